@@ -5,12 +5,14 @@ package agent
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/netdata/go.d.plugin/agent/httpapi"
 	"github.com/netdata/go.d.plugin/agent/job/build"
 	"github.com/netdata/go.d.plugin/agent/job/confgroup"
 	"github.com/netdata/go.d.plugin/agent/job/discovery"
@@ -19,6 +21,8 @@ import (
 	"github.com/netdata/go.d.plugin/agent/job/state"
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/agent/netdataapi"
+	"github.com/netdata/go.d.plugin/agent/promexporter"
+	"github.com/netdata/go.d.plugin/agent/selfmon"
 	"github.com/netdata/go.d.plugin/logger"
 	"github.com/netdata/go.d.plugin/pkg/multipath"
 
@@ -38,6 +42,7 @@ type Config struct {
 	ModuleRegistry    module.Registry
 	RunModule         string
 	MinUpdateEvery    int
+	LogFormat         string
 }
 
 // Agent represents orchestrator.
@@ -50,6 +55,7 @@ type Agent struct {
 	LockDir           string
 	RunModule         string
 	MinUpdateEvery    int
+	LogFormat         string
 	ModuleRegistry    module.Registry
 	Out               io.Writer
 	api               *netdataapi.API
@@ -67,6 +73,7 @@ func New(cfg Config) *Agent {
 		LockDir:           cfg.LockDir,
 		RunModule:         cfg.RunModule,
 		MinUpdateEvery:    cfg.MinUpdateEvery,
+		LogFormat:         cfg.LogFormat,
 		ModuleRegistry:    module.DefaultRegistry,
 		Out:               os.Stdout,
 	}
@@ -88,27 +95,35 @@ func (a *Agent) Run() {
 func serve(p *Agent) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGHUP)
-	var wg sync.WaitGroup
-
-	for {
-		ctx, cancel := context.WithCancel(context.Background())
 
-		wg.Add(1)
-		go func() { defer wg.Done(); p.run(ctx) }()
+	reload := make(chan struct{}, 1)
+	go func() {
+		for sig := range ch {
+			p.Infof("received %s signal (%d), reloading the configuration", sig, sig)
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	go p.watchPluginConfig(context.Background(), reload)
 
-		sig := <-ch
-		p.Infof("received %s signal (%d), stopping running instance", sig, sig)
-		cancel()
-		wg.Wait()
+	for {
+		if !p.run(context.Background(), reload) {
+			<-reload
+		}
 		time.Sleep(time.Second)
 	}
 }
 
-func (a *Agent) run(ctx context.Context) {
+// run runs the plugin instance, it applies the configuration changes on reload.
+// It returns true if the instance is stopped to be restarted with the new configuration.
+func (a *Agent) run(ctx context.Context, reload <-chan struct{}) bool {
 	a.Info("instance is started")
 	defer func() { a.Info("instance is stopped") }()
 
 	cfg := a.loadPluginConfig()
+	a.applyLogFormat(cfg)
 	a.Infof("using config: %s", cfg)
 	if !cfg.Enabled {
 		a.Info("plugin is disabled in the configuration file, exiting...")
@@ -116,7 +131,7 @@ func (a *Agent) run(ctx context.Context) {
 			os.Exit(0)
 		}
 		_ = a.api.DISABLE()
-		return
+		return false
 	}
 
 	enabled := a.loadEnabledModules(cfg)
@@ -126,10 +141,10 @@ func (a *Agent) run(ctx context.Context) {
 			os.Exit(0)
 		}
 		_ = a.api.DISABLE()
-		return
+		return false
 	}
 
-	discCfg := a.buildDiscoveryConf(enabled)
+	discCfg := a.buildDiscoveryConf(cfg, enabled)
 
 	discoverer, err := discovery.NewManager(discCfg)
 	if err != nil {
@@ -137,17 +152,36 @@ func (a *Agent) run(ctx context.Context) {
 		if isTerminal {
 			os.Exit(0)
 		}
-		return
+		return false
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	runner := run.NewManager()
+	runner.SpreadTicks = cfg.SpreadCollections
 
 	builder := build.NewManager()
 	builder.Runner = runner
+	if cfg.MaxConcurrentCollections > 0 {
+		builder.Limiter = run.NewLimiter(cfg.MaxConcurrentCollections)
+	}
 	builder.PluginName = a.Name
 	builder.Out = a.Out
 	builder.Modules = enabled
 
+	var exporter *promexporter.Exporter
+	if cfg.Exporter.Address != "" {
+		if exporter, err = promexporter.New(cfg.Exporter); err != nil {
+			a.Warningf("couldn't create prometheus exporter: %v", err)
+		} else {
+			builder.Sink = exporter
+			if cfg.Exporter.Standalone {
+				builder.Out = ioutil.Discard
+			}
+		}
+	}
+
 	if a.LockDir != "" {
 		builder.Registry = registry.NewFileLockRegistry(a.LockDir)
 	}
@@ -172,17 +206,78 @@ func (a *Agent) run(ctx context.Context) {
 	wg.Add(1)
 	go func() { defer wg.Done(); builder.Run(ctx, in) }()
 
-	wg.Add(1)
-	go func() { defer wg.Done(); discoverer.Run(ctx, in) }()
+	disc := &discoveryRunner{}
+	disc.start(ctx, discCfg, discoverer, in)
 
 	if saver != nil {
 		wg.Add(1)
 		go func() { defer wg.Done(); saver.Run(ctx) }()
 	}
 
+	if cfg.SelfMon {
+		a.startSelfMonJob(runner, builder, disc)
+	}
+
+	if exporter != nil {
+		wg.Add(1)
+		go func() { defer wg.Done(); exporter.Run(ctx) }()
+	}
+
+	if cfg.HTTPAPI.Address != "" {
+		if srv, err := httpapi.New(cfg.HTTPAPI, builder); err != nil {
+			a.Warningf("couldn't create http api server: %v", err)
+		} else {
+			wg.Add(1)
+			go func() { defer wg.Done(); srv.Run(ctx) }()
+		}
+	}
+
+	restart := a.handleReloads(ctx, cfg, disc, in, builder, reload)
+
+	cancel()
+	disc.stop()
 	wg.Wait()
-	<-ctx.Done()
 	runner.Cleanup()
+	return restart
+}
+
+func (a *Agent) handleReloads(ctx context.Context, cfg config, disc *discoveryRunner, in chan []*confgroup.Group,
+	builder *build.Manager, reload <-chan struct{}) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-reload:
+			var ok bool
+			if cfg, ok = a.reload(ctx, cfg, disc, in, builder); !ok {
+				return true
+			}
+		}
+	}
+}
+
+func (a *Agent) startSelfMonJob(runner *run.Manager, builder *build.Manager, discoverer selfmon.DiscoveryStater) {
+	updateEvery := a.MinUpdateEvery
+	if updateEvery <= 0 {
+		updateEvery = module.UpdateEvery
+	}
+
+	job := module.NewJob(module.JobConfig{
+		PluginName:  a.Name,
+		Name:        selfmon.ModuleName,
+		ModuleName:  selfmon.ModuleName,
+		FullName:    selfmon.FullName(a.Name),
+		Module:      selfmon.New(selfmon.Config{PluginName: a.Name, Jobs: builder, Discovery: discoverer}),
+		Out:         builder.Out,
+		Sink:        builder.Sink,
+		UpdateEvery: updateEvery,
+		Priority:    module.Priority,
+	})
+	if !job.AutoDetection() {
+		a.Warning("self monitoring job detection failed")
+		return
+	}
+	runner.Start(job)
 }
 
 func (a *Agent) signalHandling() {
@@ -5,12 +5,15 @@ package agent
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TODO: tech debt
@@ -41,7 +44,7 @@ func TestAgent_Run(t *testing.T) {
 	var wg sync.WaitGroup
 
 	wg.Add(1)
-	go func() { defer wg.Done(); a.run(ctx) }()
+	go func() { defer wg.Done(); a.run(ctx, nil) }()
 
 	time.Sleep(time.Second * 2)
 	cancel()
@@ -60,6 +63,72 @@ func TestAgent_Run(t *testing.T) {
 	assert.True(t, buf.String() != "")
 }
 
+func TestAgent_Run_Reload(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "test.conf")
+	writeConf := func(conf string) { require.NoError(t, os.WriteFile(confPath, []byte(conf), 0644)) }
+	writeConf("modules:\n  module1: yes\n  module2: yes\n  module3: no\n")
+
+	a := New(Config{Name: "test", ConfDir: []string{dir}})
+	var buf bytes.Buffer
+	a.Out = &buf
+
+	var mux sync.Mutex
+	stats := make(map[string]int)
+	a.ModuleRegistry = prepareRegistry(&mux, stats, "module1", "module2", "module3")
+	getStat := func(key string) int { mux.Lock(); defer mux.Unlock(); return stats[key] }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reload := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() { defer wg.Done(); assert.False(t, a.run(ctx, reload)) }()
+
+	time.Sleep(time.Second * 3)
+	assert.Equal(t, 1, getStat("module1_init"))
+	assert.Equal(t, 1, getStat("module2_init"))
+	assert.Equal(t, 0, getStat("module3_init"))
+
+	writeConf("modules:\n  module1: yes\n  module2: no\n  module3: yes\n")
+	reload <- struct{}{}
+	time.Sleep(time.Second * 3)
+
+	assert.Equal(t, 1, getStat("module1_init"))
+	assert.Equal(t, 0, getStat("module1_cleanup"))
+	assert.Equal(t, 1, getStat("module2_cleanup"))
+	assert.Equal(t, 1, getStat("module3_init"))
+
+	cancel()
+	wg.Wait()
+}
+
+func TestAgent_Run_ReloadRestart(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "test.conf")
+	require.NoError(t, os.WriteFile(confPath, []byte("max_procs: 1\n"), 0644))
+
+	a := New(Config{Name: "test", ConfDir: []string{dir}})
+	a.Out = &bytes.Buffer{}
+	var mux sync.Mutex
+	a.ModuleRegistry = prepareRegistry(&mux, make(map[string]int), "module1")
+
+	reload := make(chan struct{})
+	done := make(chan bool)
+	go func() { done <- a.run(context.Background(), reload) }()
+
+	time.Sleep(time.Second)
+	require.NoError(t, os.WriteFile(confPath, []byte("max_procs: 2\n"), 0644))
+	reload <- struct{}{}
+
+	select {
+	case restart := <-done:
+		assert.True(t, restart)
+	case <-time.After(time.Second * 5):
+		t.Error("instance is not stopped for restart")
+	}
+}
+
 func prepareRegistry(mux *sync.Mutex, stats map[string]int, names ...string) module.Registry {
 	reg := module.Registry{}
 	for _, name := range names {
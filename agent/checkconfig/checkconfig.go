@@ -0,0 +1,345 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package checkconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/logger"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Stdin is the Config.Path value that makes Checker read the config from the Config.In.
+const Stdin = "-"
+
+// Config is a Checker configuration.
+type Config struct {
+	// Path is the module config file path, "-" means read from the In.
+	Path string
+	// ModuleName is the module to check, required if the name can't be derived from the Path.
+	ModuleName string
+	// JobName is the job to check, all the jobs from the config are checked if not set.
+	JobName string
+	Modules module.Registry
+	In      io.Reader
+	Out     io.Writer
+}
+
+// Checker runs jobs from a module config file once (init, check, collect)
+// and reports charts definitions and collected metrics.
+type Checker struct {
+	path       string
+	moduleName string
+	jobName    string
+	modules    module.Registry
+	in         io.Reader
+	out        io.Writer
+}
+
+// New creates a new Checker.
+func New(cfg Config) *Checker {
+	c := &Checker{
+		path:       cfg.Path,
+		moduleName: cfg.ModuleName,
+		jobName:    cfg.JobName,
+		modules:    cfg.Modules,
+		in:         cfg.In,
+		out:        cfg.Out,
+	}
+	if c.modules == nil {
+		c.modules = module.DefaultRegistry
+	}
+	if c.in == nil {
+		c.in = os.Stdin
+	}
+	if c.out == nil {
+		c.out = os.Stdout
+	}
+	return c
+}
+
+// Run checks the jobs, it returns an error if the config can't be loaded or any of the jobs failed.
+func (c *Checker) Run() error {
+	cfgs, err := c.loadConfigs()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, cfg := range cfgs {
+		res := c.checkJob(cfg)
+		res.print(c.out)
+		if !res.ok() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d job(s) failed", failed, len(cfgs))
+	}
+	return nil
+}
+
+func (c *Checker) loadConfigs() ([]confgroup.Config, error) {
+	bs, err := c.readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	name := c.moduleName
+	if name == "" && c.path != Stdin {
+		name = fileName(c.path)
+	}
+	if name == "" {
+		return nil, errors.New("module name is not set")
+	}
+
+	creator, ok := c.modules[name]
+	if !ok {
+		return nil, fmt.Errorf("can not find %s module", name)
+	}
+
+	var modCfg struct {
+		confgroup.Default `yaml:",inline"`
+		Jobs              []confgroup.Config `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(bs, &modCfg); err != nil {
+		return nil, fmt.Errorf("parse config: %v", err)
+	}
+
+	def := confgroup.Default{
+		UpdateEvery:        firstPositive(modCfg.UpdateEvery, creator.UpdateEvery),
+		AutoDetectionRetry: firstPositive(modCfg.AutoDetectionRetry, creator.AutoDetectionRetry),
+		Priority:           firstPositive(modCfg.Priority, creator.Priority),
+	}
+
+	var cfgs []confgroup.Config
+	for _, cfg := range modCfg.Jobs {
+		cfg.SetModule(name)
+		cfg.Apply(def)
+		if c.jobName == "" || c.jobName == cfg.Name() {
+			if err := cfg.ResolveSecrets(); err != nil {
+				return nil, fmt.Errorf("job '%s': %v", cfg.Name(), err)
+			}
+			cfgs = append(cfgs, cfg)
+		}
+	}
+
+	if len(cfgs) == 0 {
+		if c.jobName != "" {
+			return nil, fmt.Errorf("job '%s' not found in the config", c.jobName)
+		}
+		return nil, errors.New("no jobs found in the config")
+	}
+	return cfgs, nil
+}
+
+func (c *Checker) readConfig() ([]byte, error) {
+	if c.path == Stdin {
+		return ioutil.ReadAll(c.in)
+	}
+	return ioutil.ReadFile(c.path)
+}
+
+func (c *Checker) checkJob(cfg confgroup.Config) (res *result) {
+	res = &result{module: cfg.Module(), name: cfg.Name(), fullName: cfg.FullName()}
+
+	creator := c.modules[cfg.Module()]
+	unknown, errs := cfg.Validate(creator.Schema())
+	res.unknown = unknown
+	if len(errs) > 0 {
+		res.err = fmt.Errorf("config: %s", strings.Join(errs, ", "))
+		return res
+	}
+
+	mod := creator.Create()
+	if err := unmarshal(cfg.ExpandSecrets(), mod); err != nil {
+		res.err = fmt.Errorf("config: %v", err)
+		return res
+	}
+	mod.GetBase().Logger = logger.New(cfg.Module(), cfg.Name())
+
+	defer func() {
+		if r := recover(); r != nil {
+			res.err = fmt.Errorf("PANIC: %v", r)
+			if logger.IsDebug() {
+				res.stack = debug.Stack()
+			}
+		}
+		mod.Cleanup()
+	}()
+
+	if !mod.Init() {
+		res.err = errors.New("init failed")
+		return res
+	}
+	if !mod.Check() {
+		res.err = errors.New("check failed")
+		return res
+	}
+	if res.charts = mod.Charts(); res.charts == nil {
+		res.err = errors.New("nil charts")
+		return res
+	}
+	if err := res.charts.Check(); err != nil {
+		res.err = fmt.Errorf("charts check: %v", err)
+		return res
+	}
+	if res.metrics = collect(mod); len(res.metrics) == 0 {
+		res.err = errors.New("no metrics collected")
+	}
+	return res
+}
+
+type result struct {
+	module   string
+	name     string
+	fullName string
+	unknown  []string
+	charts   *module.Charts
+	metrics  map[string]float64
+	err      error
+	stack    []byte
+}
+
+func (r result) ok() bool { return r.err == nil }
+
+func (r result) print(w io.Writer) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "job '%s' (module '%s', name '%s')\n", r.fullName, r.module, r.name)
+
+	for _, key := range r.unknown {
+		fmt.Fprintf(&b, "  warning: unknown config key '%s'\n", key)
+	}
+
+	if r.charts != nil {
+		used := make(map[string]bool)
+		fmt.Fprintf(&b, "  charts (%d):\n", len(*r.charts))
+		for _, chart := range *r.charts {
+			fmt.Fprintf(&b, "    chart '%s': title '%s', units '%s', family '%s', context '%s', type '%s'\n",
+				chart.ID, chart.Title, chart.Units, chart.Fam, chart.Ctx, chartType(chart))
+			for _, dim := range chart.Dims {
+				used[dim.ID] = true
+				fmt.Fprintf(&b, "      dim '%s': name '%s', algo '%s', mul %d, div %d => %s\n",
+					dim.ID, firstNotEmpty(dim.Name, dim.ID), dimAlgo(dim), dim.Mul, dim.Div, r.value(dim.ID))
+			}
+			for _, v := range chart.Vars {
+				used[v.ID] = true
+				fmt.Fprintf(&b, "      var '%s' => %s\n", v.ID, r.value(v.ID))
+			}
+		}
+
+		var unused []string
+		for k := range r.metrics {
+			if !used[k] {
+				unused = append(unused, k)
+			}
+		}
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			fmt.Fprintf(&b, "  collected, but not used by any chart (%d):\n", len(unused))
+			for _, k := range unused {
+				fmt.Fprintf(&b, "    '%s' => %s\n", k, formatValue(r.metrics[k]))
+			}
+		}
+	}
+
+	if r.metrics != nil {
+		fmt.Fprintf(&b, "  collected metrics: %d\n", len(r.metrics))
+	}
+
+	if r.ok() {
+		b.WriteString("  result: OK\n")
+	} else {
+		fmt.Fprintf(&b, "  result: FAILED (%v)\n", r.err)
+		if len(r.stack) > 0 {
+			fmt.Fprintf(&b, "%s\n", r.stack)
+		}
+	}
+
+	_, _ = io.WriteString(w, b.String())
+}
+
+func (r result) value(id string) string {
+	if v, ok := r.metrics[id]; ok {
+		return formatValue(v)
+	}
+	return "<not collected>"
+}
+
+// collect returns the module collected values, the FloatCollector values are reported as is (not scaled).
+func collect(mod module.Module) map[string]float64 {
+	if fc, ok := mod.(module.FloatCollector); ok {
+		return fc.CollectFloat()
+	}
+	mx := mod.Collect()
+	if mx == nil {
+		return nil
+	}
+	values := make(map[string]float64, len(mx))
+	for k, v := range mx {
+		values[k] = float64(v)
+	}
+	return values
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func chartType(chart *module.Chart) string {
+	if v := chart.Type.String(); v != "" {
+		return v
+	}
+	return module.Line.String()
+}
+
+func dimAlgo(dim *module.Dim) string {
+	if v := dim.Algo.String(); v != "" {
+		return v
+	}
+	return module.Absolute.String()
+}
+
+func unmarshal(conf interface{}, module interface{}) error {
+	bs, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bs, module)
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstPositive(value int, others ...int) int {
+	if value > 0 || len(others) == 0 {
+		return value
+	}
+	return firstPositive(others[0], others[1:]...)
+}
+
+func fileName(path string) string {
+	_, file := filepath.Split(path)
+	ext := filepath.Ext(path)
+	return file[:len(file)-len(ext)]
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package checkconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareRegistry() module.Registry {
+	charts := func() *module.Charts {
+		return &module.Charts{
+			{
+				ID:    "chart1",
+				Title: "Title",
+				Units: "units",
+				Dims: module.Dims{
+					{ID: "dim1"},
+					{ID: "dim2", Algo: module.Incremental},
+				},
+			},
+		}
+	}
+	reg := module.Registry{}
+	reg.Register("success", module.Creator{
+		Create: func() module.Module {
+			return &module.MockModule{
+				ChartsFunc:  charts,
+				CollectFunc: func() map[string]int64 { return map[string]int64{"dim1": 1, "unused": 2} },
+			}
+		},
+	})
+	reg.Register("fail", module.Creator{
+		Create: func() module.Module {
+			return &module.MockModule{CheckFunc: func() bool { return false }}
+		},
+	})
+	reg.Register("panic", module.Creator{
+		Create: func() module.Module {
+			return &module.MockModule{
+				ChartsFunc:  charts,
+				CollectFunc: func() map[string]int64 { panic("oops") },
+			}
+		},
+	})
+	return reg
+}
+
+func TestChecker_Run(t *testing.T) {
+	tests := map[string]struct {
+		cfg          Config
+		input        string
+		wantErr      bool
+		wantContains []string
+	}{
+		"success": {
+			cfg:   Config{Path: Stdin, ModuleName: "success"},
+			input: "jobs:\n  - name: job1\n",
+			wantContains: []string{
+				"job 'success_job1'",
+				"dim 'dim1': name 'dim1', algo 'absolute', mul 0, div 0 => 1",
+				"dim 'dim2': name 'dim2', algo 'incremental', mul 0, div 0 => <not collected>",
+				"'unused' => 2",
+				"result: OK",
+			},
+		},
+		"only requested job": {
+			cfg:          Config{Path: Stdin, ModuleName: "success", JobName: "job2"},
+			input:        "jobs:\n  - name: job1\n  - name: job2\n",
+			wantContains: []string{"job 'success_job2'"},
+		},
+		"requested job not found": {
+			cfg:     Config{Path: Stdin, ModuleName: "success", JobName: "job3"},
+			input:   "jobs:\n  - name: job1\n",
+			wantErr: true,
+		},
+		"check fails": {
+			cfg:          Config{Path: Stdin, ModuleName: "fail"},
+			input:        "jobs:\n  - name: job1\n",
+			wantErr:      true,
+			wantContains: []string{"result: FAILED (check failed)"},
+		},
+		"collect panics": {
+			cfg:          Config{Path: Stdin, ModuleName: "panic"},
+			input:        "jobs:\n  - name: job1\n",
+			wantErr:      true,
+			wantContains: []string{"result: FAILED (PANIC: oops)"},
+		},
+		"no module name": {
+			cfg:     Config{Path: Stdin},
+			input:   "jobs:\n  - name: job1\n",
+			wantErr: true,
+		},
+		"unknown module": {
+			cfg:     Config{Path: Stdin, ModuleName: "unknown"},
+			input:   "jobs:\n  - name: job1\n",
+			wantErr: true,
+		},
+		"no jobs": {
+			cfg:     Config{Path: Stdin, ModuleName: "success"},
+			input:   "update_every: 5\n",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			test.cfg.Modules = prepareRegistry()
+			test.cfg.In = strings.NewReader(test.input)
+			test.cfg.Out = &out
+
+			err := New(test.cfg).Run()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, s := range test.wantContains {
+				assert.Contains(t, out.String(), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/build"
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const (
+	unixSocketPrefix = "unix://"
+	jobsPath         = "/api/v1/jobs"
+)
+
+// Config is the HTTP API configuration.
+// Address is either 'host:port' or 'unix:///path/to/socket'. Empty address disables the API.
+type Config struct {
+	Address string `yaml:"address"`
+}
+
+// JobsManager is implemented by build.Manager.
+type JobsManager interface {
+	JobStatuses() []build.JobStatus
+	RestartJob(ctx context.Context, fullName string) error
+	StopJob(ctx context.Context, fullName string) error
+	AutoDetectJob(ctx context.Context, fullName string) error
+}
+
+type Server struct {
+	*logger.Logger
+	address string
+	jobs    JobsManager
+	handler http.Handler
+}
+
+func New(cfg Config, jobs JobsManager) (*Server, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("address not set")
+	}
+	s := &Server{
+		Logger:  logger.New("httpapi", "server"),
+		address: cfg.Address,
+		jobs:    jobs,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(jobsPath, s.handleJobs)
+	mux.HandleFunc(jobsPath+"/", s.handleJob)
+	s.handler = mux
+
+	return s, nil
+}
+
+func (s *Server) Handler() http.Handler { return s.handler }
+
+// Run serves the API until the context is canceled.
+func (s *Server) Run(ctx context.Context) {
+	s.Info("instance is started")
+	defer func() { s.Info("instance is stopped") }()
+
+	ln, err := s.listen()
+	if err != nil {
+		s.Errorf("listen on '%s': %v", s.address, err)
+		return
+	}
+
+	srv := &http.Server{Handler: s.handler}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			s.Error(err)
+		}
+	}()
+	s.Infof("serving on '%s'", s.address)
+
+	<-ctx.Done()
+
+	sctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_ = srv.Shutdown(sctx)
+	<-done
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	if !strings.HasPrefix(s.address, unixSocketPrefix) {
+		return net.Listen("tcp", s.address)
+	}
+	path := strings.TrimPrefix(s.address, unixSocketPrefix)
+	// the socket file left after unclean shutdown
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// handleJobs serves 'GET /api/v1/jobs'.
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%s' not allowed", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.jobs.JobStatuses())
+}
+
+// handleJob serves 'GET /api/v1/jobs/{full_name}' and 'POST /api/v1/jobs/{full_name}/{restart|stop|autodetection}'.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, jobsPath+"/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	fullName := parts[0]
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%s' not allowed", r.Method))
+			return
+		}
+		var statuses []build.JobStatus
+		for _, st := range s.jobs.JobStatuses() {
+			if st.FullName == fullName {
+				statuses = append(statuses, st)
+			}
+		}
+		if len(statuses) == 0 {
+			writeError(w, http.StatusNotFound, build.ErrJobNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, statuses)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method '%s' not allowed", r.Method))
+		return
+	}
+
+	var err error
+	switch action := parts[1]; action {
+	case "restart":
+		err = s.jobs.RestartJob(r.Context(), fullName)
+	case "stop":
+		err = s.jobs.StopJob(r.Context(), fullName)
+	case "autodetection":
+		err = s.jobs.AutoDetectJob(r.Context(), fullName)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action '%s'", action))
+		return
+	}
+
+	switch {
+	case err == nil:
+		s.Infof("'%s' job: '%s' request done", fullName, parts[1])
+		writeJSON(w, http.StatusOK, response{Status: "ok"})
+	case errors.Is(err, build.ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, build.ErrJobNotRunning), errors.Is(err, build.ErrJobRunning):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, response{Status: "error", Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
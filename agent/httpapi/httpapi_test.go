@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/build"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		config  Config
+		wantErr bool
+	}{
+		"tcp address":   {config: Config{Address: "127.0.0.1:8080"}},
+		"unix socket":   {config: Config{Address: "unix:///tmp/go.d.sock"}},
+		"empty address": {config: Config{}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, err := New(test.config, &mockJobsManager{})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, srv)
+			}
+		})
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	tests := map[string]struct {
+		method     string
+		path       string
+		ctrlErr    error
+		wantCode   int
+		wantBody   string
+		wantAction string
+	}{
+		"list jobs": {
+			method:   http.MethodGet,
+			path:     "/api/v1/jobs",
+			wantCode: http.StatusOK,
+			wantBody: `[{"module":"nginx","name":"local","full_name":"nginx_local","source":"/etc/go.d/nginx.conf","provider":"file reader","state":"success","update_every":1,"autodetection_retry":0,"last_collect":1600000000,"last_collect_duration_ms":10,"penalty":0,"retries":0},{"module":"redis","name":"local","full_name":"redis_local","source":"/etc/go.d/redis.conf","provider":"file reader","state":"retry","update_every":1,"autodetection_retry":10,"last_collect_duration_ms":0,"penalty":0,"retries":0,"last_error":"check failed","last_error_time":1600000000}]`,
+		},
+		"list jobs wrong method": {
+			method:   http.MethodPost,
+			path:     "/api/v1/jobs",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		"get job": {
+			method:   http.MethodGet,
+			path:     "/api/v1/jobs/redis_local",
+			wantCode: http.StatusOK,
+			wantBody: `[{"module":"redis","name":"local","full_name":"redis_local","source":"/etc/go.d/redis.conf","provider":"file reader","state":"retry","update_every":1,"autodetection_retry":10,"last_collect_duration_ms":0,"penalty":0,"retries":0,"last_error":"check failed","last_error_time":1600000000}]`,
+		},
+		"get unknown job": {
+			method:   http.MethodGet,
+			path:     "/api/v1/jobs/mysql_local",
+			wantCode: http.StatusNotFound,
+		},
+		"restart job": {
+			method:     http.MethodPost,
+			path:       "/api/v1/jobs/nginx_local/restart",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+			wantAction: "restart nginx_local",
+		},
+		"stop job": {
+			method:     http.MethodPost,
+			path:       "/api/v1/jobs/nginx_local/stop",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+			wantAction: "stop nginx_local",
+		},
+		"trigger autodetection": {
+			method:     http.MethodPost,
+			path:       "/api/v1/jobs/redis_local/autodetection",
+			wantCode:   http.StatusOK,
+			wantBody:   `{"status":"ok"}`,
+			wantAction: "autodetection redis_local",
+		},
+		"control unknown job": {
+			method:     http.MethodPost,
+			path:       "/api/v1/jobs/mysql_local/stop",
+			ctrlErr:    build.ErrJobNotFound,
+			wantCode:   http.StatusNotFound,
+			wantBody:   `{"status":"error","error":"job not found"}`,
+			wantAction: "stop mysql_local",
+		},
+		"control conflict": {
+			method:     http.MethodPost,
+			path:       "/api/v1/jobs/nginx_local/autodetection",
+			ctrlErr:    build.ErrJobRunning,
+			wantCode:   http.StatusConflict,
+			wantBody:   `{"status":"error","error":"job is already running"}`,
+			wantAction: "autodetection nginx_local",
+		},
+		"control wrong method": {
+			method:   http.MethodGet,
+			path:     "/api/v1/jobs/nginx_local/stop",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		"unknown action": {
+			method:   http.MethodPost,
+			path:     "/api/v1/jobs/nginx_local/reload",
+			wantCode: http.StatusNotFound,
+		},
+		"unknown path": {
+			method:   http.MethodPost,
+			path:     "/api/v1/jobs/nginx_local/stop/now",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mgr := &mockJobsManager{statuses: prepareJobStatuses(), err: test.ctrlErr}
+			srv, err := New(Config{Address: "127.0.0.1:0"}, mgr)
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(srv.Handler())
+			defer ts.Close()
+
+			req, err := http.NewRequest(test.method, ts.URL+test.path, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.wantCode, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.Equal(t, test.wantAction, mgr.action)
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.True(t, json.Valid(body))
+			if test.wantBody != "" {
+				assert.JSONEq(t, test.wantBody, string(body))
+			}
+		})
+	}
+}
+
+func TestServer_Run_UnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpapi")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "go.d.sock")
+	srv, err := New(Config{Address: unixSocketPrefix + path}, &mockJobsManager{statuses: prepareJobStatuses()})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { defer close(done); srv.Run(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+
+	var resp *http.Response
+	for i := 0; i < 20; i++ {
+		if resp, err = client.Get("http://unix" + jobsPath); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("server didn't stop after context cancellation")
+	}
+}
+
+type mockJobsManager struct {
+	statuses []build.JobStatus
+	err      error
+	action   string
+}
+
+func (m *mockJobsManager) JobStatuses() []build.JobStatus { return m.statuses }
+
+func (m *mockJobsManager) RestartJob(_ context.Context, fullName string) error {
+	m.action = "restart " + fullName
+	return m.err
+}
+
+func (m *mockJobsManager) StopJob(_ context.Context, fullName string) error {
+	m.action = "stop " + fullName
+	return m.err
+}
+
+func (m *mockJobsManager) AutoDetectJob(_ context.Context, fullName string) error {
+	m.action = "autodetection " + fullName
+	return m.err
+}
+
+func prepareJobStatuses() []build.JobStatus {
+	return []build.JobStatus{
+		{
+			Module:                "nginx",
+			Name:                  "local",
+			FullName:              "nginx_local",
+			Source:                "/etc/go.d/nginx.conf",
+			Provider:              "file reader",
+			State:                 "success",
+			UpdateEvery:           1,
+			LastCollect:           1600000000,
+			LastCollectDurationMs: 10,
+		},
+		{
+			Module:             "redis",
+			Name:               "local",
+			FullName:           "redis_local",
+			Source:             "/etc/go.d/redis.conf",
+			Provider:           "file reader",
+			State:              "retry",
+			UpdateEvery:        1,
+			AutoDetectionRetry: 10,
+			LastError:          "check failed",
+			LastErrorTime:      1600000000,
+		},
+	}
+}
@@ -63,13 +63,21 @@ const (
 	duplicateGlobal   state = "duplicate_global"   // a job with the same FullName is registered by another plugin
 	registrationError state = "registration_error" // an error during registration (only 'too many open files')
 	buildError        state = "build_error"        // an error during building
+	stopped           state = "stopped"            // stopped on request
 )
 
+// hangRetryEvery is the autodetection retry interval (in seconds) for the jobs which collect has hung
+// and which autodetection_retry is not set.
+const hangRetryEvery = 30
+
 type (
 	Manager struct {
 		PluginName string
 		Out        io.Writer
+		Sink       module.MetricsSink
 		Modules    module.Registry
+		// Limiter limits the number of the jobs collecting data at once, nil means no limit.
+		Limiter module.CollectLimiter
 		*logger.Logger
 
 		Runner    Runner
@@ -80,10 +88,17 @@ type (
 		grpCache   *groupCache
 		startCache *startedCache
 		retryCache *retryCache
+		statuses   *statusCache
 
 		addCh    chan []confgroup.Config
 		removeCh chan []confgroup.Config
 		retryCh  chan confgroup.Config
+		ctrlCh   chan ctrlRequest
+		modsCh   chan module.Registry
+
+		hangMux sync.Mutex
+		hangs   []confgroup.Config
+		hangCh  chan struct{}
 	}
 )
 
@@ -97,9 +112,13 @@ func NewManager() *Manager {
 		grpCache:   newGroupCache(),
 		startCache: newStartedCache(),
 		retryCache: newRetryCache(),
+		statuses:   newStatusCache(),
 		addCh:      make(chan []confgroup.Config),
 		removeCh:   make(chan []confgroup.Config),
 		retryCh:    make(chan confgroup.Config),
+		ctrlCh:     make(chan ctrlRequest),
+		modsCh:     make(chan module.Registry),
+		hangCh:     make(chan struct{}, 1),
 	}
 	return mgr
 }
@@ -179,10 +198,27 @@ func (m *Manager) runConfigProcessing(ctx context.Context) {
 			m.handleRemove(ctx, cfgs)
 		case cfg := <-m.retryCh:
 			m.handleAddCfg(ctx, cfg)
+		case req := <-m.ctrlCh:
+			req.resp <- m.handleControl(ctx, req)
+		case mods := <-m.modsCh:
+			m.Modules = mods
+		case <-m.hangCh:
+			for _, cfg := range m.takeHangs() {
+				m.handleHangCfg(ctx, cfg)
+			}
 		}
 	}
 }
 
+// SetModules replaces the modules the jobs are built from, the configs received after the call use the new modules.
+// The running jobs are not affected.
+func (m *Manager) SetModules(ctx context.Context, modules module.Registry) {
+	select {
+	case <-ctx.Done():
+	case m.modsCh <- modules:
+	}
+}
+
 func (m *Manager) handleAdd(ctx context.Context, cfgs []confgroup.Config) {
 	for _, cfg := range cfgs {
 		select {
@@ -208,7 +244,7 @@ func (m *Manager) handleRemove(ctx context.Context, cfgs []confgroup.Config) {
 func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 	if m.startCache.has(cfg) {
 		m.Infof("%s[%s] job is being served by another job, skipping it", cfg.Module(), cfg.Name())
-		m.CurState.Save(cfg, duplicateLocal)
+		m.saveState(cfg, duplicateLocal, nil)
 		return
 	}
 
@@ -218,10 +254,18 @@ func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 		m.retryCache.remove(cfg)
 	}
 
+	issues, err := m.validateConfig(cfg)
+	m.statuses.putConfigIssues(cfg, issues)
+	if err != nil {
+		m.Warningf("couldn't build %s[%s]: %v", cfg.Module(), cfg.Name(), err)
+		m.saveState(cfg, buildError, nil)
+		return
+	}
+
 	job, err := m.buildJob(cfg)
 	if err != nil {
 		m.Warningf("couldn't build %s[%s]: %v", cfg.Module(), cfg.Name(), err)
-		m.CurState.Save(cfg, buildError)
+		m.saveState(cfg, buildError, nil)
 		return
 	}
 	cleanupJob := true
@@ -250,31 +294,32 @@ func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 	switch detection(job) {
 	case success:
 		if ok, err := m.Registry.Register(cfg.FullName()); ok || err != nil && !isTooManyOpenFiles(err) {
-			m.CurState.Save(cfg, success)
+			m.saveState(cfg, success, job)
 			m.Runner.Start(job)
 			m.startCache.put(cfg)
 			cleanupJob = false
 		} else if isTooManyOpenFiles(err) {
 			m.Error(err)
-			m.CurState.Save(cfg, registrationError)
+			m.saveState(cfg, registrationError, job)
 		} else {
 			m.Infof("%s[%s] job is being served by another plugin, skipping it", cfg.Module(), cfg.Name())
-			m.CurState.Save(cfg, duplicateGlobal)
+			m.saveState(cfg, duplicateGlobal, job)
 		}
 	case retry:
-		m.Infof("%s[%s] job detection failed, will retry in %d seconds",
-			cfg.Module(), cfg.Name(), job.AutoDetectionEvery())
-		m.CurState.Save(cfg, retry)
-		ctx, cancel := context.WithCancel(ctx)
-		m.retryCache.put(cfg, retryTask{
-			cancel:  cancel,
+		attempt := 0
+		if isRetry {
+			attempt = task.attempt + 1
+		}
+		delay := retryDelay(cfg, job.AutoDetectionEvery(), attempt)
+		m.Infof("%s[%s] job detection failed, will retry in %d seconds", cfg.Module(), cfg.Name(), delay)
+		m.saveState(cfg, retry, job)
+		m.scheduleRetry(ctx, cfg, retryTask{
 			timeout: job.AutoDetectionEvery(),
 			retries: job.AutoDetectTries,
-		})
-		timeout := time.Second * time.Duration(job.AutoDetectionEvery())
-		go runRetryTask(ctx, m.retryCh, cfg, timeout)
+			attempt: attempt,
+		}, delay)
 	case failed:
-		m.CurState.Save(cfg, failed)
+		m.saveState(cfg, failed, job)
 	default:
 		m.Warningf("%s[%s] job detection: unknown state", cfg.Module(), cfg.Name())
 	}
@@ -282,12 +327,9 @@ func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 
 func (m *Manager) handleRemoveCfg(cfg confgroup.Config) {
 	defer m.CurState.Remove(cfg)
+	defer m.statuses.remove(cfg)
 
-	if m.startCache.has(cfg) {
-		m.Runner.Stop(cfg.FullName())
-		_ = m.Registry.Unregister(cfg.FullName())
-		m.startCache.remove(cfg)
-	}
+	m.stopRunning(cfg)
 
 	if task, ok := m.retryCache.lookup(cfg); ok {
 		task.cancel()
@@ -295,6 +337,80 @@ func (m *Manager) handleRemoveCfg(cfg confgroup.Config) {
 	}
 }
 
+// handleHangCfg stops the job which collect has hung and schedules its autodetection retry.
+func (m *Manager) handleHangCfg(ctx context.Context, cfg confgroup.Config) {
+	if !m.startCache.has(cfg) {
+		return
+	}
+	timeout := cfg.AutoDetectionRetry()
+	if timeout <= 0 {
+		timeout = hangRetryEvery
+	}
+	m.Warningf("%s[%s] job collect has hung, restarting it in %d seconds", cfg.Module(), cfg.Name(), timeout)
+
+	m.stopRunning(cfg)
+	m.saveState(cfg, retry, nil)
+	m.scheduleRetry(ctx, cfg, retryTask{timeout: timeout, retries: -1}, timeout) // infinite retries
+}
+
+// scheduleRetry schedules the job autodetection retry in delay seconds. The task attempt is the number
+// of the consecutive failed retries, the retry delay is backed off with it.
+func (m *Manager) scheduleRetry(ctx context.Context, cfg confgroup.Config, task retryTask, delay int) {
+	ctx, task.cancel = context.WithCancel(ctx)
+	task.delay = delay
+	m.retryCache.put(cfg, task)
+	go runRetryTask(ctx, m.retryCh, cfg, time.Second*time.Duration(delay))
+}
+
+// notifyHang is called from the job goroutine, it must not block.
+func (m *Manager) notifyHang(cfg confgroup.Config) {
+	m.hangMux.Lock()
+	m.hangs = append(m.hangs, cfg)
+	m.hangMux.Unlock()
+
+	select {
+	case m.hangCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) takeHangs() []confgroup.Config {
+	m.hangMux.Lock()
+	defer m.hangMux.Unlock()
+
+	cfgs := m.hangs
+	m.hangs = nil
+	return cfgs
+}
+
+func (m *Manager) saveState(cfg confgroup.Config, st state, job *module.Job) {
+	m.CurState.Save(cfg, st)
+	m.statuses.put(cfg, st, job)
+}
+
+// validateConfig checks the job config against the module schema. It returns the config issues,
+// the unknown keys are reported, but they don't fail the job build.
+func (m *Manager) validateConfig(cfg confgroup.Config) ([]string, error) {
+	creator, ok := m.Modules[cfg.Module()]
+	if !ok {
+		return nil, fmt.Errorf("can not find %s module", cfg.Module())
+	}
+
+	unknown, errs := cfg.Validate(creator.Schema())
+
+	var issues []string
+	for _, key := range unknown {
+		m.Warningf("%s[%s] config: unknown key '%s'", cfg.Module(), cfg.Name(), key)
+		issues = append(issues, fmt.Sprintf("unknown key '%s'", key))
+	}
+	issues = append(issues, errs...)
+
+	if len(errs) > 0 {
+		return issues, fmt.Errorf("config: %s", strings.Join(errs, ", "))
+	}
+	return issues, nil
+}
+
 func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
 	creator, ok := m.Modules[cfg.Module()]
 	if !ok {
@@ -303,7 +419,12 @@ func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
 
 	m.Debugf("building %s[%s] job, config: %v", cfg.Module(), cfg.Name(), cfg)
 	mod := creator.Create()
-	if err := unmarshal(cfg, mod); err != nil {
+	if err := unmarshal(cfg.ExpandSecrets(), mod); err != nil {
+		return nil, err
+	}
+
+	vnode, err := jobVnode(cfg)
+	if err != nil {
 		return nil, err
 	}
 
@@ -315,12 +436,48 @@ func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
 		UpdateEvery:     cfg.UpdateEvery(),
 		AutoDetectEvery: cfg.AutoDetectionRetry(),
 		Priority:        cfg.Priority(),
+		Labels:          cfg.Labels(),
+		Vnode:           vnode,
 		Module:          mod,
 		Out:             m.Out,
+		Sink:            m.Sink,
+		CollectTimeout:  cfg.CollectTimeout(),
+		OnCollectHang:   func() { m.notifyHang(cfg) },
+		Limiter:         m.Limiter,
+		Backoff:         jobBackoff(cfg),
 	})
 	return job, nil
 }
 
+// jobBackoff returns the job backoff set by the 'backoff_max' and 'backoff_jitter' options.
+func jobBackoff(cfg confgroup.Config) module.Backoff {
+	return module.Backoff{Max: cfg.BackoffMax(), Jitter: cfg.BackoffJitter()}
+}
+
+// retryDelay returns the autodetection retry delay in seconds. The retries are backed off only if the job config
+// sets the backoff options, otherwise the retry interval is fixed.
+func retryDelay(cfg confgroup.Config, interval, attempt int) int {
+	if cfg.BackoffMax() <= 0 && !cfg.BackoffJitter() {
+		return interval
+	}
+	return jobBackoff(cfg).Delay(interval, attempt)
+}
+
+// jobVnode returns the job virtual node, nil if the job config has no 'vnode'.
+// The 'vnode' is validated against the job schema, the 'hostname' is required.
+func jobVnode(cfg confgroup.Config) (*module.VirtualNode, error) {
+	v, ok := cfg.ExpandSecrets()["vnode"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	var vnode module.VirtualNode
+	if err := unmarshal(v, &vnode); err != nil {
+		return nil, fmt.Errorf("vnode: %v", err)
+	}
+	return &vnode, nil
+}
+
 func detection(job jobpkg.Job) state {
 	if !job.AutoDetection() {
 		if job.RetryAutoDetection() {
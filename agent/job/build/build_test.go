@@ -13,6 +13,7 @@ import (
 	"github.com/netdata/go.d.plugin/agent/job/run"
 	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TODO: tech dept
@@ -80,6 +81,72 @@ func TestManager_Run(t *testing.T) {
 	assert.True(t, buf.String() != "")
 }
 
+func TestManager_handleHangCfg(t *testing.T) {
+	runner := &mockRunner{}
+	mgr := NewManager()
+	mgr.Modules = prepareMockRegistry()
+	mgr.Runner = runner
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := prepareCfg("name", "success")
+	mgr.handleAdd(ctx, []confgroup.Config{cfg})
+	require.True(t, mgr.startCache.has(cfg))
+
+	mgr.notifyHang(cfg)
+	<-mgr.hangCh
+	for _, cfg := range mgr.takeHangs() {
+		mgr.handleHangCfg(ctx, cfg)
+	}
+
+	assert.Equal(t, 1, runner.stops)
+	assert.False(t, mgr.startCache.has(cfg))
+	task, ok := mgr.retryCache.lookup(cfg)
+	require.True(t, ok)
+	assert.Equal(t, hangRetryEvery, task.timeout)
+	assert.Equal(t, -1, task.retries)
+	assert.Equal(t, retry, mgr.JobStatuses()[0].State)
+	assert.Empty(t, mgr.takeHangs())
+}
+
+func TestManager_handleAddCfg_RetryBackoff(t *testing.T) {
+	tests := map[string]struct {
+		backoffMax     int
+		expectedDelays []int
+	}{
+		"fixed interval without backoff options": {expectedDelays: []int{60, 60, 60, 60}},
+		"backoff_max set":                        {backoffMax: 200, expectedDelays: []int{60, 120, 200, 200}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mgr := NewManager()
+			mgr.Modules = prepareMockRegistry()
+			mgr.Runner = &mockRunner{}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cfg := prepareCfg("name", "retry")
+			cfg["autodetection_retry"] = 60
+			if test.backoffMax > 0 {
+				cfg["backoff_max"] = test.backoffMax
+			}
+
+			for attempt, delay := range test.expectedDelays {
+				mgr.handleAdd(ctx, []confgroup.Config{cfg})
+
+				task, ok := mgr.retryCache.lookup(cfg)
+				require.True(t, ok)
+				assert.Equal(t, attempt, task.attempt)
+				assert.Equal(t, 60, task.timeout)
+				assert.Equal(t, delay, task.delay)
+			}
+		})
+	}
+}
+
 func prepareMockRegistry() module.Registry {
 	reg := module.Registry{}
 	reg.Register("success", module.Creator{
@@ -105,5 +172,13 @@ func prepareMockRegistry() module.Registry {
 			}
 		},
 	})
+	reg.Register("retry", module.Creator{
+		Create: func() module.Module {
+			return &module.MockModule{
+				InitFunc:  func() bool { return true },
+				CheckFunc: func() bool { return false },
+			}
+		},
+	})
 	return reg
 }
@@ -24,6 +24,9 @@ type (
 		cancel  context.CancelFunc
 		timeout int
 		retries int
+		attempt int
+		// delay is the scheduled retry delay in seconds.
+		delay int
 	}
 )
 
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package build
+
+import (
+	"context"
+	"errors"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+)
+
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobNotRunning = errors.New("job is not running")
+	ErrJobRunning    = errors.New("job is already running")
+)
+
+type (
+	ctrlAction  string
+	ctrlRequest struct {
+		action   ctrlAction
+		fullName string
+		resp     chan error
+	}
+)
+
+const (
+	ctrlRestart       ctrlAction = "restart"
+	ctrlStop          ctrlAction = "stop"
+	ctrlAutoDetection ctrlAction = "autodetection"
+)
+
+// JobStatuses returns the states of all job configs known to the Manager.
+func (m *Manager) JobStatuses() []JobStatus {
+	return m.statuses.statuses()
+}
+
+// RestartJob stops the running job and builds and starts it again (autodetection included).
+func (m *Manager) RestartJob(ctx context.Context, fullName string) error {
+	return m.control(ctx, ctrlRestart, fullName)
+}
+
+// StopJob stops the running (or waiting for the next autodetection retry) job.
+// The job stays stopped until its config is changed or autodetection is triggered.
+func (m *Manager) StopJob(ctx context.Context, fullName string) error {
+	return m.control(ctx, ctrlStop, fullName)
+}
+
+// AutoDetectJob immediately runs autodetection for the job that is not running.
+func (m *Manager) AutoDetectJob(ctx context.Context, fullName string) error {
+	return m.control(ctx, ctrlAutoDetection, fullName)
+}
+
+func (m *Manager) control(ctx context.Context, action ctrlAction, fullName string) error {
+	req := ctrlRequest{action: action, fullName: fullName, resp: make(chan error, 1)}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case m.ctrlCh <- req:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-req.resp:
+		return err
+	}
+}
+
+func (m *Manager) handleControl(ctx context.Context, req ctrlRequest) error {
+	if !m.statuses.has(req.fullName) {
+		return ErrJobNotFound
+	}
+	m.Infof("received '%s' request for '%s' job", req.action, req.fullName)
+
+	switch req.action {
+	case ctrlRestart:
+		cfgs := m.statuses.lookup(req.fullName, success)
+		if len(cfgs) == 0 {
+			return ErrJobNotRunning
+		}
+		m.stopRunning(cfgs[0])
+		m.handleAddCfg(ctx, cfgs[0])
+	case ctrlStop:
+		cfgs := m.statuses.lookup(req.fullName, success, retry)
+		if len(cfgs) == 0 {
+			return ErrJobNotRunning
+		}
+		cfg := cfgs[0]
+		m.stopRunning(cfg)
+		if task, ok := m.retryCache.lookup(cfg); ok {
+			task.cancel()
+			m.retryCache.remove(cfg)
+		}
+		m.saveState(cfg, stopped, nil)
+	case ctrlAutoDetection:
+		if len(m.statuses.lookup(req.fullName, success)) > 0 {
+			return ErrJobRunning
+		}
+		cfgs := m.statuses.lookup(req.fullName, retry, failed, stopped, buildError, registrationError)
+		if len(cfgs) == 0 {
+			return ErrJobNotFound
+		}
+		m.handleAddCfg(ctx, cfgs[0])
+	}
+	return nil
+}
+
+func (m *Manager) stopRunning(cfg confgroup.Config) {
+	if !m.startCache.has(cfg) {
+		return
+	}
+	m.Runner.Stop(cfg.FullName())
+	_ = m.Registry.Unregister(cfg.FullName())
+	m.startCache.remove(cfg)
+}
@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job"
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_handleControl(t *testing.T) {
+	tests := map[string]struct {
+		cfgs          []confgroup.Config
+		action        ctrlAction
+		fullName      string
+		wantErr       error
+		wantState     state
+		wantRunning   bool
+		wantStarts    int
+		wantStops     int
+		wantRetryTask bool
+	}{
+		"restart running job": {
+			cfgs:        []confgroup.Config{prepareCfg("name", "success")},
+			action:      ctrlRestart,
+			fullName:    "success_name",
+			wantState:   success,
+			wantRunning: true,
+			wantStarts:  2,
+			wantStops:   1,
+		},
+		"restart not running job": {
+			cfgs:      []confgroup.Config{prepareCfg("name", "fail")},
+			action:    ctrlRestart,
+			fullName:  "fail_name",
+			wantErr:   ErrJobNotRunning,
+			wantState: failed,
+		},
+		"restart unknown job": {
+			cfgs:        []confgroup.Config{prepareCfg("name", "success")},
+			action:      ctrlRestart,
+			fullName:    "success_unknown",
+			wantErr:     ErrJobNotFound,
+			wantState:   success,
+			wantStarts:  1,
+			wantRunning: true,
+		},
+		"stop running job": {
+			cfgs:       []confgroup.Config{prepareCfg("name", "success")},
+			action:     ctrlStop,
+			fullName:   "success_name",
+			wantState:  stopped,
+			wantStarts: 1,
+			wantStops:  1,
+		},
+		"stop job waiting for autodetection retry": {
+			cfgs:      []confgroup.Config{prepareRetryCfg("name", "retry")},
+			action:    ctrlStop,
+			fullName:  "retry_name",
+			wantState: stopped,
+		},
+		"stop failed job": {
+			cfgs:      []confgroup.Config{prepareCfg("name", "fail")},
+			action:    ctrlStop,
+			fullName:  "fail_name",
+			wantErr:   ErrJobNotRunning,
+			wantState: failed,
+		},
+		"autodetection for running job": {
+			cfgs:        []confgroup.Config{prepareCfg("name", "success")},
+			action:      ctrlAutoDetection,
+			fullName:    "success_name",
+			wantErr:     ErrJobRunning,
+			wantState:   success,
+			wantRunning: true,
+			wantStarts:  1,
+		},
+		"autodetection for failed job": {
+			cfgs:      []confgroup.Config{prepareCfg("name", "fail")},
+			action:    ctrlAutoDetection,
+			fullName:  "fail_name",
+			wantState: failed,
+		},
+		"autodetection for job waiting for autodetection retry": {
+			cfgs:          []confgroup.Config{prepareRetryCfg("name", "retry")},
+			action:        ctrlAutoDetection,
+			fullName:      "retry_name",
+			wantState:     retry,
+			wantRetryTask: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &mockRunner{}
+			mgr := NewManager()
+			mgr.Modules = prepareMockRegistry()
+			mgr.Runner = runner
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			mgr.handleAdd(ctx, test.cfgs)
+
+			err := mgr.handleControl(ctx, ctrlRequest{action: test.action, fullName: test.fullName})
+			if test.wantErr != nil {
+				assert.Equal(t, test.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.wantStarts, runner.starts)
+			assert.Equal(t, test.wantStops, runner.stops)
+
+			cfg := test.cfgs[0]
+			assert.Equal(t, test.wantRunning, mgr.startCache.has(cfg))
+			_, ok := mgr.retryCache.lookup(cfg)
+			assert.Equal(t, test.wantRetryTask, ok)
+
+			statuses := mgr.JobStatuses()
+			require.Len(t, statuses, 1)
+			assert.Equal(t, test.wantState, statuses[0].State)
+		})
+	}
+}
+
+func TestManager_RestartJob_ContextCanceled(t *testing.T) {
+	mgr := NewManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, mgr.RestartJob(ctx, "success_name"))
+}
+
+type mockRunner struct {
+	starts int
+	stops  int
+}
+
+func (m *mockRunner) Start(_ job.Job) { m.starts++ }
+func (m *mockRunner) Stop(_ string)   { m.stops++ }
+
+func prepareRetryCfg(name, module string) confgroup.Config {
+	cfg := prepareCfg(name, module)
+	cfg["autodetection_retry"] = 10
+	return cfg
+}
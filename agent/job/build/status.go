@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package build
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+// JobStatus is the state of a job config known to the Manager.
+// Runtime statistics are set only for the jobs that have been built.
+type JobStatus struct {
+	Module             string `json:"module"`
+	Name               string `json:"name"`
+	FullName           string `json:"full_name"`
+	Source             string `json:"source"`
+	Provider           string `json:"provider"`
+	State              string `json:"state"`
+	UpdateEvery        int    `json:"update_every"`
+	AutoDetectionRetry int    `json:"autodetection_retry"`
+	// ConfigIssues are the unknown keys, wrong value types and missing required keys of the job config.
+	ConfigIssues []string `json:"config_issues,omitempty"`
+
+	LastCollect           int64  `json:"last_collect,omitempty"` // unix timestamp
+	LastCollectDurationMs int64  `json:"last_collect_duration_ms"`
+	Penalty               int    `json:"penalty"`
+	Retries               int    `json:"retries"`
+	CollectionFailing     bool   `json:"collection_failing,omitempty"`
+	LastError             string `json:"last_error,omitempty"`
+	LastErrorTime         int64  `json:"last_error_time,omitempty"` // unix timestamp
+	SkippedTicks          int    `json:"skipped_ticks,omitempty"`
+	Panics                int    `json:"panics,omitempty"`
+}
+
+type (
+	statusCache struct {
+		mux   sync.Mutex
+		items map[cfgHash]*jobState
+		// issues are the job configs schema validation issues.
+		issues map[cfgHash][]string
+	}
+	jobState struct {
+		cfg   confgroup.Config
+		state state
+		job   *module.Job
+	}
+)
+
+func newStatusCache() *statusCache {
+	return &statusCache{
+		items:  make(map[cfgHash]*jobState),
+		issues: make(map[cfgHash][]string),
+	}
+}
+
+func (c *statusCache) put(cfg confgroup.Config, st state, job *module.Job) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.items[cfg.Hash()] = &jobState{cfg: cfg, state: st, job: job}
+}
+
+func (c *statusCache) remove(cfg confgroup.Config) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.items, cfg.Hash())
+	delete(c.issues, cfg.Hash())
+}
+
+func (c *statusCache) putConfigIssues(cfg confgroup.Config, issues []string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if len(issues) == 0 {
+		delete(c.issues, cfg.Hash())
+	} else {
+		c.issues[cfg.Hash()] = issues
+	}
+}
+
+// lookup returns the job configs with the full name in the order of the states preference.
+func (c *statusCache) lookup(fullName string, states ...state) []confgroup.Config {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var cfgs []confgroup.Config
+	for _, st := range states {
+		for _, v := range c.items {
+			if v.state == st && v.cfg.FullName() == fullName {
+				cfgs = append(cfgs, v.cfg)
+			}
+		}
+	}
+	return cfgs
+}
+
+func (c *statusCache) has(fullName string) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for _, v := range c.items {
+		if v.cfg.FullName() == fullName {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *statusCache) statuses() []JobStatus {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	statuses := make([]JobStatus, 0, len(c.items))
+	for hash, v := range c.items {
+		status := newJobStatus(v)
+		status.ConfigIssues = c.issues[hash]
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].FullName == statuses[j].FullName {
+			return statuses[i].Source < statuses[j].Source
+		}
+		return statuses[i].FullName < statuses[j].FullName
+	})
+	return statuses
+}
+
+func newJobStatus(js *jobState) JobStatus {
+	status := JobStatus{
+		Module:             js.cfg.Module(),
+		Name:               js.cfg.Name(),
+		FullName:           js.cfg.FullName(),
+		Source:             js.cfg.Source(),
+		Provider:           js.cfg.Provider(),
+		State:              js.state,
+		UpdateEvery:        js.cfg.UpdateEvery(),
+		AutoDetectionRetry: js.cfg.AutoDetectionRetry(),
+	}
+	if js.job == nil {
+		return status
+	}
+
+	stats := js.job.Stats()
+	if !stats.LastRun.IsZero() {
+		status.LastCollect = stats.LastRun.Unix()
+	}
+	status.LastCollectDurationMs = stats.LastRunDuration.Milliseconds()
+	status.Penalty = stats.Penalty
+	status.Retries = stats.Retries
+	status.CollectionFailing = stats.Failing
+	status.LastError = stats.LastError
+	if !stats.LastErrorTime.IsZero() {
+		status.LastErrorTime = stats.LastErrorTime.Unix()
+	}
+	status.SkippedTicks = stats.SkippedTicks
+	status.Panics = stats.Panics
+	return status
+}
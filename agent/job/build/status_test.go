@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_JobStatuses(t *testing.T) {
+	mgr := NewManager()
+	mgr.Modules = prepareMockRegistry()
+	mgr.Runner = &mockRunner{}
+
+	var cfgs []confgroup.Config
+	for _, name := range []string{"success", "fail", "unknown"} {
+		cfg := prepareCfg("name", name)
+		cfg.SetSource("source")
+		cfg.SetProvider("provider")
+		cfgs = append(cfgs, cfg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mgr.handleAdd(ctx, cfgs)
+
+	expected := []JobStatus{
+		{
+			Module:    "fail",
+			Name:      "name",
+			FullName:  "fail_name",
+			Source:    "source",
+			Provider:  "provider",
+			State:     failed,
+			LastError: "init failed",
+		},
+		{
+			Module:   "success",
+			Name:     "name",
+			FullName: "success_name",
+			Source:   "source",
+			Provider: "provider",
+			State:    success,
+		},
+		{
+			Module:   "unknown",
+			Name:     "name",
+			FullName: "unknown_name",
+			Source:   "source",
+			Provider: "provider",
+			State:    buildError,
+		},
+	}
+
+	statuses := mgr.JobStatuses()
+	for i := range statuses {
+		assert.Equal(t, statuses[i].LastError != "", statuses[i].LastErrorTime != 0)
+		statuses[i].LastErrorTime = 0
+	}
+	assert.Equal(t, expected, statuses)
+
+	mgr.handleRemove(ctx, cfgs)
+	assert.Empty(t, mgr.JobStatuses())
+}
+
+func TestManager_JobStatuses_ConfigIssues(t *testing.T) {
+	mgr := NewManager()
+	mgr.Modules = prepareMockRegistry()
+	mgr.Runner = &mockRunner{}
+
+	unknownKey := prepareCfg("unknown_key", "success")
+	unknownKey["update_evry"] = 1
+	wrongType := prepareCfg("wrong_type", "success")
+	wrongType["priority"] = "high"
+	noVnodeHostname := prepareCfg("no_vnode_hostname", "success")
+	noVnodeHostname["vnode"] = map[interface{}]interface{}{"guid": "guid"}
+	cfgs := []confgroup.Config{unknownKey, wrongType, noVnodeHostname}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mgr.handleAdd(ctx, cfgs)
+
+	expected := []JobStatus{
+		{
+			Module:       "success",
+			Name:         "no_vnode_hostname",
+			FullName:     "success_no_vnode_hostname",
+			State:        buildError,
+			ConfigIssues: []string{"'vnode.hostname' is required"},
+		},
+		{
+			Module:       "success",
+			Name:         "unknown_key",
+			FullName:     "success_unknown_key",
+			State:        success,
+			ConfigIssues: []string{"unknown key 'update_evry'"},
+		},
+		{
+			Module:       "success",
+			Name:         "wrong_type",
+			FullName:     "success_wrong_type",
+			State:        buildError,
+			ConfigIssues: []string{"'priority': expected integer, got string"},
+		},
+	}
+
+	assert.Equal(t, expected, mgr.JobStatuses())
+
+	mgr.handleRemove(ctx, cfgs)
+	assert.Empty(t, mgr.JobStatuses())
+}
@@ -3,6 +3,7 @@
 package confgroup
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -19,8 +20,7 @@ type Group struct {
 type Config map[string]interface{}
 
 func (c Config) HashIncludeMap(_ string, k, _ interface{}) (bool, error) {
-	s := k.(string)
-	return !(strings.HasPrefix(s, "__") && strings.HasSuffix(s, "__")), nil
+	return !isInternalKey(k.(string)), nil
 }
 
 func (c Config) Name() string              { v, _ := c.get("name").(string); return v }
@@ -29,13 +29,46 @@ func (c Config) FullName() string          { return fullName(c.Name(), c.Module(
 func (c Config) UpdateEvery() int          { v, _ := c.get("update_every").(int); return v }
 func (c Config) AutoDetectionRetry() int   { v, _ := c.get("autodetection_retry").(int); return v }
 func (c Config) Priority() int             { v, _ := c.get("priority").(int); return v }
-func (c Config) Hash() uint64              { return calcHash(c) }
+func (c Config) CollectTimeout() int       { v, _ := c.get("collect_timeout").(int); return v }
+func (c Config) BackoffMax() int           { v, _ := c.get("backoff_max").(int); return v }
+func (c Config) BackoffJitter() bool       { v, _ := c.get("backoff_jitter").(bool); return v }
+func (c Config) Labels() map[string]string { return toLabels(c.get("labels")) }
+func (c Config) Hash() uint64              { return c.hash() }
 func (c Config) Source() string            { v, _ := c.get("__source__").(string); return v }
 func (c Config) Provider() string          { v, _ := c.get("__provider__").(string); return v }
 func (c Config) SetModule(source string)   { c.set("module", source) }
 func (c Config) SetSource(source string)   { c.set("__source__", source) }
 func (c Config) SetProvider(source string) { c.set("__provider__", source) }
 
+// MergeLabels adds the labels the config doesn't have, the config labels take precedence.
+func (c Config) MergeLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	merged := c.Labels()
+	if merged == nil {
+		merged = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		if _, ok := merged[k]; !ok {
+			merged[k] = v
+		}
+	}
+	c.set("labels", merged)
+}
+
+// Validate checks the config (with the secrets expanded) against the module schema,
+// the internal keys are not checked.
+func (c Config) Validate(schema *module.Schema) (unknown []string, errs []string) {
+	values := make(map[string]interface{}, len(c))
+	for k, v := range c.ExpandSecrets() {
+		if !isInternalKey(k) {
+			values[k] = v
+		}
+	}
+	return schema.Validate(values)
+}
+
 func (c Config) set(key string, value interface{}) { c[key] = value }
 func (c Config) get(key string) interface{}        { return c[key] }
 
@@ -55,6 +88,7 @@ func (c Config) Apply(def Default) {
 	if c.UpdateEvery() < def.MinUpdateEvery && def.MinUpdateEvery > 0 {
 		c.set("update_every", def.MinUpdateEvery)
 	}
+	c.MergeLabels(def.Labels)
 	if c.Name() == "" {
 		c.set("name", c.Module())
 	} else {
@@ -62,6 +96,33 @@ func (c Config) Apply(def Default) {
 	}
 }
 
+func toLabels(v interface{}) map[string]string {
+	var labels map[string]string
+	add := func(k, v interface{}) {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+
+	// the labels are map[interface{}]interface{} after yaml unmarshalling
+	switch v := v.(type) {
+	case map[string]string:
+		for k, v := range v {
+			add(k, v)
+		}
+	case map[string]interface{}:
+		for k, v := range v {
+			add(k, v)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range v {
+			add(k, v)
+		}
+	}
+	return labels
+}
+
 func cleanName(name string) string {
 	return reSpace.ReplaceAllString(name, "_")
 }
@@ -75,6 +136,13 @@ func fullName(name, module string) string {
 	return module + "_" + name
 }
 
+func (c Config) hash() uint64 {
+	if resolved, ok := c.get(keySecrets).(secrets); ok && c.RestartOnSecretChange() {
+		return calcHash([]interface{}{c, map[string]string(resolved)})
+	}
+	return calcHash(c)
+}
+
 func calcHash(obj interface{}) uint64 {
 	hash, _ := hashstructure.Hash(obj, nil)
 	return hash
@@ -86,3 +154,7 @@ func firstPositive(value int, others ...int) int {
 	}
 	return firstPositive(others[0], others[1:]...)
 }
+
+func isInternalKey(key string) bool {
+	return strings.HasPrefix(key, "__") && strings.HasSuffix(key, "__")
+}
@@ -119,6 +119,57 @@ func TestConfig_Priority(t *testing.T) {
 	}
 }
 
+func TestConfig_CollectTimeout(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected interface{}
+	}{
+		"int":     {cfg: Config{"collect_timeout": 5}, expected: 5},
+		"not int": {cfg: Config{"collect_timeout": "5"}, expected: 0},
+		"not set": {cfg: Config{}, expected: 0},
+		"nil cfg": {expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.CollectTimeout())
+		})
+	}
+}
+
+func TestConfig_Labels(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected map[string]string
+	}{
+		"yaml map": {
+			cfg:      Config{"labels": map[interface{}]interface{}{"env": "prod", "port": 80}},
+			expected: map[string]string{"env": "prod", "port": "80"},
+		},
+		"string map": {
+			cfg:      Config{"labels": map[string]string{"env": "prod"}},
+			expected: map[string]string{"env": "prod"},
+		},
+		"not map": {cfg: Config{"labels": "env"}, expected: nil},
+		"not set": {cfg: Config{}, expected: nil},
+		"nil cfg": {expected: nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.Labels())
+		})
+	}
+}
+
+func TestConfig_MergeLabels(t *testing.T) {
+	cfg := Config{"labels": map[interface{}]interface{}{"env": "prod"}}
+
+	cfg.MergeLabels(map[string]string{"env": "dev", "team": "web"})
+
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web"}, cfg.Labels())
+}
+
 func TestConfig_Hash(t *testing.T) {
 	tests := map[string]struct {
 		one, two Config
@@ -298,6 +349,24 @@ func TestConfig_Apply(t *testing.T) {
 				"priority":            module.Priority,
 			},
 		},
+		"merge labels": {
+			def: Default{
+				Labels: map[string]string{"env": "dev", "team": "web"},
+			},
+			origCfg: Config{
+				"name":   "name",
+				"module": "module",
+				"labels": map[interface{}]interface{}{"env": "prod"},
+			},
+			expectedCfg: Config{
+				"name":                "name",
+				"module":              "module",
+				"update_every":        module.UpdateEvery,
+				"autodetection_retry": module.AutoDetectionRetry,
+				"priority":            module.Priority,
+				"labels":              map[string]string{"env": "prod", "team": "web"},
+			},
+		},
 		"clean name": {
 			def: Default{},
 			origCfg: Config{
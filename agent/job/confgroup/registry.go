@@ -9,6 +9,8 @@ type Default struct {
 	UpdateEvery        int `yaml:"update_every"`
 	AutoDetectionRetry int `yaml:"autodetection_retry"`
 	Priority           int `yaml:"priority"`
+	// Labels are added to every job of the module, the job labels take precedence.
+	Labels map[string]string `yaml:"labels"`
 }
 
 func (r Registry) Register(name string, def Default) {
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package confgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The secret references are resolved when the config is read, the config keeps the references and the resolved
+// values are substituted only when the config is passed to the module. Hence, the values are never logged and
+// the config hash doesn't change when a secret is rotated (unless 'restart_on_secret_change' is set).
+
+const keySecrets = "__secrets__"
+
+// secretCmdTimeout is the '${cmd:...}' reference command execution timeout.
+var secretCmdTimeout = time.Second * 10
+
+var reSecretRef = regexp.MustCompile(`\$\{(env|file|cmd):([^}]+)}`)
+
+// secrets holds the resolved values, [reference]value.
+type secrets map[string]string
+
+func (s secrets) String() string { return "***" }
+
+// RestartOnSecretChange returns whether the resolved secret values are a part of the config hash.
+func (c Config) RestartOnSecretChange() bool {
+	v, _ := c.get("restart_on_secret_change").(bool)
+	return v
+}
+
+// ResolveSecrets resolves the '${env:VAR}', '${file:/path}' and '${cmd:command args}' references
+// found in the config string values.
+func (c Config) ResolveSecrets() error {
+	var refs [][]string
+	walkStrings(c, func(s string) string {
+		refs = append(refs, reSecretRef.FindAllStringSubmatch(s, -1)...)
+		return s
+	})
+	if len(refs) == 0 {
+		delete(c, keySecrets)
+		return nil
+	}
+
+	resolved := make(secrets)
+	for _, ref := range refs {
+		if _, ok := resolved[ref[0]]; ok {
+			continue
+		}
+		v, err := resolveSecret(ref[1], ref[2])
+		if err != nil {
+			return fmt.Errorf("resolve '%s': %v", ref[0], err)
+		}
+		resolved[ref[0]] = v
+	}
+	c.set(keySecrets, resolved)
+	return nil
+}
+
+// ExpandSecrets returns a copy of the config with the secret references replaced by the resolved values.
+func (c Config) ExpandSecrets() Config {
+	resolved, _ := c.get(keySecrets).(secrets)
+	if len(resolved) == 0 {
+		return c
+	}
+
+	expanded := make(Config, len(c))
+	for k, v := range c {
+		if k == keySecrets {
+			continue
+		}
+		expanded[k] = copyValue(v)
+	}
+	walkStrings(expanded, func(s string) string {
+		return reSecretRef.ReplaceAllStringFunc(s, func(ref string) string { return resolved[ref] })
+	})
+	return expanded
+}
+
+func resolveSecret(kind, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", errors.New("environment variable is not set")
+		}
+		return v, nil
+	case "file":
+		bs, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(bs), "\r\n"), nil
+	case "cmd":
+		args := strings.Fields(ref)
+		if len(args) == 0 {
+			return "", errors.New("empty command")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+		defer cancel()
+
+		// the output is not a part of the error, it may contain the secret
+		bs, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("command execution: %v", err)
+		}
+		return strings.TrimRight(string(bs), "\r\n"), nil
+	}
+	return "", fmt.Errorf("unknown secret kind '%s'", kind)
+}
+
+// walkStrings replaces all the string values (including the nested ones) with the fn result.
+func walkStrings(c Config, fn func(string) string) {
+	for k, v := range c {
+		if k == keySecrets {
+			continue
+		}
+		c[k] = walkValue(v, fn)
+	}
+}
+
+func walkValue(v interface{}, fn func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case map[interface{}]interface{}:
+		for k, vv := range v {
+			v[k] = walkValue(vv, fn)
+		}
+	case map[string]interface{}:
+		for k, vv := range v {
+			v[k] = walkValue(vv, fn)
+		}
+	case map[string]string:
+		for k, vv := range v {
+			v[k] = fn(vv)
+		}
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = walkValue(vv, fn)
+		}
+	case []string:
+		for i, vv := range v {
+			v[i] = fn(vv)
+		}
+	}
+	return v
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, vv := range v {
+			m[k] = copyValue(vv)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, vv := range v {
+			m[k] = copyValue(vv)
+		}
+		return m
+	case map[string]string:
+		m := make(map[string]string, len(v))
+		for k, vv := range v {
+			m[k] = vv
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, vv := range v {
+			s[i] = copyValue(vv)
+		}
+		return s
+	case []string:
+		return append([]string(nil), v...)
+	}
+	return v
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package confgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("s3cr3t_file\n"), 0600))
+	t.Setenv("TEST_GOD_SECRET", "s3cr3t_env")
+
+	tests := map[string]struct {
+		cfg      Config
+		wantErr  bool
+		expected Config
+	}{
+		"no references": {
+			cfg:      Config{"name": "name", "password": "plain"},
+			expected: Config{"name": "name", "password": "plain"},
+		},
+		"env": {
+			cfg:      Config{"password": "${env:TEST_GOD_SECRET}"},
+			expected: Config{"password": "s3cr3t_env"},
+		},
+		"file": {
+			cfg:      Config{"password": "${file:" + secretFile + "}"},
+			expected: Config{"password": "s3cr3t_file"},
+		},
+		"cmd": {
+			cfg:      Config{"password": "${cmd:printenv TEST_GOD_SECRET}"},
+			expected: Config{"password": "s3cr3t_env"},
+		},
+		"part of the value": {
+			cfg:      Config{"dsn": "user:${env:TEST_GOD_SECRET}@tcp(127.0.0.1:3306)/"},
+			expected: Config{"dsn": "user:s3cr3t_env@tcp(127.0.0.1:3306)/"},
+		},
+		"nested": {
+			cfg: Config{
+				"headers": map[interface{}]interface{}{"X-Token": "${env:TEST_GOD_SECRET}"},
+				"args":    []interface{}{"${env:TEST_GOD_SECRET}"},
+			},
+			expected: Config{
+				"headers": map[interface{}]interface{}{"X-Token": "s3cr3t_env"},
+				"args":    []interface{}{"s3cr3t_env"},
+			},
+		},
+		"env not set": {
+			cfg:     Config{"password": "${env:TEST_GOD_SECRET_NOT_SET}"},
+			wantErr: true,
+		},
+		"file not exists": {
+			cfg:     Config{"password": "${file:" + filepath.Join(dir, "not_exists") + "}"},
+			wantErr: true,
+		},
+		"cmd fails": {
+			cfg:     Config{"password": "${cmd:false}"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.cfg.ResolveSecrets()
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, test.cfg.ExpandSecrets())
+			assert.NotContains(t, fmt.Sprint(test.cfg), "s3cr3t")
+			assert.NotContains(t, fmt.Sprint(test.cfg.ExpandSecrets()), keySecrets)
+		})
+	}
+}
+
+func TestConfig_ResolveSecrets_Hash(t *testing.T) {
+	t.Setenv("TEST_GOD_SECRET", "secret1")
+	cfg := Config{"name": "name", "password": "${env:TEST_GOD_SECRET}"}
+	require.NoError(t, cfg.ResolveSecrets())
+	hash := cfg.Hash()
+
+	t.Setenv("TEST_GOD_SECRET", "secret2")
+	require.NoError(t, cfg.ResolveSecrets())
+	assert.Equal(t, hash, cfg.Hash())
+
+	cfg.set("restart_on_secret_change", true)
+	hash = cfg.Hash()
+	t.Setenv("TEST_GOD_SECRET", "secret3")
+	require.NoError(t, cfg.ResolveSecrets())
+	assert.NotEqual(t, hash, cfg.Hash())
+}
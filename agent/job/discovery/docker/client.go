@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// container is a subset of the Docker Engine API 'ContainerSummary' object.
+// https://docs.docker.com/engine/api/v1.41/#operation/ContainerList
+type container struct {
+	ID         string            `json:"Id"`
+	Names      []string          `json:"Names"`
+	Image      string            `json:"Image"`
+	Labels     map[string]string `json:"Labels"`
+	State      string            `json:"State"`
+	Ports      []containerPort   `json:"Ports"`
+	HostConfig struct {
+		NetworkMode string `json:"NetworkMode"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+type containerPort struct {
+	IP          string `json:"IP"`
+	PrivatePort uint16 `json:"PrivatePort"`
+	PublicPort  uint16 `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+type dockerClient interface {
+	containerList(ctx context.Context) ([]container, error)
+}
+
+func newAPIClient(address string, timeout time.Duration) (*apiClient, error) {
+	var (
+		baseURL string
+		dial    func(ctx context.Context, _, _ string) (net.Conn, error)
+	)
+	dialer := &net.Dialer{Timeout: timeout}
+
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		path := strings.TrimPrefix(address, "unix://")
+		baseURL = "http://docker"
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+	case strings.HasPrefix(address, "tcp://"):
+		baseURL = "http://" + strings.TrimPrefix(address, "tcp://")
+		dial = dialer.DialContext
+	case strings.HasPrefix(address, "http://"):
+		baseURL = address
+		dial = dialer.DialContext
+	default:
+		return nil, fmt.Errorf("unsupported address scheme: '%s'", address)
+	}
+
+	return &apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dial},
+		},
+	}, nil
+}
+
+type apiClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func (c *apiClient) containerList(ctx context.Context) ([]container, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/containers/json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("'%s' returned HTTP status code: %d", req.URL, resp.StatusCode)
+	}
+
+	var containers []container
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("error on decoding response from '%s': %v", req.URL, err)
+	}
+	return containers, nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainersJSON = `
+[
+  {
+    "Id": "8dfafdbc3a40",
+    "Names": ["/redis"],
+    "Image": "redis:7",
+    "Labels": {"com.example.team": "core"},
+    "State": "running",
+    "Ports": [
+      {"IP": "0.0.0.0", "PrivatePort": 6379, "PublicPort": 6379, "Type": "tcp"},
+      {"IP": "::", "PrivatePort": 6379, "PublicPort": 6379, "Type": "tcp"}
+    ],
+    "HostConfig": {"NetworkMode": "default"},
+    "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.2"}}}
+  }
+]
+`
+
+func TestNewAPIClient(t *testing.T) {
+	tests := map[string]struct {
+		address string
+		wantErr bool
+	}{
+		"unix socket":        {address: "unix:///var/run/docker.sock"},
+		"tcp":                {address: "tcp://127.0.0.1:2375"},
+		"http":               {address: "http://127.0.0.1:2375"},
+		"unsupported scheme": {address: "ssh://127.0.0.1", wantErr: true},
+		"no scheme":          {address: "/var/run/docker.sock", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := newAPIClient(test.address, time.Second)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, client)
+			}
+		})
+	}
+}
+
+func TestAPIClient_containerList(t *testing.T) {
+	tests := map[string]struct {
+		handler  http.HandlerFunc
+		wantFail bool
+	}{
+		"valid response": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/containers/json" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(testContainersJSON))
+			},
+		},
+		"invalid response": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("hello and goodbye"))
+			},
+			wantFail: true,
+		},
+		"404 response": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantFail: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(test.handler)
+			defer srv.Close()
+
+			client, err := newAPIClient(srv.URL, time.Second)
+			require.NoError(t, err)
+
+			containers, err := client.containerList(context.Background())
+
+			if test.wantFail {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, containers, 1)
+				assert.Equal(t, "8dfafdbc3a40", containers[0].ID)
+				assert.Len(t, containers[0].Ports, 2)
+				assert.Equal(t, "172.17.0.2", containers[0].NetworkSettings.Networks["bridge"].IPAddress)
+			}
+		})
+	}
+}
+
+func TestAPIClient_containerList_UnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netdata-go-test-discovery-docker")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	sock := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testContainersJSON))
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	client, err := newAPIClient("unix://"+sock, time.Second)
+	require.NoError(t, err)
+
+	containers, err := client.containerList(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, containers, 1)
+}
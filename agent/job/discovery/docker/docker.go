@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/logger"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/ilyam8/hashstructure"
+)
+
+const (
+	defaultAddress      = "unix:///var/run/docker.sock"
+	defaultTimeout      = time.Second * 2
+	defaultRefreshEvery = time.Second * 10
+)
+
+type Config struct {
+	Address      string       `yaml:"address"`
+	Timeout      web.Duration `yaml:"timeout"`
+	RefreshEvery web.Duration `yaml:"refresh_every"`
+}
+
+type (
+	targetGroup struct {
+		source  string
+		targets []model.Target
+	}
+	// target is a single container port (or a container without ports).
+	// Its exported fields are available in the classify and compose templates.
+	target struct {
+		model.Base `hash:"ignore"`
+		hash       uint64
+		tuid       string
+
+		Address      string
+		ID           string
+		Name         string
+		Image        string
+		Labels       map[string]string
+		NetworkMode  string
+		IP           string
+		Port         string
+		PortProtocol string
+		PublicPort   string
+		HostIP       string
+	}
+)
+
+func (g *targetGroup) Provider() string        { return "docker" }
+func (g *targetGroup) Source() string          { return g.source }
+func (g *targetGroup) Targets() []model.Target { return g.targets }
+
+func (t *target) Hash() uint64 { return t.hash }
+func (t *target) TUID() string { return t.tuid }
+
+func (t *target) JobLabels() map[string]string {
+	return map[string]string{
+		"container_name":  t.Name,
+		"container_image": t.Image,
+	}
+}
+
+type (
+	Discovery struct {
+		*logger.Logger
+		client       dockerClient
+		refreshEvery time.Duration
+		cache        cache
+	}
+	cache map[string]uint64 // [container ID]hash
+)
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	address, timeout := cfg.Address, cfg.Timeout.Duration
+	if address == "" {
+		address = defaultAddress
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client, err := newAPIClient(address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("docker discovery client: %v", err)
+	}
+	return newDiscovery(cfg, client)
+}
+
+func newDiscovery(cfg Config, client dockerClient) (*Discovery, error) {
+	d := &Discovery{
+		Logger:       logger.New("discovery", "docker"),
+		client:       client,
+		refreshEvery: cfg.RefreshEvery.Duration,
+		cache:        make(cache),
+	}
+	if d.refreshEvery <= 0 {
+		d.refreshEvery = defaultRefreshEvery
+	}
+	return d, nil
+}
+
+func (d *Discovery) String() string {
+	return "docker discovery"
+}
+
+func (d *Discovery) Discover(ctx context.Context, in chan<- []model.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	d.refresh(ctx, in)
+
+	tk := time.NewTicker(d.refreshEvery)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+			d.refresh(ctx, in)
+		}
+	}
+}
+
+func (d *Discovery) refresh(ctx context.Context, in chan<- []model.TargetGroup) {
+	containers, err := d.client.containerList(ctx)
+	if err != nil {
+		d.Warningf("list containers: %v", err)
+		return
+	}
+
+	var groups []model.TargetGroup
+	seen := make(map[string]bool)
+
+	for _, cntr := range containers {
+		seen[cntr.ID] = true
+
+		hash, _ := hashstructure.Hash(cntr, nil)
+		if v, ok := d.cache[cntr.ID]; ok && v == hash {
+			continue
+		}
+		d.cache[cntr.ID] = hash
+
+		groups = append(groups, &targetGroup{source: containerSource(cntr), targets: containerTargets(cntr)})
+	}
+
+	// stopped containers are not listed, their groups are removed the same way as removed files' groups.
+	for id := range d.cache {
+		if seen[id] {
+			continue
+		}
+		delete(d.cache, id)
+		groups = append(groups, &targetGroup{source: containerSource(container{ID: id})})
+	}
+
+	send(ctx, in, groups)
+}
+
+func containerTargets(cntr container) []model.Target {
+	newTarget := func() *target {
+		tgt := &target{
+			ID:          cntr.ID,
+			Name:        containerName(cntr),
+			Image:       cntr.Image,
+			Labels:      cntr.Labels,
+			NetworkMode: cntr.HostConfig.NetworkMode,
+			IP:          containerIP(cntr),
+		}
+		tgt.Address = tgt.IP
+		return tgt
+	}
+
+	var targets []model.Target
+	seen := make(map[string]bool)
+
+	for _, port := range cntr.Ports {
+		// published ports are listed once per host address family
+		key := strconv.Itoa(int(port.PrivatePort)) + "/" + port.Type
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		tgt := newTarget()
+		tgt.Port = strconv.Itoa(int(port.PrivatePort))
+		tgt.PortProtocol = port.Type
+		tgt.HostIP = port.IP
+		if port.PublicPort != 0 {
+			tgt.PublicPort = strconv.Itoa(int(port.PublicPort))
+		}
+		tgt.Address = net.JoinHostPort(tgt.IP, tgt.Port)
+		tgt.tuid = fmt.Sprintf("%s_%s_%s", tgt.Name, tgt.Port, tgt.PortProtocol)
+		tgt.hash, _ = hashstructure.Hash(tgt, nil)
+		targets = append(targets, tgt)
+	}
+
+	if len(targets) == 0 {
+		tgt := newTarget()
+		tgt.tuid = tgt.Name
+		tgt.hash, _ = hashstructure.Hash(tgt, nil)
+		targets = append(targets, tgt)
+	}
+	return targets
+}
+
+func containerName(cntr container) string {
+	if len(cntr.Names) == 0 {
+		return cntr.ID
+	}
+	return strings.TrimPrefix(cntr.Names[0], "/")
+}
+
+func containerIP(cntr container) string {
+	// containers in the host network namespace share the host address
+	if cntr.HostConfig.NetworkMode == "host" {
+		return "127.0.0.1"
+	}
+	var names []string
+	for name := range cntr.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ip := cntr.NetworkSettings.Networks[name].IPAddress; ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+func containerSource(cntr container) string {
+	return "docker/" + cntr.ID
+}
+
+func send(ctx context.Context, in chan<- []model.TargetGroup, groups []model.TargetGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case in <- groups:
+	}
+}
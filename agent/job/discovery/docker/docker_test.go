@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package docker
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"default config": {
+			cfg: Config{},
+		},
+		"valid config": {
+			cfg: Config{Address: "tcp://127.0.0.1:2375"},
+		},
+		"invalid config, unsupported address": {
+			cfg:     Config{Address: "ssh://127.0.0.1"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_Discover(t *testing.T) {
+	tests := map[string]struct {
+		before   []container
+		after    []container
+		expected []model.TargetGroup
+	}{
+		"containers running before start": {
+			before: []container{newRedisContainer(), newNginxContainer()},
+			expected: []model.TargetGroup{
+				prepareGroup(newRedisContainer()),
+				prepareGroup(newNginxContainer()),
+			},
+		},
+		"container started after start": {
+			after: []container{newRedisContainer()},
+			expected: []model.TargetGroup{
+				prepareGroup(newRedisContainer()),
+			},
+		},
+		"container stopped after start": {
+			before: []container{newRedisContainer()},
+			after:  []container{},
+			expected: []model.TargetGroup{
+				prepareGroup(newRedisContainer()),
+				&targetGroup{source: "docker/8dfafdbc3a40"},
+			},
+		},
+		"container without ports": {
+			before: []container{newBusyboxContainer()},
+			expected: []model.TargetGroup{
+				prepareGroup(newBusyboxContainer()),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mockClient{containers: test.before}
+			d, err := newDiscovery(Config{}, client)
+			require.NoError(t, err)
+			d.refreshEvery = time.Millisecond * 100
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			in := make(chan []model.TargetGroup)
+			go d.Discover(ctx, in)
+
+			var groups []model.TargetGroup
+			if test.after != nil {
+				if len(test.before) > 0 {
+					groups = append(groups, <-in...)
+				}
+				client.setContainers(test.after)
+			}
+			for len(groups) < len(test.expected) {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("timed out, got %d groups, expected %d", len(groups), len(test.expected))
+				case updates := <-in:
+					groups = append(groups, updates...)
+				}
+			}
+
+			sortGroups(groups)
+			sortGroups(test.expected)
+			assert.Equal(t, test.expected, groups)
+		})
+	}
+}
+
+func TestContainerTargets(t *testing.T) {
+	targets := containerTargets(newRedisContainer())
+	require.Len(t, targets, 1)
+
+	tgt := targets[0].(*target)
+	assert.Equal(t, "redis_6379_tcp", tgt.TUID())
+	assert.NotZero(t, tgt.Hash())
+	assert.Equal(t, "172.17.0.2:6379", tgt.Address)
+	assert.Equal(t, "6379", tgt.PublicPort)
+	assert.Equal(t, "0.0.0.0", tgt.HostIP)
+
+	targets = containerTargets(newBusyboxContainer())
+	require.Len(t, targets, 1)
+	tgt = targets[0].(*target)
+	assert.Equal(t, "busybox", tgt.TUID())
+	assert.Equal(t, "172.17.0.4", tgt.Address)
+}
+
+func TestDiscovery_Discover_ListError(t *testing.T) {
+	client := &mockClient{errOnList: true}
+	d, err := newDiscovery(Config{}, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan []model.TargetGroup, 1)
+	d.refresh(ctx, in)
+
+	assert.Len(t, in, 0)
+	assert.Len(t, d.cache, 0)
+}
+
+type mockClient struct {
+	mux        sync.Mutex
+	containers []container
+	errOnList  bool
+}
+
+func (m *mockClient) containerList(_ context.Context) ([]container, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.errOnList {
+		return nil, errors.New("mock.containerList() error")
+	}
+	return m.containers, nil
+}
+
+func (m *mockClient) setContainers(containers []container) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.containers = containers
+}
+
+func newRedisContainer() container {
+	var cntr container
+	cntr.ID = "8dfafdbc3a40"
+	cntr.Names = []string{"/redis"}
+	cntr.Image = "redis:7"
+	cntr.State = "running"
+	cntr.Ports = []containerPort{
+		{IP: "0.0.0.0", PrivatePort: 6379, PublicPort: 6379, Type: "tcp"},
+		{IP: "::", PrivatePort: 6379, PublicPort: 6379, Type: "tcp"},
+	}
+	cntr.NetworkSettings.Networks = map[string]struct {
+		IPAddress string `json:"IPAddress"`
+	}{"bridge": {IPAddress: "172.17.0.2"}}
+	return cntr
+}
+
+func newNginxContainer() container {
+	var cntr container
+	cntr.ID = "9e2c94e5ea1f"
+	cntr.Names = []string{"/web"}
+	cntr.Image = "nginx:1.23"
+	cntr.Labels = map[string]string{"netdata.module": "nginx"}
+	cntr.State = "running"
+	cntr.Ports = []containerPort{
+		{PrivatePort: 80, Type: "tcp"},
+	}
+	cntr.NetworkSettings.Networks = map[string]struct {
+		IPAddress string `json:"IPAddress"`
+	}{"bridge": {IPAddress: "172.17.0.3"}}
+	return cntr
+}
+
+func newBusyboxContainer() container {
+	var cntr container
+	cntr.ID = "5c1f2a9e0b3d"
+	cntr.Names = []string{"/busybox"}
+	cntr.Image = "busybox"
+	cntr.State = "running"
+	cntr.NetworkSettings.Networks = map[string]struct {
+		IPAddress string `json:"IPAddress"`
+	}{"bridge": {IPAddress: "172.17.0.4"}}
+	return cntr
+}
+
+func prepareGroup(cntr container) *targetGroup {
+	return &targetGroup{source: containerSource(cntr), targets: containerTargets(cntr)}
+}
+
+func sortGroups(groups []model.TargetGroup) {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Source() < groups[j].Source() })
+}
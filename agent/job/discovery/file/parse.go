@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/logger"
 
 	"gopkg.in/yaml.v2"
 )
@@ -21,7 +22,9 @@ const (
 	sdFormat
 )
 
-func parse(req confgroup.Registry, path string) (*confgroup.Group, error) {
+// parse reads the file config group. A job which secret references can't be resolved is logged and dropped,
+// the rest of the group is kept.
+func parse(req confgroup.Registry, path string, log *logger.Logger) (*confgroup.Group, error) {
 	bs, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -32,9 +35,9 @@ func parse(req confgroup.Registry, path string) (*confgroup.Group, error) {
 
 	switch cfgFormat(bs) {
 	case staticFormat:
-		return parseStaticFormat(req, path, bs)
+		return parseStaticFormat(req, path, bs, log)
 	case sdFormat:
-		return parseSDFormat(req, path, bs)
+		return parseSDFormat(req, path, bs, log)
 	case unknownEmptyFormat:
 		return nil, nil
 	default:
@@ -42,7 +45,7 @@ func parse(req confgroup.Registry, path string) (*confgroup.Group, error) {
 	}
 }
 
-func parseStaticFormat(reg confgroup.Registry, path string, bs []byte) (*confgroup.Group, error) {
+func parseStaticFormat(reg confgroup.Registry, path string, bs []byte, log *logger.Logger) (*confgroup.Group, error) {
 	name := fileName(path)
 	modDef, ok := reg.Lookup(name)
 	if !ok {
@@ -53,19 +56,26 @@ func parseStaticFormat(reg confgroup.Registry, path string, bs []byte) (*confgro
 	if err := yaml.Unmarshal(bs, &modCfg); err != nil {
 		return nil, err
 	}
+	var i int
 	for _, cfg := range modCfg.Jobs {
 		cfg.SetModule(name)
 		def := mergeDef(modCfg.Default, modDef)
 		cfg.Apply(def)
+		if err := cfg.ResolveSecrets(); err != nil {
+			log.Warningf("parse '%s': skipping job '%s': %v", path, cfg.Name(), err)
+			continue
+		}
+		modCfg.Jobs[i] = cfg
+		i++
 	}
 	group := &confgroup.Group{
-		Configs: modCfg.Jobs,
+		Configs: modCfg.Jobs[:i],
 		Source:  path,
 	}
 	return group, nil
 }
 
-func parseSDFormat(reg confgroup.Registry, path string, bs []byte) (*confgroup.Group, error) {
+func parseSDFormat(reg confgroup.Registry, path string, bs []byte, log *logger.Logger) (*confgroup.Group, error) {
 	var cfgs sdConfig
 	if err := yaml.Unmarshal(bs, &cfgs); err != nil {
 		return nil, err
@@ -75,6 +85,10 @@ func parseSDFormat(reg confgroup.Registry, path string, bs []byte) (*confgroup.G
 	for _, cfg := range cfgs {
 		if def, ok := reg.Lookup(cfg.Module()); ok && cfg.Module() != "" {
 			cfg.Apply(def)
+			if err := cfg.ResolveSecrets(); err != nil {
+				log.Warningf("parse '%s': skipping job '%s': %v", path, cfg.FullName(), err)
+				continue
+			}
 			cfgs[i] = cfg
 			i++
 		}
@@ -116,7 +130,22 @@ func mergeDef(a, b confgroup.Default) confgroup.Default {
 		UpdateEvery:        firstPositive(a.UpdateEvery, b.UpdateEvery),
 		AutoDetectionRetry: firstPositive(a.AutoDetectionRetry, b.AutoDetectionRetry),
 		Priority:           firstPositive(a.Priority, b.Priority),
+		Labels:             mergeLabels(a.Labels, b.Labels),
+	}
+}
+
+func mergeLabels(a, b map[string]string) map[string]string {
+	if len(b) == 0 {
+		return a
+	}
+	labels := make(map[string]string, len(a)+len(b))
+	for k, v := range b {
+		labels[k] = v
+	}
+	for k, v := range a {
+		labels[k] = v
 	}
+	return labels
 }
 
 func firstPositive(value int, others ...int) int {
@@ -58,7 +58,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -96,7 +96,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -137,7 +137,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -173,7 +173,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -205,7 +205,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -243,7 +243,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -278,7 +278,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -309,7 +309,7 @@ func TestParse(t *testing.T) {
 				},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -331,7 +331,7 @@ func TestParse(t *testing.T) {
 				Configs: []confgroup.Config{},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -354,7 +354,7 @@ func TestParse(t *testing.T) {
 				Configs: []confgroup.Config{},
 			}
 
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			require.NoError(t, err)
 			assert.Equal(t, expected, group)
@@ -365,7 +365,7 @@ func TestParse(t *testing.T) {
 			}
 
 			filename := tmp.createFile("empty-*")
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			assert.Nil(t, group)
 			require.NoError(t, err)
@@ -375,17 +375,56 @@ func TestParse(t *testing.T) {
 
 			filename := tmp.createFile("unknown-empty-format-*")
 			tmp.writeString(filename, "# a comment")
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			assert.Nil(t, group)
 			assert.NoError(t, err)
 		},
+		"static, secret references": func(t *testing.T, tmp *tmpDir) {
+			t.Setenv("TEST_GOD_PASSWORD", "password")
+			reg := confgroup.Registry{"module": {}}
+			filename := tmp.join("module.conf")
+			tmp.writeString(filename, "jobs:\n  - name: name\n    password: ${env:TEST_GOD_PASSWORD}\n")
+
+			group, err := parse(reg, filename, nil)
+
+			require.NoError(t, err)
+			require.Len(t, group.Configs, 1)
+			assert.Equal(t, "${env:TEST_GOD_PASSWORD}", group.Configs[0]["password"])
+			assert.Equal(t, "password", group.Configs[0].ExpandSecrets()["password"])
+		},
+		"static, unresolved secret reference": func(t *testing.T, tmp *tmpDir) {
+			reg := confgroup.Registry{"module": {}}
+			filename := tmp.join("module.conf")
+			tmp.writeString(filename, "jobs:\n"+
+				"  - name: bad\n    password: ${env:TEST_GOD_PASSWORD_NOT_SET}\n"+
+				"  - name: good\n    password: secret\n")
+
+			group, err := parse(reg, filename, nil)
+
+			require.NoError(t, err)
+			require.Len(t, group.Configs, 1)
+			assert.Equal(t, "good", group.Configs[0].Name())
+		},
+		"sd, unresolved secret reference": func(t *testing.T, tmp *tmpDir) {
+			reg := confgroup.Registry{"module": {}}
+			filename := tmp.join("module.conf")
+			tmp.writeString(filename, ""+
+				"- module: module\n  name: bad\n  password: ${env:TEST_GOD_PASSWORD_NOT_SET}\n"+
+				"- module: module\n  name: good\n")
+
+			group, err := parse(reg, filename, nil)
+
+			require.NoError(t, err)
+			require.Len(t, group.Configs, 1)
+			assert.Equal(t, "good", group.Configs[0].Name())
+		},
 		"unknown format": func(t *testing.T, tmp *tmpDir) {
 			reg := confgroup.Registry{}
 
 			filename := tmp.createFile("unknown-format-*")
 			tmp.writeYAML(filename, "unknown")
-			group, err := parse(reg, filename)
+			group, err := parse(reg, filename, nil)
 
 			assert.Nil(t, group)
 			assert.Error(t, err)
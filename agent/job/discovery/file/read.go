@@ -60,7 +60,7 @@ func (r Reader) groups() (groups []*confgroup.Group) {
 				continue
 			}
 
-			group, err := parse(r.reg, path)
+			group, err := parse(r.reg, path, r.Logger)
 			if err != nil {
 				r.Warningf("parse '%s': %v", path, err)
 				continue
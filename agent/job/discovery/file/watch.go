@@ -139,7 +139,7 @@ func (w *Watcher) refresh(ctx context.Context, in chan<- []*confgroup.Group) {
 		}
 		w.cache.put(file, fi.ModTime())
 
-		if group, err := parse(w.reg, file); err != nil {
+		if group, err := parse(w.reg, file, w.Logger); err != nil {
 			w.Warningf("parse '%s': %v", file, err)
 		} else if group == nil {
 			groups = append(groups, &confgroup.Group{Source: file})
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type role string
+
+const (
+	rolePod     role = "pod"
+	roleService role = "service"
+)
+
+const (
+	envNodeName  = "MY_NODE_NAME"
+	resyncPeriod = 10 * time.Minute
+)
+
+type Config struct {
+	Role       string   `yaml:"role"`
+	Namespaces []string `yaml:"namespaces"`
+	Selector   struct {
+		Label string `yaml:"label"`
+		Field string `yaml:"field"`
+	} `yaml:"selector"`
+	// LocalMode limits the pod discovery to the node the plugin is running on (MY_NODE_NAME env variable).
+	LocalMode bool `yaml:"local_mode"`
+}
+
+func validateConfig(cfg Config) error {
+	switch role(cfg.Role) {
+	case rolePod, roleService:
+	default:
+		return fmt.Errorf("unknown role: '%s'", cfg.Role)
+	}
+	if cfg.LocalMode && role(cfg.Role) == rolePod && os.Getenv(envNodeName) == "" {
+		return fmt.Errorf("local mode: '%s' environment variable not set", envNodeName)
+	}
+	return nil
+}
+
+type (
+	discoverer interface {
+		run(ctx context.Context, in chan<- []model.TargetGroup)
+	}
+	Discovery struct {
+		*logger.Logger
+		client      kubernetes.Interface
+		role        role
+		namespaces  []string
+		selectorLbl string
+		selectorFld string
+		discoverers []discoverer
+	}
+)
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("k8s discovery config validation: %v", err)
+	}
+	client, err := newKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("k8s discovery client: %v", err)
+	}
+	return newDiscovery(cfg, client)
+}
+
+func newDiscovery(cfg Config, client kubernetes.Interface) (*Discovery, error) {
+	d := &Discovery{
+		Logger:      logger.New("discovery", "k8s "+cfg.Role),
+		client:      client,
+		role:        role(cfg.Role),
+		namespaces:  cfg.Namespaces,
+		selectorLbl: cfg.Selector.Label,
+		selectorFld: cfg.Selector.Field,
+	}
+	if len(d.namespaces) == 0 {
+		d.namespaces = []string{corev1.NamespaceAll}
+	}
+	if cfg.LocalMode && d.role == rolePod {
+		d.selectorFld = joinSelectors(d.selectorFld, "spec.nodeName="+os.Getenv(envNodeName))
+	}
+	return d, nil
+}
+
+func (d *Discovery) String() string {
+	return fmt.Sprintf("k8s %s discovery: %v", d.role, d.namespaces)
+}
+
+func (d *Discovery) Discover(ctx context.Context, in chan<- []model.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	d.discoverers = d.setupDiscoverers(ctx)
+
+	var wg sync.WaitGroup
+	for _, dd := range d.discoverers {
+		wg.Add(1)
+		go func(dd discoverer) { defer wg.Done(); dd.run(ctx, in) }(dd)
+	}
+
+	wg.Wait()
+	<-ctx.Done()
+}
+
+func (d *Discovery) setupDiscoverers(ctx context.Context) []discoverer {
+	var discoverers []discoverer
+	for _, namespace := range d.namespaces {
+		switch d.role {
+		case rolePod:
+			pod := d.client.CoreV1().Pods(namespace)
+			lw := &cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					d.applySelectors(&opts)
+					return pod.List(ctx, opts)
+				},
+				WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+					d.applySelectors(&opts)
+					return pod.Watch(ctx, opts)
+				},
+			}
+			si := cache.NewSharedInformer(lw, &corev1.Pod{}, resyncPeriod)
+			discoverers = append(discoverers, newPodDiscoverer(si, d))
+		case roleService:
+			svc := d.client.CoreV1().Services(namespace)
+			lw := &cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					d.applySelectors(&opts)
+					return svc.List(ctx, opts)
+				},
+				WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+					d.applySelectors(&opts)
+					return svc.Watch(ctx, opts)
+				},
+			}
+			si := cache.NewSharedInformer(lw, &corev1.Service{}, resyncPeriod)
+			discoverers = append(discoverers, newServiceDiscoverer(si, d))
+		}
+	}
+	return discoverers
+}
+
+func (d *Discovery) applySelectors(opts *metav1.ListOptions) {
+	opts.LabelSelector = d.selectorLbl
+	opts.FieldSelector = d.selectorFld
+}
+
+func enqueue(queue *workqueue.Type, obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	queue.Add(key)
+}
+
+func send(ctx context.Context, in chan<- []model.TargetGroup, group model.TargetGroup) {
+	if group == nil {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case in <- []model.TargetGroup{group}:
+	}
+}
+
+func joinSelectors(srs ...string) string {
+	var i int
+	for _, v := range srs {
+		if v != "" {
+			srs[i] = v
+			i++
+		}
+	}
+	return strings.Join(srs[:i], ",")
+}
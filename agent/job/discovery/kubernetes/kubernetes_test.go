@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"invalid config, unknown role": {
+			cfg:     Config{Role: "node"},
+			wantErr: true,
+		},
+		"invalid config, local mode without node name": {
+			cfg:     Config{Role: "pod", LocalMode: true},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_Run(t *testing.T) {
+	tests := map[string]func() discoverySim{
+		"pod role: pods exist before run": func() discoverySim {
+			redis, nginx := newRedisPod(), newNginxPod()
+			d := prepareDiscovery(t, rolePod, redis, nginx)
+
+			return discoverySim{
+				discovery: d,
+				expectedGroups: []model.TargetGroup{
+					preparePodGroup(nginx),
+					preparePodGroup(redis),
+				},
+			}
+		},
+		"pod role: pod added and deleted after run": func() discoverySim {
+			d := prepareDiscovery(t, rolePod)
+			redis := newRedisPod()
+
+			return discoverySim{
+				discovery: d,
+				runAfterSync: func(ctx context.Context) {
+					pods := d.client.CoreV1().Pods(redis.Namespace)
+					_, _ = pods.Create(ctx, redis, metav1.CreateOptions{})
+					time.Sleep(time.Millisecond * 100)
+					_ = pods.Delete(ctx, redis.Name, metav1.DeleteOptions{})
+				},
+				expectedGroups: []model.TargetGroup{
+					preparePodGroup(redis),
+					&podTargetGroup{source: "k8s/pod/default/redis-5b97d"},
+				},
+			}
+		},
+		"pod role: pod without ip": func() discoverySim {
+			redis := newRedisPod()
+			redis.Status.PodIP = ""
+			d := prepareDiscovery(t, rolePod, redis)
+
+			return discoverySim{
+				discovery: d,
+				expectedGroups: []model.TargetGroup{
+					&podTargetGroup{source: "k8s/pod/default/redis-5b97d"},
+				},
+			}
+		},
+		"service role: services exist before run": func() discoverySim {
+			svc := newRedisService()
+			d := prepareDiscovery(t, roleService, svc)
+
+			return discoverySim{
+				discovery: d,
+				expectedGroups: []model.TargetGroup{
+					&serviceTargetGroup{source: "k8s/service/default/redis-master", targets: serviceTargets(svc)},
+				},
+			}
+		},
+	}
+
+	for name, createSim := range tests {
+		t.Run(name, func(t *testing.T) {
+			sim := createSim()
+			sim.run(t)
+		})
+	}
+}
+
+func prepareDiscovery(t *testing.T, r role, objects ...runtime.Object) *Discovery {
+	cfg := Config{Role: string(r)}
+	require.NoError(t, validateConfig(cfg))
+	d, err := newDiscovery(cfg, fake.NewSimpleClientset(objects...))
+	require.NoError(t, err)
+	return d
+}
+
+func preparePodGroup(pod *corev1.Pod) *podTargetGroup {
+	return &podTargetGroup{source: podSource(pod.Namespace, pod.Name), targets: podTargets(pod)}
+}
+
+func newRedisPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis-5b97d",
+			Namespace: "default",
+			UID:       "ef7e5f5c-3b2b-4b1a-8d9b-3e8b7c2a0c01",
+			Labels:    map[string]string{"app": "redis"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{
+					Name:  "redis",
+					Image: "docker.io/library/redis:7",
+					Ports: []corev1.ContainerPort{
+						{Name: "redis", ContainerPort: 6379, Protocol: corev1.ProtocolTCP},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.1",
+		},
+	}
+}
+
+func newNginxPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx-7f8b4",
+			Namespace: "default",
+			UID:       "ef7e5f5c-3b2b-4b1a-8d9b-3e8b7c2a0c02",
+			Labels:    map[string]string{"app": "nginx"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node1",
+			Containers: []corev1.Container{
+				{
+					Name:  "nginx",
+					Image: "nginx:1.23",
+					Ports: []corev1.ContainerPort{
+						{Name: "http", ContainerPort: 80, Protocol: corev1.ProtocolTCP},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.2",
+		},
+	}
+}
+
+func newRedisService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis-master",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "redis"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: "10.96.0.10",
+			Ports: []corev1.ServicePort{
+				{Name: "redis", Port: 6379, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/ilyam8/hashstructure"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type (
+	podTargetGroup struct {
+		source  string
+		targets []model.Target
+	}
+	// podTarget is a single pod container port (or a container without ports).
+	// Its exported fields are available in the classify and compose templates.
+	podTarget struct {
+		model.Base `hash:"ignore"`
+		hash       uint64
+		tuid       string
+
+		Address        string
+		Namespace      string
+		Name           string
+		UID            string
+		Labels         map[string]string
+		Annotations    map[string]string
+		NodeName       string
+		PodIP          string
+		ControllerName string
+		ControllerKind string
+		ContName       string
+		Image          string
+		Env            map[string]string
+		Port           string
+		PortName       string
+		PortProtocol   string
+	}
+)
+
+func (g *podTargetGroup) Provider() string        { return "kubernetes" }
+func (g *podTargetGroup) Source() string          { return g.source }
+func (g *podTargetGroup) Targets() []model.Target { return g.targets }
+
+func (t *podTarget) Hash() uint64 { return t.hash }
+func (t *podTarget) TUID() string { return t.tuid }
+
+func (t *podTarget) JobLabels() map[string]string {
+	return map[string]string{
+		"k8s_namespace":       t.Namespace,
+		"k8s_pod_name":        t.Name,
+		"k8s_container_name":  t.ContName,
+		"k8s_controller_kind": t.ControllerKind,
+		"k8s_controller_name": t.ControllerName,
+	}
+}
+
+func newPodDiscoverer(si cache.SharedInformer, d *Discovery) *podDiscoverer {
+	if si == nil {
+		panic("nil pod shared informer")
+	}
+
+	queue := workqueue.NewNamed("pod")
+	si.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { enqueue(queue, obj) },
+		UpdateFunc: func(_, obj interface{}) { enqueue(queue, obj) },
+		DeleteFunc: func(obj interface{}) { enqueue(queue, obj) },
+	})
+
+	return &podDiscoverer{
+		Discovery: d,
+		informer:  si,
+		queue:     queue,
+	}
+}
+
+type podDiscoverer struct {
+	*Discovery
+	informer cache.SharedInformer
+	queue    *workqueue.Type
+}
+
+func (p *podDiscoverer) run(ctx context.Context, in chan<- []model.TargetGroup) {
+	p.Info("pod_discoverer is started")
+	defer func() { p.Info("pod_discoverer is stopped") }()
+
+	defer p.queue.ShutDown()
+
+	go p.informer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), p.informer.HasSynced) {
+		return
+	}
+
+	go p.runDiscover(ctx, in)
+
+	<-ctx.Done()
+}
+
+func (p *podDiscoverer) runDiscover(ctx context.Context, in chan<- []model.TargetGroup) {
+	for {
+		item, shutdown := p.queue.Get()
+		if shutdown {
+			return
+		}
+
+		func() {
+			defer p.queue.Done(item)
+
+			key := item.(string)
+			ns, name, err := cache.SplitMetaNamespaceKey(key)
+			if err != nil {
+				return
+			}
+
+			obj, exists, err := p.informer.GetStore().GetByKey(key)
+			if err != nil {
+				return
+			}
+
+			source := podSource(ns, name)
+			if !exists {
+				send(ctx, in, &podTargetGroup{source: source})
+				return
+			}
+
+			pod, ok := obj.(*corev1.Pod)
+			if !ok {
+				return
+			}
+			send(ctx, in, &podTargetGroup{source: source, targets: podTargets(pod)})
+		}()
+	}
+}
+
+func podTargets(pod *corev1.Pod) []model.Target {
+	// the pod is not scheduled yet or is being terminated, there is nothing to collect from.
+	if pod.Status.PodIP == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil
+	}
+
+	ctrlName, ctrlKind := controllerRef(pod)
+
+	var targets []model.Target
+	for _, container := range pod.Spec.Containers {
+		env := containerEnv(container)
+		newTarget := func() *podTarget {
+			return &podTarget{
+				Address:        pod.Status.PodIP,
+				Namespace:      pod.Namespace,
+				Name:           pod.Name,
+				UID:            string(pod.UID),
+				Labels:         pod.Labels,
+				Annotations:    pod.Annotations,
+				NodeName:       pod.Spec.NodeName,
+				PodIP:          pod.Status.PodIP,
+				ControllerName: ctrlName,
+				ControllerKind: ctrlKind,
+				ContName:       container.Name,
+				Image:          container.Image,
+				Env:            env,
+			}
+		}
+
+		if len(container.Ports) == 0 {
+			tgt := newTarget()
+			tgt.tuid = podTUID(pod, container)
+			tgt.hash, _ = hashstructure.Hash(tgt, nil)
+			targets = append(targets, tgt)
+			continue
+		}
+
+		for _, port := range container.Ports {
+			tgt := newTarget()
+			tgt.Port = strconv.FormatInt(int64(port.ContainerPort), 10)
+			tgt.PortName = port.Name
+			tgt.PortProtocol = string(port.Protocol)
+			tgt.Address = net.JoinHostPort(tgt.PodIP, tgt.Port)
+			tgt.tuid = podTUIDWithPort(pod, container, port)
+			tgt.hash, _ = hashstructure.Hash(tgt, nil)
+			targets = append(targets, tgt)
+		}
+	}
+	return targets
+}
+
+func controllerRef(pod *corev1.Pod) (name, kind string) {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return ref.Name, ref.Kind
+		}
+	}
+	return "", ""
+}
+
+func containerEnv(container corev1.Container) map[string]string {
+	if len(container.Env) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(container.Env))
+	for _, e := range container.Env {
+		// values from ConfigMaps and Secrets (ValueFrom) are not resolved.
+		if e.ValueFrom == nil {
+			env[e.Name] = e.Value
+		}
+	}
+	return env
+}
+
+func podTUID(pod *corev1.Pod, container corev1.Container) string {
+	return fmt.Sprintf("%s_%s_%s", pod.Namespace, pod.Name, container.Name)
+}
+
+func podTUIDWithPort(pod *corev1.Pod, container corev1.Container, port corev1.ContainerPort) string {
+	return fmt.Sprintf("%s_%s_%s_%s_%d", pod.Namespace, pod.Name, container.Name, strings.ToLower(string(port.Protocol)), port.ContainerPort)
+}
+
+func podSource(namespace, name string) string {
+	return "k8s/pod/" + namespace + "/" + name
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/ilyam8/hashstructure"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type (
+	serviceTargetGroup struct {
+		source  string
+		targets []model.Target
+	}
+	// serviceTarget is a single service port.
+	// Its exported fields are available in the classify and compose templates.
+	serviceTarget struct {
+		model.Base `hash:"ignore"`
+		hash       uint64
+		tuid       string
+
+		Address      string
+		Namespace    string
+		Name         string
+		Labels       map[string]string
+		Annotations  map[string]string
+		Port         string
+		PortName     string
+		PortProtocol string
+		ClusterIP    string
+		ExternalName string
+		Type         string
+	}
+)
+
+func (g *serviceTargetGroup) Provider() string        { return "kubernetes" }
+func (g *serviceTargetGroup) Source() string          { return g.source }
+func (g *serviceTargetGroup) Targets() []model.Target { return g.targets }
+
+func (t *serviceTarget) Hash() uint64 { return t.hash }
+func (t *serviceTarget) TUID() string { return t.tuid }
+
+func (t *serviceTarget) JobLabels() map[string]string {
+	return map[string]string{
+		"k8s_namespace":    t.Namespace,
+		"k8s_service_name": t.Name,
+	}
+}
+
+func newServiceDiscoverer(si cache.SharedInformer, d *Discovery) *serviceDiscoverer {
+	if si == nil {
+		panic("nil service shared informer")
+	}
+
+	queue := workqueue.NewNamed("service")
+	si.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { enqueue(queue, obj) },
+		UpdateFunc: func(_, obj interface{}) { enqueue(queue, obj) },
+		DeleteFunc: func(obj interface{}) { enqueue(queue, obj) },
+	})
+
+	return &serviceDiscoverer{
+		Discovery: d,
+		informer:  si,
+		queue:     queue,
+	}
+}
+
+type serviceDiscoverer struct {
+	*Discovery
+	informer cache.SharedInformer
+	queue    *workqueue.Type
+}
+
+func (s *serviceDiscoverer) run(ctx context.Context, in chan<- []model.TargetGroup) {
+	s.Info("service_discoverer is started")
+	defer func() { s.Info("service_discoverer is stopped") }()
+
+	defer s.queue.ShutDown()
+
+	go s.informer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), s.informer.HasSynced) {
+		return
+	}
+
+	go s.runDiscover(ctx, in)
+
+	<-ctx.Done()
+}
+
+func (s *serviceDiscoverer) runDiscover(ctx context.Context, in chan<- []model.TargetGroup) {
+	for {
+		item, shutdown := s.queue.Get()
+		if shutdown {
+			return
+		}
+
+		func() {
+			defer s.queue.Done(item)
+
+			key := item.(string)
+			ns, name, err := cache.SplitMetaNamespaceKey(key)
+			if err != nil {
+				return
+			}
+
+			obj, exists, err := s.informer.GetStore().GetByKey(key)
+			if err != nil {
+				return
+			}
+
+			source := serviceSource(ns, name)
+			if !exists {
+				send(ctx, in, &serviceTargetGroup{source: source})
+				return
+			}
+
+			svc, ok := obj.(*corev1.Service)
+			if !ok {
+				return
+			}
+			send(ctx, in, &serviceTargetGroup{source: source, targets: serviceTargets(svc)})
+		}()
+	}
+}
+
+func serviceTargets(svc *corev1.Service) []model.Target {
+	var targets []model.Target
+	for _, port := range svc.Spec.Ports {
+		portNum := strconv.FormatInt(int64(port.Port), 10)
+		tgt := &serviceTarget{
+			tuid:         fmt.Sprintf("%s_%s_%s_%s", svc.Namespace, svc.Name, strings.ToLower(string(port.Protocol)), portNum),
+			Address:      net.JoinHostPort(svc.Name+"."+svc.Namespace+".svc", portNum),
+			Namespace:    svc.Namespace,
+			Name:         svc.Name,
+			Labels:       svc.Labels,
+			Annotations:  svc.Annotations,
+			Port:         portNum,
+			PortName:     port.Name,
+			PortProtocol: string(port.Protocol),
+			ClusterIP:    svc.Spec.ClusterIP,
+			ExternalName: svc.Spec.ExternalName,
+			Type:         string(svc.Spec.Type),
+		}
+		tgt.hash, _ = hashstructure.Hash(tgt, nil)
+		targets = append(targets, tgt)
+	}
+	return targets
+}
+
+func serviceSource(namespace, name string) string {
+	return "k8s/service/" + namespace + "/" + name
+}
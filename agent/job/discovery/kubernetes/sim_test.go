@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type discoverySim struct {
+	discovery      *Discovery
+	runAfterSync   func(ctx context.Context)
+	expectedGroups []model.TargetGroup
+}
+
+func (sim discoverySim) run(t *testing.T) {
+	t.Helper()
+	require.NotNil(t, sim.discovery)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	in, out := make(chan []model.TargetGroup), make(chan []model.TargetGroup)
+	go sim.collectGroups(t, in, out)
+
+	go sim.discovery.Discover(ctx, in)
+	time.Sleep(time.Millisecond * 250)
+
+	if sim.runAfterSync != nil {
+		sim.runAfterSync(ctx)
+	}
+
+	actual := <-out
+
+	sortGroups(actual)
+	sortGroups(sim.expectedGroups)
+
+	assert.Equal(t, sim.expectedGroups, actual)
+}
+
+func (sim discoverySim) collectGroups(t *testing.T, in, out chan []model.TargetGroup) {
+	timeout := time.Second * 5
+	var groups []model.TargetGroup
+loop:
+	for {
+		select {
+		case updates := <-in:
+			if groups = append(groups, updates...); len(groups) >= len(sim.expectedGroups) {
+				break loop
+			}
+		case <-time.After(timeout):
+			t.Logf("discovery %s timed out after %s, got %d groups, expected %d, some events are skipped",
+				sim.discovery, timeout, len(groups), len(sim.expectedGroups))
+			break loop
+		}
+	}
+	out <- groups
+}
+
+func sortGroups(groups []model.TargetGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Source() < groups[j].Source() })
+}
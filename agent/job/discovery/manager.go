@@ -12,6 +12,7 @@ import (
 	"github.com/netdata/go.d.plugin/agent/job/confgroup"
 	"github.com/netdata/go.d.plugin/agent/job/discovery/dummy"
 	"github.com/netdata/go.d.plugin/agent/job/discovery/file"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/pipeline"
 	"github.com/netdata/go.d.plugin/logger"
 )
 
@@ -19,13 +20,14 @@ type Config struct {
 	Registry confgroup.Registry
 	File     file.Config
 	Dummy    dummy.Config
+	SD       []pipeline.Config
 }
 
 func validateConfig(cfg Config) error {
 	if len(cfg.Registry) == 0 {
 		return errors.New("empty config registry")
 	}
-	if len(cfg.File.Read)+len(cfg.File.Watch) == 0 && len(cfg.Dummy.Names) == 0 {
+	if len(cfg.File.Read)+len(cfg.File.Watch) == 0 && len(cfg.Dummy.Names) == 0 && len(cfg.SD) == 0 {
 		return errors.New("discoverers not set")
 	}
 	return nil
@@ -42,6 +44,8 @@ type (
 		sendEvery   time.Duration
 		mux         *sync.RWMutex
 		cache       *cache
+		// number of configs per config group source, the cache is reset after every send.
+		groups map[string]int
 	}
 )
 
@@ -55,6 +59,7 @@ func NewManager(cfg Config) (*Manager, error) {
 		discoverers: make([]discoverer, 0),
 		mux:         &sync.RWMutex{},
 		cache:       newCache(),
+		groups:      make(map[string]int),
 		Logger:      logger.New("discovery", "manager"),
 	}
 	if err := mgr.registerDiscoverers(cfg); err != nil {
@@ -86,6 +91,15 @@ func (m *Manager) registerDiscoverers(cfg Config) error {
 		m.discoverers = append(m.discoverers, d)
 	}
 
+	for _, sdCfg := range cfg.SD {
+		sdCfg.Registry = cfg.Registry
+		p, err := pipeline.New(sdCfg)
+		if err != nil {
+			return err
+		}
+		m.discoverers = append(m.discoverers, p)
+	}
+
 	if len(m.discoverers) == 0 {
 		return errors.New("zero registered discoverers")
 	}
@@ -134,12 +148,37 @@ func (m *Manager) runDiscoverer(ctx context.Context, d discoverer) {
 				defer m.mux.Unlock()
 
 				m.cache.update(groups)
+				m.updateGroupsStats(groups)
 				m.triggerSend()
 			}()
 		}
 	}
 }
 
+func (m *Manager) updateGroupsStats(groups []*confgroup.Group) {
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		if len(group.Configs) == 0 {
+			delete(m.groups, group.Source)
+		} else {
+			m.groups[group.Source] = len(group.Configs)
+		}
+	}
+}
+
+// Stats returns the number of discovered non-empty config groups and the number of configs in them.
+func (m *Manager) Stats() (groups, configs int) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	for _, n := range m.groups {
+		configs += n
+	}
+	return len(m.groups), configs
+}
+
 func (m *Manager) sendLoop(ctx context.Context, in chan<- []*confgroup.Group) {
 	m.mustSend(ctx, in)
 
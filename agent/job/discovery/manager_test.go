@@ -127,6 +127,20 @@ func TestManager_Run(t *testing.T) {
 	}
 }
 
+func TestManager_Stats(t *testing.T) {
+	mgr := prepareManager()
+
+	mgr.updateGroupsStats(prepareMockDiscoverer("test1", 2, 3).groups)
+	groups, configs := mgr.Stats()
+	assert.Equal(t, 2, groups)
+	assert.Equal(t, 6, configs)
+
+	mgr.updateGroupsStats([]*confgroup.Group{{Source: "test1_group_1"}, nil})
+	groups, configs = mgr.Stats()
+	assert.Equal(t, 1, groups)
+	assert.Equal(t, 3, configs)
+}
+
 func prepareMockDiscoverer(source string, groups, configs int) mockDiscoverer {
 	d := mockDiscoverer{}
 
@@ -149,6 +163,7 @@ func prepareManager(discoverers ...discoverer) *Manager {
 		sendEvery:   2 * time.Second,
 		discoverers: discoverers,
 		cache:       newCache(),
+		groups:      make(map[string]int),
 		mux:         &sync.RWMutex{},
 	}
 	return mgr
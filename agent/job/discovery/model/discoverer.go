@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package model
+
+import (
+	"context"
+)
+
+// Discoverer discovers targets and sends their groups to the pipeline.
+// Every sent group is a complete snapshot of its source, a group without targets removes the source.
+type Discoverer interface {
+	Discover(ctx context.Context, in chan<- []TargetGroup)
+}
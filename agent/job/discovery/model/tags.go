@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tags is a set of target tags.
+type Tags map[string]struct{}
+
+func NewTags() Tags {
+	return Tags{}
+}
+
+// ParseTags parses a space separated list of tags.
+// A tag prefixed with '-' removes the tag when merged.
+func ParseTags(line string) (Tags, error) {
+	tags := NewTags()
+	for _, tag := range strings.Fields(line) {
+		if !isTagWordValid(strings.TrimPrefix(tag, "-")) {
+			return nil, fmt.Errorf("tags '%s' contains tag '%s' with forbidden symbol", line, tag)
+		}
+		tags[tag] = struct{}{}
+	}
+	return tags, nil
+}
+
+// Merge adds the tags, tags prefixed with '-' are removed.
+func (t Tags) Merge(tags Tags) {
+	for tag := range tags {
+		if strings.HasPrefix(tag, "-") {
+			delete(t, tag[1:])
+		} else {
+			t[tag] = struct{}{}
+		}
+	}
+}
+
+func (t Tags) Clone() Tags {
+	ts := NewTags()
+	ts.Merge(t)
+	return ts
+}
+
+func (t Tags) String() string {
+	ts := make([]string, 0, len(t))
+	for tag := range t {
+		ts = append(ts, tag)
+	}
+	sort.Strings(ts)
+	return fmt.Sprintf("{%s}", strings.Join(ts, ", "))
+}
+
+func isTagWordValid(word string) bool {
+	// valid:
+	// ^[a-zA-Z][a-zA-Z0-9=_.]*$
+	if len(word) == 0 {
+		return false
+	}
+	for i, r := range word {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '=' || r == '_' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
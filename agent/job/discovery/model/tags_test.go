@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected Tags
+		wantErr  bool
+	}{
+		"empty": {
+			input:    "",
+			expected: Tags{},
+		},
+		"single tag": {
+			input:    "redis",
+			expected: Tags{"redis": {}},
+		},
+		"multiple tags": {
+			input:    "  redis  local -unknown app=db k8s.pod_1 ",
+			expected: Tags{"redis": {}, "local": {}, "-unknown": {}, "app=db": {}, "k8s.pod_1": {}},
+		},
+		"tag starts with digit": {
+			input:   "redis 1local",
+			wantErr: true,
+		},
+		"tag with forbidden symbol": {
+			input:   "redis lo|cal",
+			wantErr: true,
+		},
+		"only minus": {
+			input:   "-",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tags, err := ParseTags(test.input)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, tags)
+			}
+		})
+	}
+}
+
+func TestTags_Merge(t *testing.T) {
+	tags := Tags{"redis": {}, "unknown": {}}
+
+	tags.Merge(Tags{"local": {}, "-unknown": {}, "-missing": {}})
+
+	assert.Equal(t, Tags{"redis": {}, "local": {}}, tags)
+}
+
+func TestTags_Clone(t *testing.T) {
+	tags := Tags{"redis": {}}
+
+	clone := tags.Clone()
+	clone.Merge(Tags{"local": {}})
+
+	assert.Equal(t, Tags{"redis": {}}, tags)
+	assert.Equal(t, Tags{"redis": {}, "local": {}}, clone)
+}
+
+func TestTags_String(t *testing.T) {
+	assert.Equal(t, "{}", Tags{}.String())
+	assert.Equal(t, "{local, redis}", Tags{"redis": {}, "local": {}}.String())
+}
+
+func TestBase_Tags(t *testing.T) {
+	var b Base
+
+	b.Tags().Merge(Tags{"redis": {}})
+
+	assert.Equal(t, Tags{"redis": {}}, b.Tags())
+}
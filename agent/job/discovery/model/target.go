@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package model
+
+// Target is a single discovered entity (a container port, a listening socket, etc.).
+// Its exported fields are available in the classify and compose templates.
+type Target interface {
+	// Hash identifies the target state, it changes when any of the target fields change.
+	Hash() uint64
+	// Tags are attached by the classify stage.
+	Tags() Tags
+	// TUID is the target unique id within its group.
+	TUID() string
+}
+
+// LabeledTarget is a Target that attaches labels (e.g. the container metadata) to the jobs composed from it.
+// The labels don't override the labels set in the composed config.
+type LabeledTarget interface {
+	Target
+	JobLabels() map[string]string
+}
+
+// TargetGroup is a set of targets that share a source.
+type TargetGroup interface {
+	Targets() []Target
+	Provider() string
+	Source() string
+}
+
+// Base implements Tags(), targets embed it.
+type Base struct {
+	tags Tags
+}
+
+func (b *Base) Tags() Tags {
+	if b.tags == nil {
+		b.tags = NewTags()
+	}
+	return b.tags
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package netlisteners
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/logger"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/ilyam8/hashstructure"
+)
+
+const (
+	defaultProcPath     = "/proc"
+	defaultRefreshEvery = time.Second * 30
+)
+
+type Config struct {
+	ProcPath     string       `yaml:"proc_path"`
+	RefreshEvery web.Duration `yaml:"refresh_every"`
+}
+
+type (
+	targetGroup struct {
+		source  string
+		targets []model.Target
+	}
+	// target is a process listening on a TCP port.
+	// Its exported fields are available in the classify and compose templates.
+	target struct {
+		model.Base `hash:"ignore"`
+		hash       uint64
+		tuid       string
+
+		Address   string
+		IPAddress string
+		Port      string
+		Protocol  string
+		PID       string
+		Comm      string
+		Cmdline   string
+	}
+)
+
+func (g *targetGroup) Provider() string        { return "net listeners" }
+func (g *targetGroup) Source() string          { return g.source }
+func (g *targetGroup) Targets() []model.Target { return g.targets }
+
+func (t *target) Hash() uint64 { return t.hash }
+func (t *target) TUID() string { return t.tuid }
+
+type (
+	Discovery struct {
+		*logger.Logger
+		procPath     string
+		refreshEvery time.Duration
+		cache        cache
+	}
+	cache map[string]uint64 // [source]hash
+)
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	d := &Discovery{
+		Logger:       logger.New("discovery", "net listeners"),
+		procPath:     cfg.ProcPath,
+		refreshEvery: cfg.RefreshEvery.Duration,
+		cache:        make(cache),
+	}
+	if d.procPath == "" {
+		d.procPath = defaultProcPath
+	}
+	if d.refreshEvery <= 0 {
+		d.refreshEvery = defaultRefreshEvery
+	}
+	return d, nil
+}
+
+func (d *Discovery) String() string {
+	return "net listeners discovery"
+}
+
+func (d *Discovery) Discover(ctx context.Context, in chan<- []model.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	d.refresh(ctx, in)
+
+	tk := time.NewTicker(d.refreshEvery)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+			d.refresh(ctx, in)
+		}
+	}
+}
+
+func (d *Discovery) refresh(ctx context.Context, in chan<- []model.TargetGroup) {
+	targets, err := d.discoverTargets()
+	if err != nil {
+		d.Warningf("discover listening sockets: %v", err)
+		return
+	}
+
+	var groups []model.TargetGroup
+	seen := make(map[string]bool)
+
+	for _, tgt := range targets {
+		source := targetSource(tgt)
+		seen[source] = true
+
+		if v, ok := d.cache[source]; ok && v == tgt.Hash() {
+			continue
+		}
+		d.cache[source] = tgt.Hash()
+
+		groups = append(groups, &targetGroup{source: source, targets: []model.Target{tgt}})
+	}
+
+	// closed sockets are removed the same way as removed files' groups.
+	for source := range d.cache {
+		if seen[source] {
+			continue
+		}
+		delete(d.cache, source)
+		groups = append(groups, &targetGroup{source: source})
+	}
+
+	send(ctx, in, groups)
+}
+
+func (d *Discovery) discoverTargets() ([]*target, error) {
+	sockets, err := readListenSockets(d.procPath)
+	if err != nil {
+		return nil, err
+	}
+	procs, err := readSocketProcesses(d.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// IPv4 first, a process listening on both families is discovered once.
+	sort.SliceStable(sockets, func(i, j int) bool { return sockets[i].protocol < sockets[j].protocol })
+
+	var targets []*target
+	seen := make(map[string]bool)
+
+	for _, sock := range sockets {
+		proc, ok := procs[sock.inode]
+		if !ok {
+			// the socket owner can't be inspected (not enough permissions) or already exited
+			continue
+		}
+		port := strconv.Itoa(int(sock.port))
+		key := proc.comm + "/" + port
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		tgt := &target{
+			IPAddress: sock.ip.String(),
+			Port:      port,
+			Protocol:  sock.protocol,
+			PID:       strconv.Itoa(proc.pid),
+			Comm:      proc.comm,
+			Cmdline:   proc.cmdline,
+		}
+		tgt.Address = net.JoinHostPort(connectIP(sock.ip), port)
+		tgt.tuid = fmt.Sprintf("%s_%s_%s", tgt.Comm, tgt.Protocol, tgt.Port)
+		tgt.hash, _ = hashstructure.Hash(tgt, nil)
+		targets = append(targets, tgt)
+	}
+	return targets, nil
+}
+
+// connectIP returns the address to use to connect to a socket listening on ip.
+func connectIP(ip net.IP) string {
+	if !ip.IsUnspecified() {
+		return ip.String()
+	}
+	if ip.To4() != nil {
+		return "127.0.0.1"
+	}
+	return "::1"
+}
+
+func targetSource(tgt *target) string {
+	// PID is not a part of the source, a restarted process keeps its jobs.
+	return fmt.Sprintf("netlisteners/%s/%s", tgt.Comm, tgt.Port)
+}
+
+func send(ctx context.Context, in chan<- []model.TargetGroup, groups []model.TargetGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case in <- groups:
+	}
+}
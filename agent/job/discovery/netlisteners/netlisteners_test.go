@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package netlisteners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/ilyam8/hashstructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscovery_Discover(t *testing.T) {
+	dir, cleanup := prepareProcDir(t)
+	defer cleanup()
+
+	d, err := NewDiscovery(Config{
+		ProcPath:     dir,
+		RefreshEvery: web.Duration{Duration: time.Millisecond * 100},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	in := make(chan []model.TargetGroup)
+	go d.Discover(ctx, in)
+
+	groups := <-in
+	sortGroups(groups)
+
+	expected := []model.TargetGroup{
+		prepareGroup(&target{
+			tuid:      "mysqld_tcp_3306",
+			Address:   "127.0.0.1:3306",
+			IPAddress: "0.0.0.0",
+			Port:      "3306",
+			Protocol:  "tcp",
+			PID:       "102",
+			Comm:      "mysqld",
+			Cmdline:   "/usr/sbin/mysqld",
+		}),
+		prepareGroup(&target{
+			tuid:      "nginx_tcp6_80",
+			Address:   "[::1]:80",
+			IPAddress: "::1",
+			Port:      "80",
+			Protocol:  "tcp6",
+			PID:       "103",
+			Comm:      "nginx",
+			Cmdline:   "nginx: master process /usr/sbin/nginx",
+		}),
+		prepareGroup(&target{
+			tuid:      "redis-server_tcp_6379",
+			Address:   "127.0.0.1:6379",
+			IPAddress: "127.0.0.1",
+			Port:      "6379",
+			Protocol:  "tcp",
+			PID:       "101",
+			Comm:      "redis-server",
+			Cmdline:   "/usr/bin/redis-server 127.0.0.1:6379",
+		}),
+	}
+	assert.Equal(t, expected, groups)
+
+	// redis stopped
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "101")))
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the removed group")
+	case groups = <-in:
+	}
+	assert.Equal(t, []model.TargetGroup{&targetGroup{source: "netlisteners/redis-server/6379"}}, groups)
+}
+
+func TestDiscovery_Discover_ProcNotExists(t *testing.T) {
+	d, err := NewDiscovery(Config{ProcPath: "testdata/not_exists"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan []model.TargetGroup, 1)
+	d.refresh(ctx, in)
+
+	assert.Len(t, in, 0)
+	assert.Len(t, d.cache, 0)
+}
+
+func prepareGroup(tgt *target) *targetGroup {
+	tgt.hash, _ = hashstructure.Hash(tgt, nil)
+	return &targetGroup{source: targetSource(tgt), targets: []model.Target{tgt}}
+}
+
+func sortGroups(groups []model.TargetGroup) {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Source() < groups[j].Source() })
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package netlisteners
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const tcpListen = "0A"
+
+type (
+	listenSocket struct {
+		protocol string // tcp or tcp6
+		ip       net.IP
+		port     uint16
+		inode    string
+	}
+	process struct {
+		pid     int
+		comm    string
+		cmdline string
+	}
+)
+
+// readListenSockets parses /proc/net/tcp and /proc/net/tcp6 and returns sockets in the LISTEN state.
+// https://www.kernel.org/doc/Documentation/networking/proc_net_tcp.txt
+func readListenSockets(procPath string) ([]listenSocket, error) {
+	var sockets []listenSocket
+	var found int
+
+	for _, proto := range []string{"tcp", "tcp6"} {
+		socks, err := readListenSocketsFile(filepath.Join(procPath, "net", proto), proto)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found++
+		sockets = append(sockets, socks...)
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no tcp files found in '%s'", filepath.Join(procPath, "net"))
+	}
+	return sockets, nil
+}
+
+func readListenSocketsFile(path, proto string) ([]listenSocket, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var sockets []listenSocket
+	sc := bufio.NewScanner(f)
+	sc.Scan() // header
+
+	for sc.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		parts := strings.Fields(sc.Text())
+		if len(parts) < 10 || parts[3] != tcpListen {
+			continue
+		}
+		ip, port, err := parseHexAddress(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %v", path, err)
+		}
+		sockets = append(sockets, listenSocket{
+			protocol: proto,
+			ip:       ip,
+			port:     port,
+			inode:    parts[9],
+		})
+	}
+	return sockets, sc.Err()
+}
+
+// parseHexAddress parses 'IP:PORT' in the /proc/net/tcp{,6} format.
+// The IP address is a sequence of 32-bit words in host (little endian) byte order, the port is in network order.
+func parseHexAddress(s string) (net.IP, uint16, error) {
+	i := strings.IndexByte(s, ':')
+	if i == -1 {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+
+	ip, err := hex.DecodeString(s[:i])
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+	for w := 0; w < len(ip); w += 4 {
+		ip[w], ip[w+1], ip[w+2], ip[w+3] = ip[w+3], ip[w+2], ip[w+1], ip[w]
+	}
+
+	port, err := strconv.ParseUint(s[i+1:], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+	return ip, uint16(port), nil
+}
+
+// readSocketProcesses maps socket inodes to the processes that own them by reading /proc/<pid>/fd.
+// Processes that can not be inspected (permissions) are skipped.
+func readSocketProcesses(procPath string) (map[string]process, error) {
+	dirs, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make(map[string]process)
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil || !dir.IsDir() {
+			continue
+		}
+
+		fdPath := filepath.Join(procPath, dir.Name(), "fd")
+		fds, err := ioutil.ReadDir(fdPath)
+		if err != nil {
+			continue
+		}
+
+		var proc *process
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			if proc == nil {
+				proc = readProcess(procPath, pid)
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			procs[inode] = *proc
+		}
+	}
+	return procs, nil
+}
+
+func readProcess(procPath string, pid int) *process {
+	proc := &process{pid: pid}
+	dir := filepath.Join(procPath, strconv.Itoa(pid))
+
+	if bs, err := ioutil.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		proc.comm = string(bytes.TrimSpace(bs))
+	}
+	if bs, err := ioutil.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		proc.cmdline = string(bytes.TrimSpace(bytes.ReplaceAll(bs, []byte{0}, []byte{' '})))
+	}
+	return proc
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package netlisteners
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   116        0 21001 1 0000000000000000 100 0 0 10 0
+   1: 00000000:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000   112        0 21002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:0CEA 0100007F:D2F4 01 00000000:00000000 00:00000000 00000000   112        0 21003 1 0000000000000000 20 4 30 10 -1
+`
+	testProcNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0CEA 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   112        0 21004 1 0000000000000000 100 0 0 10 0
+   1: 00000000000000000000000001000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21005 1 0000000000000000 100 0 0 10 0
+`
+)
+
+type testProcess struct {
+	pid     int
+	comm    string
+	cmdline string
+	inodes  []string
+}
+
+var testProcesses = []testProcess{
+	{pid: 101, comm: "redis-server", cmdline: "/usr/bin/redis-server\x00127.0.0.1:6379\x00", inodes: []string{"21001"}},
+	{pid: 102, comm: "mysqld", cmdline: "/usr/sbin/mysqld\x00", inodes: []string{"21002", "21003", "21004"}},
+	{pid: 103, comm: "nginx", cmdline: "nginx: master process /usr/sbin/nginx\x00", inodes: []string{"21005"}},
+}
+
+func prepareProcDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "netdata-go-test-discovery-netlisteners")
+	require.NoError(t, err)
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "net"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "net", "tcp"), []byte(testProcNetTCP), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "net", "tcp6"), []byte(testProcNetTCP6), 0644))
+
+	for _, p := range testProcesses {
+		pidDir := filepath.Join(dir, strconv.Itoa(p.pid))
+		require.NoError(t, os.MkdirAll(filepath.Join(pidDir, "fd"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(pidDir, "comm"), []byte(p.comm+"\n"), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(pidDir, "cmdline"), []byte(p.cmdline), 0644))
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(pidDir, "fd", "0")))
+		for i, inode := range p.inodes {
+			link := filepath.Join(pidDir, "fd", strconv.Itoa(i+3))
+			require.NoError(t, os.Symlink("socket:["+inode+"]", link))
+		}
+	}
+	return dir, cleanup
+}
+
+func TestParseHexAddress(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		wantIP   net.IP
+		wantPort uint16
+		wantErr  bool
+	}{
+		"IPv4 loopback": {
+			input:    "0100007F:18EB",
+			wantIP:   net.IPv4(127, 0, 0, 1),
+			wantPort: 6379,
+		},
+		"IPv4 any": {
+			input:    "00000000:0CEA",
+			wantIP:   net.IPv4zero,
+			wantPort: 3306,
+		},
+		"IPv6 loopback": {
+			input:    "00000000000000000000000001000000:0050",
+			wantIP:   net.IPv6loopback,
+			wantPort: 80,
+		},
+		"IPv6 any": {
+			input:    "00000000000000000000000000000000:0CEA",
+			wantIP:   net.IPv6unspecified,
+			wantPort: 3306,
+		},
+		"no port": {
+			input:   "0100007F",
+			wantErr: true,
+		},
+		"bad ip length": {
+			input:   "01007F:0050",
+			wantErr: true,
+		},
+		"bad port": {
+			input:   "0100007F:XYZ",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ip, port, err := parseHexAddress(test.input)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, test.wantIP.Equal(ip), "expected %s, got %s", test.wantIP, ip)
+				assert.Equal(t, test.wantPort, port)
+			}
+		})
+	}
+}
+
+func TestReadListenSockets(t *testing.T) {
+	dir, cleanup := prepareProcDir(t)
+	defer cleanup()
+
+	sockets, err := readListenSockets(dir)
+	require.NoError(t, err)
+
+	var inodes []string
+	for _, sock := range sockets {
+		inodes = append(inodes, sock.inode)
+	}
+	assert.Equal(t, []string{"21001", "21002", "21004", "21005"}, inodes)
+}
+
+func TestReadListenSockets_NoFiles(t *testing.T) {
+	_, err := readListenSockets("testdata/not_exists")
+
+	assert.Error(t, err)
+}
+
+func TestReadSocketProcesses(t *testing.T) {
+	dir, cleanup := prepareProcDir(t)
+	defer cleanup()
+
+	procs, err := readSocketProcesses(dir)
+	require.NoError(t, err)
+
+	assert.Len(t, procs, 5)
+	assert.Equal(t, process{pid: 101, comm: "redis-server", cmdline: "/usr/bin/redis-server 127.0.0.1:6379"}, procs["21001"])
+	assert.Equal(t, "mysqld", procs["21004"].comm)
+	assert.Equal(t, "nginx: master process /usr/sbin/nginx", procs["21005"].cmdline)
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+type (
+	targetClassificator struct {
+		*logger.Logger
+		rules []*classifyRule
+	}
+	classifyRule struct {
+		name  string
+		sr    selector
+		tags  model.Tags
+		match []*classifyRuleMatch
+	}
+	classifyRuleMatch struct {
+		tags model.Tags
+		expr *template.Template
+	}
+)
+
+func newTargetClassificator(cfg []ClassifyRuleConfig) (*targetClassificator, error) {
+	c := &targetClassificator{}
+
+	for i, ruleCfg := range cfg {
+		name := ruleCfg.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i+1)
+		}
+		rule, err := newClassifyRule(name, ruleCfg)
+		if err != nil {
+			return nil, fmt.Errorf("classify rule '%s': %v", name, err)
+		}
+		c.rules = append(c.rules, rule)
+	}
+	return c, nil
+}
+
+func newClassifyRule(name string, cfg ClassifyRuleConfig) (*classifyRule, error) {
+	rule := &classifyRule{name: name}
+
+	var err error
+	if rule.sr, err = parseSelector(cfg.Selector); err != nil {
+		return nil, err
+	}
+	if rule.tags, err = model.ParseTags(cfg.Tags); err != nil {
+		return nil, err
+	}
+
+	for i, matchCfg := range cfg.Match {
+		m := &classifyRuleMatch{}
+		if m.tags, err = model.ParseTags(matchCfg.Tags); err != nil {
+			return nil, fmt.Errorf("match[%d]: %v", i+1, err)
+		}
+		if m.expr, err = newTemplate(fmt.Sprintf("%s/match[%d]", name, i+1), matchCfg.Expr); err != nil {
+			return nil, fmt.Errorf("match[%d]: %v", i+1, err)
+		}
+		rule.match = append(rule.match, m)
+	}
+	return rule, nil
+}
+
+// classify returns the tags to attach to the target. Rules are applied in order,
+// a rule selector sees the tags attached by the previous rules.
+func (c *targetClassificator) classify(tgt model.Target) model.Tags {
+	tgtTags := tgt.Tags().Clone()
+	tags := model.NewTags()
+	var buf bytes.Buffer
+
+	for _, rule := range c.rules {
+		if !rule.sr.matches(tgtTags) {
+			continue
+		}
+
+		matched := len(rule.match) == 0
+		for i, m := range rule.match {
+			buf.Reset()
+			if err := m.expr.Execute(&buf, tgt); err != nil {
+				c.Warningf("classify rule '%s' match[%d] (%s): %v", rule.name, i+1, tgt.TUID(), err)
+				continue
+			}
+			if strings.TrimSpace(buf.String()) != "true" {
+				continue
+			}
+			matched = true
+			tags.Merge(m.tags)
+			tgtTags.Merge(m.tags)
+		}
+		if matched {
+			tags.Merge(rule.tags)
+			tgtTags.Merge(rule.tags)
+		}
+	}
+	return tags
+}
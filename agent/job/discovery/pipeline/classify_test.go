@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const testClassifyConfig = `
+- name: applications
+  tags: app
+  match:
+    - tags: redis
+      expr: '{{ match "* redis*" .Image }}'
+    - tags: nginx
+      expr: '{{ eq .Port "80" }}'
+- name: default port
+  selector: redis
+  match:
+    - tags: default_port
+      expr: '{{ eq .Port "6379" }}'
+- name: not applications
+  selector: '!app'
+  tags: unknown
+- name: remove
+  selector: unknown
+  tags: -app
+`
+
+func TestNewTargetClassificator(t *testing.T) {
+	tests := map[string]struct {
+		cfg     string
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: testClassifyConfig,
+		},
+		"invalid selector": {
+			cfg:     "- selector: 'redis|'",
+			wantErr: true,
+		},
+		"invalid tags": {
+			cfg:     "- tags: 'redis|'",
+			wantErr: true,
+		},
+		"invalid match tags": {
+			cfg:     "- match:\n    - tags: '1redis'\n      expr: 'true'",
+			wantErr: true,
+		},
+		"invalid match expr": {
+			cfg:     "- match:\n    - tags: redis\n      expr: '{{ .Image'",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg []ClassifyRuleConfig
+			require.NoError(t, yaml.Unmarshal([]byte(test.cfg), &cfg))
+
+			clr, err := newTargetClassificator(cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, clr)
+			}
+		})
+	}
+}
+
+func TestTargetClassificator_classify(t *testing.T) {
+	tests := map[string]struct {
+		target   model.Target
+		expected model.Tags
+	}{
+		"redis, default port": {
+			target:   newMockTarget("redis", "redis:7", "6379"),
+			expected: model.Tags{"app": {}, "redis": {}, "default_port": {}},
+		},
+		"redis, non default port": {
+			target:   newMockTarget("redis", "redis:7", "6380"),
+			expected: model.Tags{"app": {}, "redis": {}},
+		},
+		"nginx": {
+			target:   newMockTarget("web", "nginx:1.23", "80"),
+			expected: model.Tags{"app": {}, "nginx": {}},
+		},
+		"unknown": {
+			target:   newMockTarget("busybox", "busybox", "1234"),
+			expected: model.Tags{"unknown": {}},
+		},
+	}
+
+	var cfg []ClassifyRuleConfig
+	require.NoError(t, yaml.Unmarshal([]byte(testClassifyConfig), &cfg))
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clr, err := newTargetClassificator(cfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, clr.classify(test.target))
+			assert.Empty(t, test.target.Tags(), "classify must not change the target tags")
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/logger"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	configComposer struct {
+		*logger.Logger
+		rules []*composeRule
+	}
+	composeRule struct {
+		name string
+		sr   selector
+		conf []*composeRuleConf
+	}
+	composeRuleConf struct {
+		sr   selector
+		tmpl *template.Template
+	}
+)
+
+func newConfigComposer(cfg []ComposeRuleConfig) (*configComposer, error) {
+	c := &configComposer{}
+
+	for i, ruleCfg := range cfg {
+		name := ruleCfg.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i+1)
+		}
+		rule, err := newComposeRule(name, ruleCfg)
+		if err != nil {
+			return nil, fmt.Errorf("compose rule '%s': %v", name, err)
+		}
+		c.rules = append(c.rules, rule)
+	}
+	return c, nil
+}
+
+func newComposeRule(name string, cfg ComposeRuleConfig) (*composeRule, error) {
+	rule := &composeRule{name: name}
+
+	var err error
+	if rule.sr, err = parseSelector(cfg.Selector); err != nil {
+		return nil, err
+	}
+
+	for i, confCfg := range cfg.Config {
+		conf := &composeRuleConf{}
+		if conf.sr, err = parseSelector(confCfg.Selector); err != nil {
+			return nil, fmt.Errorf("config[%d]: %v", i+1, err)
+		}
+		if conf.tmpl, err = newTemplate(fmt.Sprintf("%s/config[%d]", name, i+1), confCfg.Template); err != nil {
+			return nil, fmt.Errorf("config[%d]: %v", i+1, err)
+		}
+		rule.conf = append(rule.conf, conf)
+	}
+	return rule, nil
+}
+
+// compose renders job configs for the target using every rule (and rule config) the target tags match.
+func (c *configComposer) compose(tgt model.Target) []confgroup.Config {
+	var configs []confgroup.Config
+	var buf bytes.Buffer
+
+	for _, rule := range c.rules {
+		if !rule.sr.matches(tgt.Tags()) {
+			continue
+		}
+		for i, conf := range rule.conf {
+			if !conf.sr.matches(tgt.Tags()) {
+				continue
+			}
+
+			buf.Reset()
+			if err := conf.tmpl.Execute(&buf, tgt); err != nil {
+				c.Warningf("compose rule '%s' config[%d] (%s): %v", rule.name, i+1, tgt.TUID(), err)
+				continue
+			}
+
+			cfgs, err := parseComposed(buf.Bytes())
+			if err != nil {
+				c.Warningf("compose rule '%s' config[%d] (%s): %v", rule.name, i+1, tgt.TUID(), err)
+				continue
+			}
+			configs = append(configs, cfgs...)
+		}
+	}
+	return configs
+}
+
+func parseComposed(bs []byte) ([]confgroup.Config, error) {
+	var cfgs []confgroup.Config
+	if err := yaml.Unmarshal(bs, &cfgs); err == nil {
+		return cfgs, nil
+	}
+
+	var cfg confgroup.Config
+	if err := yaml.Unmarshal(bs, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	return []confgroup.Config{cfg}, nil
+}
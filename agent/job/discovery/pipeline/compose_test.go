@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const testComposeConfig = `
+- name: applications
+  selector: app
+  config:
+    - selector: redis
+      template: |
+        module: redis
+        name: {{.Name}}_{{.Port}}
+        address: redis://@{{.Address}}
+    - selector: nginx
+      template: |
+        - module: nginx
+          name: {{.Name}}
+          url: http://{{.Address}}/stub_status
+        - module: web_log
+          name: {{.Name}}
+    - selector: broken
+      template: |
+        module: [broken
+- name: any
+  config:
+    - selector: debug
+      template: |
+        module: debug
+        name: {{.Name}}
+`
+
+func TestNewConfigComposer(t *testing.T) {
+	tests := map[string]struct {
+		cfg     string
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: testComposeConfig,
+		},
+		"invalid selector": {
+			cfg:     "- selector: 'redis|'\n  config:\n    - template: 'module: redis'",
+			wantErr: true,
+		},
+		"invalid config selector": {
+			cfg:     "- config:\n    - selector: '!'\n      template: 'module: redis'",
+			wantErr: true,
+		},
+		"invalid template": {
+			cfg:     "- config:\n    - template: '{{ .Name'",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg []ComposeRuleConfig
+			require.NoError(t, yaml.Unmarshal([]byte(test.cfg), &cfg))
+
+			cmr, err := newConfigComposer(cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, cmr)
+			}
+		})
+	}
+}
+
+func TestConfigComposer_compose(t *testing.T) {
+	tests := map[string]struct {
+		target   model.Target
+		tags     model.Tags
+		expected []confgroup.Config
+	}{
+		"single config": {
+			target: newMockTarget("redis", "redis:7", "6379"),
+			tags:   model.Tags{"app": {}, "redis": {}},
+			expected: []confgroup.Config{
+				{"module": "redis", "name": "redis_6379", "address": "redis://@10.0.0.1:6379"},
+			},
+		},
+		"list of configs": {
+			target: newMockTarget("web", "nginx:1.23", "80"),
+			tags:   model.Tags{"app": {}, "nginx": {}},
+			expected: []confgroup.Config{
+				{"module": "nginx", "name": "web", "url": "http://10.0.0.1:80/stub_status"},
+				{"module": "web_log", "name": "web"},
+			},
+		},
+		"multiple rules": {
+			target: newMockTarget("redis", "redis:7", "6379"),
+			tags:   model.Tags{"app": {}, "redis": {}, "debug": {}},
+			expected: []confgroup.Config{
+				{"module": "redis", "name": "redis_6379", "address": "redis://@10.0.0.1:6379"},
+				{"module": "debug", "name": "redis"},
+			},
+		},
+		"rule selector not matched": {
+			target: newMockTarget("redis", "redis:7", "6379"),
+			tags:   model.Tags{"redis": {}},
+		},
+		"broken template output": {
+			target: newMockTarget("redis", "redis:7", "6379"),
+			tags:   model.Tags{"app": {}, "broken": {}},
+		},
+	}
+
+	var cfg []ComposeRuleConfig
+	require.NoError(t, yaml.Unmarshal([]byte(testComposeConfig), &cfg))
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmr, err := newConfigComposer(cfg)
+			require.NoError(t, err)
+
+			test.target.Tags().Merge(test.tags)
+
+			assert.Equal(t, test.expected, cmr.compose(test.target))
+		})
+	}
+}
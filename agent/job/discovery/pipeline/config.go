@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/docker"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/kubernetes"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/netlisteners"
+)
+
+// Config is a service discovery pipeline config: discover targets, classify them (attach tags)
+// and compose job configs for the tagged targets.
+type Config struct {
+	Registry confgroup.Registry   `yaml:"-"`
+	Name     string               `yaml:"name"`
+	Discover DiscoveryConfig      `yaml:"discover"`
+	Classify []ClassifyRuleConfig `yaml:"classify"`
+	Compose  []ComposeRuleConfig  `yaml:"compose"`
+}
+
+type DiscoveryConfig struct {
+	K8s          []kubernetes.Config   `yaml:"kubernetes"`
+	Docker       []docker.Config       `yaml:"docker"`
+	NetListeners []netlisteners.Config `yaml:"net_listeners"`
+}
+
+// ClassifyRuleConfig attaches tags to the targets that match the selector.
+// 'Tags' are attached if any of the 'Match' expressions is true (or 'Match' is not set).
+type ClassifyRuleConfig struct {
+	Name     string `yaml:"name"`
+	Selector string `yaml:"selector"`
+	Tags     string `yaml:"tags"`
+	Match    []struct {
+		Tags string `yaml:"tags"`
+		// Expr is a Go text/template evaluated against the target, it must render to "true" to match.
+		Expr string `yaml:"expr"`
+	} `yaml:"match"`
+}
+
+// ComposeRuleConfig renders job configs for the targets that match the selector.
+type ComposeRuleConfig struct {
+	Name     string `yaml:"name"`
+	Selector string `yaml:"selector"`
+	Config   []struct {
+		Selector string `yaml:"selector"`
+		// Template is a Go text/template evaluated against the target,
+		// it must render to a YAML job config or a list of job configs.
+		Template string `yaml:"template"`
+	} `yaml:"config"`
+}
+
+func validateConfig(cfg Config) error {
+	if len(cfg.Registry) == 0 {
+		return errors.New("empty config registry")
+	}
+	if cfg.Name == "" {
+		return errors.New("name not set")
+	}
+	d := cfg.Discover
+	if len(d.K8s)+len(d.Docker)+len(d.NetListeners) == 0 {
+		return errors.New("discoverers not set")
+	}
+	if len(cfg.Classify) == 0 {
+		return errors.New("classify rules not set")
+	}
+	if len(cfg.Compose) == 0 {
+		return errors.New("compose rules not set")
+	}
+	for i, rule := range cfg.Classify {
+		for j, m := range rule.Match {
+			if m.Expr == "" {
+				return fmt.Errorf("classify rule '%s'[%d] match[%d]: expr not set", rule.Name, i+1, j+1)
+			}
+		}
+	}
+	for i, rule := range cfg.Compose {
+		if len(rule.Config) == 0 {
+			return fmt.Errorf("compose rule '%s'[%d]: config not set", rule.Name, i+1)
+		}
+		for j, conf := range rule.Config {
+			if conf.Template == "" {
+				return fmt.Errorf("compose rule '%s'[%d] config[%d]: template not set", rule.Name, i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"text/template"
+
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+)
+
+func newTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(funcMap).Option("missingkey=zero").Parse(text)
+}
+
+var funcMap = template.FuncMap{
+	// match reports whether the value matches the pkg/matcher expression, e.g. `{{ match "* redis*" .Image }}`.
+	"match": func(expr, value string) (bool, error) {
+		m, err := matcher.Parse(expr)
+		if err != nil {
+			return false, err
+		}
+		return m.MatchString(value), nil
+	},
+	"hasKey": func(m map[string]string, key string) bool {
+		_, ok := m[key]
+		return ok
+	},
+}
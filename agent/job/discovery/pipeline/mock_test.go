@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/ilyam8/hashstructure"
+)
+
+type (
+	mockTargetGroup struct {
+		source  string
+		targets []model.Target
+	}
+	mockTarget struct {
+		model.Base `hash:"ignore"`
+		hash       uint64
+		tuid       string
+
+		Name    string
+		Image   string
+		Address string
+		Port    string
+		Labels  map[string]string
+	}
+)
+
+func (g *mockTargetGroup) Provider() string        { return "mock" }
+func (g *mockTargetGroup) Source() string          { return g.source }
+func (g *mockTargetGroup) Targets() []model.Target { return g.targets }
+
+func (t *mockTarget) Hash() uint64 { return t.hash }
+func (t *mockTarget) TUID() string { return t.tuid }
+
+func newMockTarget(name, image, port string) *mockTarget {
+	tgt := &mockTarget{
+		tuid:    name + "_" + port,
+		Name:    name,
+		Image:   image,
+		Address: "10.0.0.1:" + port,
+		Port:    port,
+	}
+	tgt.hash, _ = hashstructure.Hash(tgt, nil)
+	return tgt
+}
+
+func newMockGroup(source string, targets ...model.Target) *mockTargetGroup {
+	return &mockTargetGroup{source: source, targets: targets}
+}
+
+type mockDiscoverer struct {
+	updates [][]model.TargetGroup
+}
+
+func (d *mockDiscoverer) Discover(ctx context.Context, in chan<- []model.TargetGroup) {
+	for _, tggs := range d.updates {
+		select {
+		case <-ctx.Done():
+			return
+		case in <- tggs:
+		}
+	}
+	<-ctx.Done()
+}
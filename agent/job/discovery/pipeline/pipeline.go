@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/docker"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/kubernetes"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/netlisteners"
+	"github.com/netdata/go.d.plugin/logger"
+
+	"github.com/ilyam8/hashstructure"
+)
+
+type (
+	Pipeline struct {
+		*logger.Logger
+		name        string
+		reg         confgroup.Registry
+		discoverers []model.Discoverer
+		clr         classificator
+		cmr         composer
+		cache       cache
+	}
+	classificator interface {
+		classify(model.Target) model.Tags
+	}
+	composer interface {
+		compose(model.Target) []confgroup.Config
+	}
+	cache map[string]uint64 // [source]hash
+)
+
+func New(cfg Config) (*Pipeline, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("sd pipeline '%s' config validation: %v", cfg.Name, err)
+	}
+
+	p := &Pipeline{
+		Logger: logger.New("discovery", "sd pipeline "+cfg.Name),
+		name:   cfg.Name,
+		reg:    cfg.Registry,
+		cache:  make(cache),
+	}
+
+	clr, err := newTargetClassificator(cfg.Classify)
+	if err != nil {
+		return nil, fmt.Errorf("sd pipeline '%s': %v", cfg.Name, err)
+	}
+	clr.Logger = p.Logger
+	p.clr = clr
+
+	cmr, err := newConfigComposer(cfg.Compose)
+	if err != nil {
+		return nil, fmt.Errorf("sd pipeline '%s': %v", cfg.Name, err)
+	}
+	cmr.Logger = p.Logger
+	p.cmr = cmr
+
+	if err := p.registerDiscoverers(cfg.Discover); err != nil {
+		return nil, fmt.Errorf("sd pipeline '%s': %v", cfg.Name, err)
+	}
+	return p, nil
+}
+
+func (p *Pipeline) registerDiscoverers(cfg DiscoveryConfig) error {
+	for _, k8sCfg := range cfg.K8s {
+		d, err := kubernetes.NewDiscovery(k8sCfg)
+		if err != nil {
+			return err
+		}
+		p.discoverers = append(p.discoverers, d)
+	}
+	for _, dockerCfg := range cfg.Docker {
+		d, err := docker.NewDiscovery(dockerCfg)
+		if err != nil {
+			return err
+		}
+		p.discoverers = append(p.discoverers, d)
+	}
+	for _, nlCfg := range cfg.NetListeners {
+		d, err := netlisteners.NewDiscovery(nlCfg)
+		if err != nil {
+			return err
+		}
+		p.discoverers = append(p.discoverers, d)
+	}
+	return nil
+}
+
+func (p *Pipeline) String() string {
+	return fmt.Sprintf("sd pipeline '%s': %v", p.name, p.discoverers)
+}
+
+func (p *Pipeline) Run(ctx context.Context, in chan<- []*confgroup.Group) {
+	p.Info("instance is started")
+	defer func() { p.Info("instance is stopped") }()
+
+	updates := make(chan []model.TargetGroup)
+
+	var wg sync.WaitGroup
+	for _, d := range p.discoverers {
+		wg.Add(1)
+		go func(d model.Discoverer) { defer wg.Done(); d.Discover(ctx, updates) }(d)
+	}
+
+	done := make(chan struct{})
+	go func() { defer close(done); wg.Wait() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			return
+		case tggs := <-updates:
+			if groups := p.processGroups(tggs); len(groups) > 0 {
+				select {
+				case <-ctx.Done():
+				case in <- groups:
+				}
+			}
+		}
+	}
+}
+
+func (p *Pipeline) processGroups(tggs []model.TargetGroup) []*confgroup.Group {
+	var groups []*confgroup.Group
+	for _, tgg := range tggs {
+		if group := p.processGroup(tgg); group != nil {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// processGroup returns nil if the group hasn't changed since the last update.
+func (p *Pipeline) processGroup(tgg model.TargetGroup) *confgroup.Group {
+	source := tgg.Source()
+	targets := tgg.Targets()
+
+	if len(targets) == 0 {
+		if _, ok := p.cache[source]; !ok {
+			return nil
+		}
+		delete(p.cache, source)
+		return &confgroup.Group{Source: source}
+	}
+
+	hash := groupHash(targets)
+	if v, ok := p.cache[source]; ok && v == hash {
+		return nil
+	}
+	p.cache[source] = hash
+
+	group := &confgroup.Group{Source: source}
+	for _, tgt := range targets {
+		tags := p.clr.classify(tgt)
+		if len(tags) == 0 {
+			continue
+		}
+		tgt.Tags().Merge(tags)
+		p.Debugf("target '%s' tags: %s", tgt.TUID(), tgt.Tags())
+
+		for _, cfg := range p.cmr.compose(tgt) {
+			def, ok := p.reg.Lookup(cfg.Module())
+			if !ok || cfg.Module() == "" {
+				p.Warningf("target '%s': unknown module '%s', skipping the config", tgt.TUID(), cfg.Module())
+				continue
+			}
+			if lt, ok := tgt.(model.LabeledTarget); ok {
+				cfg.MergeLabels(lt.JobLabels())
+			}
+			cfg.Apply(def)
+			cfg.SetSource(source)
+			cfg.SetProvider(tgg.Provider())
+			group.Configs = append(group.Configs, cfg)
+		}
+	}
+	return group
+}
+
+func groupHash(targets []model.Target) uint64 {
+	hashes := make([]uint64, 0, len(targets))
+	for _, tgt := range targets {
+		hashes = append(hashes, tgt.Hash())
+	}
+	hash, _ := hashstructure.Hash(hashes, nil)
+	return hash
+}
@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/docker"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/netlisteners"
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const testPipelineConfig = `
+name: test
+discover:
+  net_listeners:
+    - refresh_every: 10s
+classify:
+  - name: applications
+    tags: app
+    match:
+      - tags: redis
+        expr: '{{ match "* redis*" .Image }}'
+      - tags: nginx
+        expr: '{{ match "* nginx*" .Image }}'
+compose:
+  - name: applications
+    selector: app
+    config:
+      - selector: redis
+        template: |
+          module: redis
+          name: {{.Name}}_{{.Port}}
+          address: redis://@{{.Address}}
+      - selector: nginx
+        template: |
+          module: apache
+          name: {{.Name}}
+`
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		prepare func(cfg *Config)
+		wantErr bool
+	}{
+		"valid config": {
+			prepare: func(cfg *Config) {},
+		},
+		"registry not set": {
+			prepare: func(cfg *Config) { cfg.Registry = nil },
+			wantErr: true,
+		},
+		"name not set": {
+			prepare: func(cfg *Config) { cfg.Name = "" },
+			wantErr: true,
+		},
+		"discoverers not set": {
+			prepare: func(cfg *Config) { cfg.Discover = DiscoveryConfig{} },
+			wantErr: true,
+		},
+		"classify not set": {
+			prepare: func(cfg *Config) { cfg.Classify = nil },
+			wantErr: true,
+		},
+		"compose not set": {
+			prepare: func(cfg *Config) { cfg.Compose = nil },
+			wantErr: true,
+		},
+		"classify match expr not set": {
+			prepare: func(cfg *Config) { cfg.Classify[0].Match[0].Expr = "" },
+			wantErr: true,
+		},
+		"compose config template not set": {
+			prepare: func(cfg *Config) { cfg.Compose[0].Config[0].Template = "" },
+			wantErr: true,
+		},
+		"compose config not set": {
+			prepare: func(cfg *Config) { cfg.Compose[0].Config = nil },
+			wantErr: true,
+		},
+		"invalid discoverer config": {
+			prepare: func(cfg *Config) { cfg.Discover.Docker = []docker.Config{{Address: "ssh://127.0.0.1"}} },
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := prepareConfig(t)
+			test.prepare(&cfg)
+
+			p, err := New(cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, p)
+				assert.Len(t, p.discoverers, 1)
+				assert.IsType(t, &netlisteners.Discovery{}, p.discoverers[0])
+			}
+		})
+	}
+}
+
+func TestPipeline_Run(t *testing.T) {
+	redis := newMockTarget("redis", "redis:7", "6379")
+	nginx := newMockTarget("web", "nginx:1.23", "80")
+	busybox := newMockTarget("busybox", "busybox", "1234")
+
+	tests := map[string]struct {
+		updates  [][]model.TargetGroup
+		expected []*confgroup.Group
+	}{
+		"new groups": {
+			updates: [][]model.TargetGroup{
+				{
+					newMockGroup("mock/redis", redis),
+					newMockGroup("mock/busybox", busybox),
+				},
+			},
+			expected: []*confgroup.Group{
+				{
+					Source: "mock/redis",
+					Configs: []confgroup.Config{
+						prepareJobConfig("redis", "redis_6379", "address", "redis://@10.0.0.1:6379", "mock/redis"),
+					},
+				},
+				{
+					Source: "mock/busybox",
+				},
+			},
+		},
+		"unchanged group is not sent": {
+			updates: [][]model.TargetGroup{
+				{newMockGroup("mock/redis", redis)},
+				{newMockGroup("mock/redis", newMockTarget("redis", "redis:7", "6379"))},
+				{newMockGroup("mock/busybox", busybox)},
+			},
+			expected: []*confgroup.Group{
+				{
+					Source: "mock/redis",
+					Configs: []confgroup.Config{
+						prepareJobConfig("redis", "redis_6379", "address", "redis://@10.0.0.1:6379", "mock/redis"),
+					},
+				},
+				{
+					Source: "mock/busybox",
+				},
+			},
+		},
+		"removed group": {
+			updates: [][]model.TargetGroup{
+				{newMockGroup("mock/redis", redis)},
+				{newMockGroup("mock/unknown")},
+				{newMockGroup("mock/redis")},
+			},
+			expected: []*confgroup.Group{
+				{
+					Source: "mock/redis",
+					Configs: []confgroup.Config{
+						prepareJobConfig("redis", "redis_6379", "address", "redis://@10.0.0.1:6379", "mock/redis"),
+					},
+				},
+				{
+					Source: "mock/redis",
+				},
+			},
+		},
+		"unknown module config is skipped": {
+			updates: [][]model.TargetGroup{
+				{newMockGroup("mock/web", nginx)},
+			},
+			expected: []*confgroup.Group{
+				{
+					Source: "mock/web",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(prepareConfig(t))
+			require.NoError(t, err)
+			p.discoverers = []model.Discoverer{&mockDiscoverer{updates: copyUpdates(test.updates)}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			in := make(chan []*confgroup.Group)
+			done := make(chan struct{})
+			go func() { defer close(done); p.Run(ctx, in) }()
+
+			var groups []*confgroup.Group
+			for len(groups) < len(test.expected) {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("timed out, got %d groups, expected %d", len(groups), len(test.expected))
+				case updates := <-in:
+					groups = append(groups, updates...)
+				}
+			}
+
+			select {
+			case updates := <-in:
+				t.Errorf("unexpected groups: %v", updates)
+			case <-time.After(time.Millisecond * 100):
+			}
+
+			cancel()
+			<-done
+
+			assert.Equal(t, test.expected, groups)
+		})
+	}
+}
+
+func prepareConfig(t *testing.T) Config {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(testPipelineConfig), &cfg))
+	cfg.Registry = confgroup.Registry{"redis": {}, "nginx": {}}
+	return cfg
+}
+
+func prepareJobConfig(moduleName, name, key, value, source string) confgroup.Config {
+	return confgroup.Config{
+		"module":              moduleName,
+		"name":                name,
+		key:                   value,
+		"update_every":        module.UpdateEvery,
+		"autodetection_retry": module.AutoDetectionRetry,
+		"priority":            module.Priority,
+		"__source__":          source,
+		"__provider__":        "mock",
+	}
+}
+
+// copyUpdates makes every test case use its own targets, the pipeline attaches tags to them.
+func copyUpdates(updates [][]model.TargetGroup) [][]model.TargetGroup {
+	var res [][]model.TargetGroup
+	for _, tggs := range updates {
+		var cp []model.TargetGroup
+		for _, tgg := range tggs {
+			var targets []model.Target
+			for _, tgt := range tgg.Targets() {
+				mt := *tgt.(*mockTarget)
+				mt.Base = model.Base{}
+				targets = append(targets, &mt)
+			}
+			cp = append(cp, newMockGroup(tgg.Source(), targets...))
+		}
+		res = append(res, cp)
+	}
+	return res
+}
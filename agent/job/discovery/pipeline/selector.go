@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+)
+
+// selector matches target tags.
+//
+// Syntax: space separated terms, all of them must match (AND).
+// A term is a list of tags separated by '|', any of them must match (OR).
+// A tag prefixed with '!' matches if the target doesn't have the tag, '*' matches any tags.
+// An empty selector matches any tags.
+//
+// Example: 'redis|memcached !k8s_service'.
+type selector interface {
+	matches(model.Tags) bool
+}
+
+type (
+	exactSelector string
+	trueSelector  struct{}
+	negSelector   struct{ selector }
+	orSelector    []selector
+	andSelector   []selector
+)
+
+func (s exactSelector) matches(tags model.Tags) bool { _, ok := tags[string(s)]; return ok }
+func (trueSelector) matches(model.Tags) bool         { return true }
+func (s negSelector) matches(tags model.Tags) bool   { return !s.selector.matches(tags) }
+
+func (s orSelector) matches(tags model.Tags) bool {
+	for _, sr := range s {
+		if sr.matches(tags) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s andSelector) matches(tags model.Tags) bool {
+	for _, sr := range s {
+		if !sr.matches(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSelector(line string) (selector, error) {
+	terms := strings.Fields(line)
+	if len(terms) == 0 {
+		return trueSelector{}, nil
+	}
+
+	var and andSelector
+	for _, term := range terms {
+		var or orSelector
+		for _, word := range strings.Split(term, "|") {
+			sr, err := parseSelectorWord(word)
+			if err != nil {
+				return nil, fmt.Errorf("selector '%s': %v", line, err)
+			}
+			or = append(or, sr)
+		}
+		and = append(and, or)
+	}
+	return and, nil
+}
+
+func parseSelectorWord(word string) (selector, error) {
+	neg := strings.HasPrefix(word, "!")
+	word = strings.TrimPrefix(word, "!")
+
+	var sr selector
+	switch {
+	case word == "*":
+		sr = trueSelector{}
+	case word == "":
+		return nil, fmt.Errorf("empty tag")
+	default:
+		if _, err := model.ParseTags(word); err != nil || strings.HasPrefix(word, "-") {
+			return nil, fmt.Errorf("invalid tag '%s'", word)
+		}
+		sr = exactSelector(word)
+	}
+	if neg {
+		return negSelector{sr}, nil
+	}
+	return sr, nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job/discovery/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		wantErr bool
+	}{
+		"empty":                {input: ""},
+		"single tag":           {input: "redis"},
+		"and":                  {input: "redis local"},
+		"or":                   {input: "redis|memcached"},
+		"negation":             {input: "!redis"},
+		"any":                  {input: "*"},
+		"complex":              {input: "redis|memcached !k8s local|*"},
+		"empty or alternative": {input: "redis|", wantErr: true},
+		"only negation":        {input: "!", wantErr: true},
+		"forbidden symbol":     {input: "re$dis", wantErr: true},
+		"tag removal":          {input: "-redis", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr, err := parseSelector(test.input)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, sr)
+			}
+		})
+	}
+}
+
+func TestSelector_matches(t *testing.T) {
+	tests := map[string]struct {
+		selector string
+		tags     model.Tags
+		expected bool
+	}{
+		"empty selector matches any tags": {
+			selector: "",
+			tags:     model.Tags{},
+			expected: true,
+		},
+		"single tag, match": {
+			selector: "redis",
+			tags:     model.Tags{"redis": {}, "local": {}},
+			expected: true,
+		},
+		"single tag, no match": {
+			selector: "redis",
+			tags:     model.Tags{"local": {}},
+		},
+		"and, match": {
+			selector: "redis local",
+			tags:     model.Tags{"redis": {}, "local": {}},
+			expected: true,
+		},
+		"and, no match": {
+			selector: "redis local",
+			tags:     model.Tags{"redis": {}},
+		},
+		"or, match": {
+			selector: "redis|memcached",
+			tags:     model.Tags{"memcached": {}},
+			expected: true,
+		},
+		"or, no match": {
+			selector: "redis|memcached",
+			tags:     model.Tags{"nginx": {}},
+		},
+		"negation, match": {
+			selector: "!redis",
+			tags:     model.Tags{"nginx": {}},
+			expected: true,
+		},
+		"negation, no match": {
+			selector: "!redis",
+			tags:     model.Tags{"redis": {}},
+		},
+		"any": {
+			selector: "*",
+			tags:     model.Tags{"redis": {}},
+			expected: true,
+		},
+		"complex, match": {
+			selector: "redis|memcached !k8s",
+			tags:     model.Tags{"memcached": {}, "docker": {}},
+			expected: true,
+		},
+		"complex, no match": {
+			selector: "redis|memcached !k8s",
+			tags:     model.Tags{"memcached": {}, "k8s": {}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr, err := parseSelector(test.selector)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, sr.matches(test.tags))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package run
+
+// Limiter limits the number of the data collections running at once. It implements module.CollectLimiter.
+type Limiter struct {
+	sem chan struct{}
+}
+
+// NewLimiter creates a Limiter that allows n data collections at once.
+func NewLimiter(n int) *Limiter {
+	return &Limiter{sem: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a data collection slot is available.
+func (l *Limiter) Acquire() { l.sem <- struct{}{} }
+
+// Release frees the data collection slot.
+func (l *Limiter) Release() { <-l.sem }
@@ -4,6 +4,8 @@ package run
 
 import (
 	"context"
+	"hash/fnv"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,13 +14,34 @@ import (
 	"github.com/netdata/go.d.plugin/logger"
 )
 
+// tickSpread is the part of a second the job ticks are spread over,
+// the rest of the second is left for the collections to finish before the next tick.
+const tickSpread = time.Millisecond * 500
+
 type (
 	Manager struct {
+		// SpreadTicks enables the per job tick phase offsets, so the jobs with the same update interval
+		// don't collect data at the same time. The offset is derived from the job full name.
+		SpreadTicks bool
+
 		mux   sync.Mutex
 		queue queue
 		*logger.Logger
 	}
-	queue []jobpkg.Job
+	queue []queueItem
+
+	queueItem struct {
+		job jobpkg.Job
+		// shift is the number of seconds the job clock is behind the ticker clock.
+		shift int
+		// delay is the sub-second tick delay.
+		delay time.Duration
+	}
+
+	// updateEveryJob is a job with a data collection interval, its phase offset is spread over the interval.
+	updateEveryJob interface {
+		UpdateEvery() int
+	}
 )
 
 func NewManager() *Manager {
@@ -41,7 +64,7 @@ func (m *Manager) Run(ctx context.Context) {
 			return
 		case clock := <-tk.C:
 			m.Debugf("tick %d", clock)
-			m.notify(clock)
+			m.notify(ctx, clock)
 		}
 	}
 }
@@ -52,7 +75,11 @@ func (m *Manager) Start(job jobpkg.Job) {
 	defer m.mux.Unlock()
 
 	go job.Start()
-	m.queue.add(job)
+	item := queueItem{job: job}
+	if m.SpreadTicks {
+		item.shift, item.delay = phaseOffset(job)
+	}
+	m.queue.add(item)
 }
 
 // Stop stops a job and removes it from the job queue.
@@ -68,32 +95,63 @@ func (m *Manager) Stop(fullName string) {
 // Cleanup stops all jobs in the queue.
 func (m *Manager) Cleanup() {
 	for _, v := range m.queue {
-		v.Stop()
+		v.job.Stop()
 	}
 	m.queue = m.queue[:0]
 }
 
-func (m *Manager) notify(clock int) {
+// notify ticks the jobs, the queue is sorted by the tick delay.
+// The data is aligned to the collection time by the job (BEGIN microseconds since the last run),
+// so the delayed ticks don't shift the collected values.
+func (m *Manager) notify(ctx context.Context, clock int) {
 	m.mux.Lock()
-	defer m.mux.Unlock()
+	items := append(queue(nil), m.queue...)
+	m.mux.Unlock()
+
+	start := time.Now()
+	for _, v := range items {
+		if d := v.delay - time.Since(start); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+		v.job.Tick(clock - v.shift)
+	}
+}
 
-	for _, v := range m.queue {
-		v.Tick(clock)
+// phaseOffset returns the deterministic job tick offset within the job update interval:
+// the whole seconds and the delay within the second.
+func phaseOffset(job jobpkg.Job) (shift int, delay time.Duration) {
+	every := 1
+	if j, ok := job.(updateEveryJob); ok && j.UpdateEvery() > 1 {
+		every = j.UpdateEvery()
 	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(job.FullName()))
+	slots := uint32(tickSpread / time.Millisecond)
+	n := h.Sum32() % (uint32(every) * slots)
+
+	return int(n / slots), time.Duration(n%slots) * time.Millisecond
 }
 
-func (q *queue) add(job jobpkg.Job) {
-	*q = append(*q, job)
+func (q *queue) add(item queueItem) {
+	*q = append(*q, item)
+	sort.SliceStable(*q, func(i, j int) bool { return (*q)[i].delay < (*q)[j].delay })
 }
 
 func (q *queue) remove(fullName string) jobpkg.Job {
 	for idx, v := range *q {
-		if v.FullName() != fullName {
+		if v.job.FullName() != fullName {
 			continue
 		}
-		j := (*q)[idx]
+		j := (*q)[idx].job
 		copy((*q)[idx:], (*q)[idx+1:])
-		(*q)[len(*q)-1] = nil
+		(*q)[len(*q)-1] = queueItem{}
 		*q = (*q)[:len(*q)-1]
 		return j
 	}
@@ -2,7 +2,16 @@
 
 package run
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 // TODO: tech dept
 func TestNewManager(t *testing.T) {
@@ -13,3 +22,172 @@ func TestNewManager(t *testing.T) {
 func TestManager_Run(t *testing.T) {
 
 }
+
+func TestPhaseOffset(t *testing.T) {
+	tests := map[string]struct {
+		updateEvery int
+	}{
+		"job without update every": {updateEvery: 0},
+		"update every 1":           {updateEvery: 1},
+		"update every 10":          {updateEvery: 10},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			offsets := make(map[time.Duration]bool)
+			for i := 0; i < 50; i++ {
+				job := &mockJob{fullName: fmt.Sprintf("job%d", i), updateEvery: test.updateEvery}
+
+				shift, delay := phaseOffset(job)
+				shift2, delay2 := phaseOffset(job)
+				assert.Equal(t, shift, shift2)
+				assert.Equal(t, delay, delay2)
+
+				maxShift := test.updateEvery - 1
+				if maxShift < 0 {
+					maxShift = 0
+				}
+				assert.GreaterOrEqual(t, shift, 0)
+				assert.LessOrEqual(t, shift, maxShift)
+				assert.GreaterOrEqual(t, delay, time.Duration(0))
+				assert.Less(t, delay, tickSpread)
+				offsets[time.Duration(shift)*time.Second+delay] = true
+			}
+			assert.Greater(t, len(offsets), 1)
+		})
+	}
+}
+
+func TestManager_notify(t *testing.T) {
+	tests := map[string]struct {
+		spread bool
+	}{
+		"spread ticks":     {spread: true},
+		"not spread ticks": {spread: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mgr := NewManager()
+			mgr.SpreadTicks = test.spread
+
+			var jobs []*mockJob
+			for i := 0; i < 10; i++ {
+				job := &mockJob{fullName: fmt.Sprintf("job%d", i), updateEvery: 5}
+				jobs = append(jobs, job)
+				mgr.Start(job)
+			}
+
+			start := time.Now()
+			mgr.notify(context.Background(), 10)
+
+			for i, v := range mgr.queue {
+				if i > 0 {
+					assert.LessOrEqual(t, mgr.queue[i-1].delay, v.delay)
+				}
+			}
+			for _, job := range jobs {
+				clock, at := job.lastTick()
+				shift, delay := phaseOffset(job)
+				if !test.spread {
+					shift, delay = 0, 0
+				}
+				assert.Equal(t, 10-shift, clock)
+				assert.GreaterOrEqual(t, at.Sub(start), delay)
+			}
+
+			mgr.Cleanup()
+			for _, job := range jobs {
+				assert.True(t, job.stopped)
+			}
+		})
+	}
+}
+
+func TestManager_notify_ContextCanceled(t *testing.T) {
+	mgr := NewManager()
+	mgr.SpreadTicks = true
+
+	var job *mockJob
+	for i := 0; job == nil; i++ {
+		v := &mockJob{fullName: fmt.Sprintf("job%d", i)}
+		if _, delay := phaseOffset(v); delay > 100*time.Millisecond {
+			job = v
+		}
+	}
+	mgr.Start(job)
+	defer mgr.Cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mgr.notify(ctx, 1)
+
+	_, at := job.lastTick()
+	assert.True(t, at.IsZero())
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(2)
+
+	var mux sync.Mutex
+	var running, maxRunning int
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.Acquire()
+			defer limiter.Release()
+
+			mux.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mux.Unlock()
+
+			time.Sleep(time.Millisecond * 10)
+
+			mux.Lock()
+			running--
+			mux.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 0, running)
+	assert.Equal(t, 2, maxRunning)
+}
+
+type mockJob struct {
+	fullName    string
+	updateEvery int
+
+	mux     sync.Mutex
+	clock   int
+	tickAt  time.Time
+	stopped bool
+}
+
+func (m *mockJob) Name() string             { return m.fullName }
+func (m *mockJob) ModuleName() string       { return "module" }
+func (m *mockJob) FullName() string         { return m.fullName }
+func (m *mockJob) AutoDetection() bool      { return true }
+func (m *mockJob) AutoDetectionEvery() int  { return 0 }
+func (m *mockJob) RetryAutoDetection() bool { return false }
+func (m *mockJob) Start()                   {}
+func (m *mockJob) Stop()                    { m.stopped = true }
+func (m *mockJob) Cleanup()                 {}
+func (m *mockJob) UpdateEvery() int         { return m.updateEvery }
+
+func (m *mockJob) Tick(clock int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.clock, m.tickAt = clock, time.Now()
+}
+
+func (m *mockJob) lastTick() (int, time.Time) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.clock, m.tickAt
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import "math/rand"
+
+// MaxBackoff is the default backoff ceiling in seconds.
+const MaxBackoff = 600
+
+// Backoff is the exponential backoff of the repeatedly failing jobs: the delay doubles with every attempt
+// up to the ceiling.
+type Backoff struct {
+	// Max is the delay ceiling in seconds, zero means MaxBackoff.
+	Max int
+	// Jitter randomly shortens the delay by up to a quarter, so the jobs failing together don't retry in sync.
+	Jitter bool
+}
+
+// Delay returns the delay in seconds before the next attempt: the interval doubled attempt times.
+// The delay is never above the ceiling and never below the interval.
+func (b Backoff) Delay(interval, attempt int) int {
+	if interval <= 0 {
+		return 0
+	}
+	ceiling := b.Max
+	if ceiling <= 0 {
+		ceiling = MaxBackoff
+	}
+	if ceiling < interval {
+		ceiling = interval
+	}
+
+	delay := interval
+	for i := 0; i < attempt && delay < ceiling; i++ {
+		delay *= 2
+	}
+	if delay > ceiling {
+		delay = ceiling
+	}
+
+	if b.Jitter && delay > interval {
+		delay -= rand.Intn(delay/4 + 1)
+		if delay < interval {
+			delay = interval
+		}
+	}
+	return delay
+}
@@ -40,7 +40,8 @@ const (
 const (
 	// Not documented, the source https://github.com/netdata/netdata/blob/7772d35db617fc268a5f8e79d85fc093bef43a8d/database/rrd.h#L180-L186
 
-	LabelSourceK8s = 4
+	LabelSourceConf = 1
+	LabelSourceK8s  = 4
 )
 
 func (d DimAlgo) String() string {
@@ -93,6 +94,9 @@ type (
 		Dims   Dims
 		Vars   Vars
 
+		// Vnode is the virtual node the chart belongs to, the job virtual node (if any) is used if not set.
+		Vnode *VirtualNode
+
 		Retries int
 
 		remove bool
@@ -239,6 +243,11 @@ func (c Charts) Copy() *Charts {
 	return &charts
 }
 
+// Check validates the charts definitions.
+func (c Charts) Check() error {
+	return checkCharts(c...)
+}
+
 func (c Charts) index(chartID string) int {
 	for idx := range c {
 		if c[idx].ID == chartID {
@@ -325,6 +334,15 @@ func (c *Chart) RemoveDim(dimID string) error {
 	return nil
 }
 
+// TypeID returns the chart 'type.id' as it is sent to the Netdata.
+// It is set only for charts passed to a MetricsSink.
+func (c Chart) TypeID() string {
+	if c.typ == "" {
+		return c.ID
+	}
+	return c.typ + "." + c.id
+}
+
 // HasDim returns true if the chart contains dimension with the given ID, false otherwise.
 func (c Chart) HasDim(dimID string) bool {
 	return c.indexDim(dimID) != -1
@@ -117,6 +117,14 @@ func TestCharts_Copy(t *testing.T) {
 	}
 }
 
+func TestCharts_Check(t *testing.T) {
+	charts := Charts{createTestChart("1"), createTestChart("2")}
+	assert.NoError(t, charts.Check())
+
+	charts = append(charts, createTestChart("3 4"))
+	assert.Error(t, charts.Check())
+}
+
 func TestChart_Copy(t *testing.T) {
 	orig := createTestChart("1")
 
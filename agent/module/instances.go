@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstanceChartsConfig is the InstanceCharts configuration.
+type InstanceChartsConfig struct {
+	// Templates are the charts every instance gets. The chart, dimension and variable IDs (and the chart family,
+	// if it has a '%s' verb) are formatted with the instance ID using fmt.Sprintf.
+	Templates Charts
+	// ObsoleteAfter is the number of the data collections an instance may be missed in before its charts
+	// are removed (marked obsolete). Default is 1.
+	ObsoleteAfter int
+	// MaxInstances is the maximum number of the instances with charts, zero means no limit.
+	MaxInstances int
+}
+
+// InstanceCharts manages the charts of the dynamically discovered instances (databases, pods, vhosts, etc.).
+// The instance charts are created from the templates when the instance is seen for the first time
+// and removed when the instance hasn't been seen for ObsoleteAfter data collections.
+//
+// Call Seen for every collected instance and Update once per data collection after that.
+type InstanceCharts struct {
+	charts        *Charts
+	templates     Charts
+	obsoleteAfter int
+	maxInstances  int
+
+	instances map[string]*instance
+}
+
+type instance struct {
+	charts Charts
+	seen   bool
+	misses int
+}
+
+// NewInstanceCharts creates InstanceCharts that adds the instance charts to the charts.
+func NewInstanceCharts(charts *Charts, cfg InstanceChartsConfig) *InstanceCharts {
+	ic := &InstanceCharts{
+		charts:        charts,
+		templates:     cfg.Templates,
+		obsoleteAfter: cfg.ObsoleteAfter,
+		maxInstances:  cfg.MaxInstances,
+		instances:     make(map[string]*instance),
+	}
+	if ic.obsoleteAfter <= 0 {
+		ic.obsoleteAfter = 1
+	}
+	return ic
+}
+
+// Seen marks the instance as collected in the current data collection, it creates the instance charts
+// if the instance is new. The labels are added to the new instance charts.
+// It returns false if the instance has no charts because of the MaxInstances limit.
+func (ic *InstanceCharts) Seen(id string, labels ...Label) (bool, error) {
+	if inst, ok := ic.instances[id]; ok {
+		inst.seen, inst.misses = true, 0
+		return true, nil
+	}
+	if ic.maxInstances > 0 && len(ic.instances) >= ic.maxInstances {
+		return false, nil
+	}
+
+	charts := newInstanceCharts(ic.templates, id, labels)
+	if err := ic.charts.Add(charts...); err != nil {
+		return false, fmt.Errorf("instance '%s': %v", id, err)
+	}
+	ic.instances[id] = &instance{charts: charts, seen: true}
+	return true, nil
+}
+
+// Update finishes the data collection: the charts of the instances that haven't been seen
+// for ObsoleteAfter data collections are removed.
+func (ic *InstanceCharts) Update() {
+	for id, inst := range ic.instances {
+		if inst.seen {
+			inst.seen = false
+			continue
+		}
+		if inst.misses++; inst.misses < ic.obsoleteAfter {
+			continue
+		}
+		for _, chart := range inst.charts {
+			chart.MarkRemove()
+			chart.MarkNotCreated()
+		}
+		delete(ic.instances, id)
+	}
+}
+
+// Has returns whether the instance has charts.
+func (ic *InstanceCharts) Has(id string) bool {
+	_, ok := ic.instances[id]
+	return ok
+}
+
+// Len returns the number of the instances with charts.
+func (ic *InstanceCharts) Len() int {
+	return len(ic.instances)
+}
+
+func newInstanceCharts(templates Charts, id string, labels []Label) Charts {
+	charts := *templates.Copy()
+	for _, chart := range charts {
+		chart.ID = fmt.Sprintf(chart.ID, id)
+		if strings.Contains(chart.Fam, "%s") {
+			chart.Fam = fmt.Sprintf(chart.Fam, id)
+		}
+		chart.Labels = append(append([]Label(nil), chart.Labels...), labels...)
+		for _, dim := range chart.Dims {
+			dim.ID = fmt.Sprintf(dim.ID, id)
+		}
+		for _, v := range chart.Vars {
+			v.ID = fmt.Sprintf(v.ID, id)
+		}
+	}
+	return charts
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testInstanceTemplates = Charts{
+	{
+		ID:     "db_%s_size",
+		Title:  "Size",
+		Units:  "bytes",
+		Fam:    "db %s",
+		Labels: []Label{{Key: "type", Value: "db"}},
+		Dims:   Dims{{ID: "db_%s_size", Name: "size"}},
+		Vars:   Vars{{ID: "db_%s_max_size"}},
+	},
+	{
+		ID:    "db_%s_ops",
+		Title: "Operations",
+		Units: "ops/s",
+		Fam:   "operations",
+		Dims:  Dims{{ID: "db_%s_reads", Name: "reads"}, {ID: "db_%s_writes", Name: "writes"}},
+	},
+}
+
+func TestInstanceCharts_Seen(t *testing.T) {
+	charts := &Charts{}
+	ic := NewInstanceCharts(charts, InstanceChartsConfig{Templates: testInstanceTemplates})
+
+	ok, err := ic.Seen("db1", Label{Key: "database", Value: "db1"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = ic.Seen("db1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.Len(t, *charts, 2)
+	size := charts.Get("db_db1_size")
+	require.NotNil(t, size)
+	assert.Equal(t, "db db1", size.Fam)
+	assert.Equal(t, []Label{{Key: "type", Value: "db"}, {Key: "database", Value: "db1"}}, size.Labels)
+	assert.Equal(t, "db_db1_size", size.Dims[0].ID)
+	assert.Equal(t, "db_db1_max_size", size.Vars[0].ID)
+	ops := charts.Get("db_db1_ops")
+	require.NotNil(t, ops)
+	assert.Equal(t, "operations", ops.Fam)
+	assert.Equal(t, "db_db1_writes", ops.Dims[1].ID)
+
+	assert.Len(t, testInstanceTemplates[0].Labels, 1)
+	assert.Equal(t, "db_%s_size", testInstanceTemplates[0].ID)
+}
+
+func TestInstanceCharts_Update(t *testing.T) {
+	tests := map[string]struct {
+		obsoleteAfter int
+		missed        int
+		wantRemoved   bool
+	}{
+		"seen":                            {obsoleteAfter: 0, missed: 0, wantRemoved: false},
+		"missed once, default":            {obsoleteAfter: 0, missed: 1, wantRemoved: true},
+		"missed less than obsolete after": {obsoleteAfter: 3, missed: 2, wantRemoved: false},
+		"missed obsolete after":           {obsoleteAfter: 3, missed: 3, wantRemoved: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			charts := &Charts{}
+			ic := NewInstanceCharts(charts, InstanceChartsConfig{
+				Templates:     testInstanceTemplates,
+				ObsoleteAfter: test.obsoleteAfter,
+			})
+
+			_, _ = ic.Seen("db1")
+			_, _ = ic.Seen("db2")
+			ic.Update()
+			for i := 0; i < test.missed; i++ {
+				_, _ = ic.Seen("db2")
+				ic.Update()
+			}
+
+			assert.Equal(t, !test.wantRemoved, ic.Has("db1"))
+			for _, id := range []string{"db_db1_size", "db_db1_ops"} {
+				chart := charts.Get(id)
+				require.NotNil(t, chart)
+				assert.Equal(t, test.wantRemoved, chart.remove)
+				assert.Equal(t, test.wantRemoved, chart.Obsolete)
+			}
+			assert.True(t, ic.Has("db2"))
+			assert.False(t, charts.Get("db_db2_size").remove)
+		})
+	}
+}
+
+func TestInstanceCharts_ReAdd(t *testing.T) {
+	charts := &Charts{}
+	ic := NewInstanceCharts(charts, InstanceChartsConfig{Templates: testInstanceTemplates})
+
+	_, _ = ic.Seen("db1")
+	ic.Update()
+	ic.Update()
+	require.False(t, ic.Has("db1"))
+
+	ok, err := ic.Seen("db1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, *charts, 4)
+}
+
+func TestInstanceCharts_MaxInstances(t *testing.T) {
+	charts := &Charts{}
+	ic := NewInstanceCharts(charts, InstanceChartsConfig{Templates: testInstanceTemplates, MaxInstances: 2})
+
+	for _, id := range []string{"db1", "db2", "db3"} {
+		ok, err := ic.Seen(id)
+		require.NoError(t, err)
+		assert.Equal(t, id != "db3", ok)
+	}
+	assert.Equal(t, 2, ic.Len())
+	assert.Len(t, *charts, 4)
+
+	ic.Update()
+	_, _ = ic.Seen("db2")
+	ic.Update()
+
+	ok, _ := ic.Seen("db3")
+	assert.True(t, ok)
+	assert.Equal(t, 2, ic.Len())
+}
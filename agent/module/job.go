@@ -6,8 +6,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,19 +22,22 @@ var writeLock = &sync.Mutex{}
 
 var reSpace = regexp.MustCompile(`\s+`)
 
-func newRuntimeChart(pluginName string) *Chart {
+func pluginCtxName(pluginName string) string {
 	// this is needed to keep the same name as we had before https://github.com/netdata/go.d.plugin/issues/650
 	ctxName := pluginName
 	if ctxName == "go.d" {
 		ctxName = "go"
 	}
-	ctxName = reSpace.ReplaceAllString(ctxName, "_")
+	return reSpace.ReplaceAllString(ctxName, "_")
+}
+
+func newRuntimeChart(pluginName string) *Chart {
 	return &Chart{
 		typ:      "netdata",
 		Title:    "Execution time",
 		Units:    "ms",
 		Fam:      pluginName,
-		Ctx:      fmt.Sprintf("netdata.%s_plugin_execution_time", ctxName),
+		Ctx:      fmt.Sprintf("netdata.%s_plugin_execution_time", pluginCtxName(pluginName)),
 		Priority: 145000,
 		Dims: Dims{
 			{ID: "time"},
@@ -40,6 +45,21 @@ func newRuntimeChart(pluginName string) *Chart {
 	}
 }
 
+func newCollectionStatusChart(pluginName string) *Chart {
+	return &Chart{
+		typ:      "netdata",
+		Title:    "Data collection status",
+		Units:    "status",
+		Fam:      pluginName,
+		Ctx:      fmt.Sprintf("netdata.%s_plugin_collection_status", pluginCtxName(pluginName)),
+		Priority: 145001,
+		Dims: Dims{
+			{ID: "ok"},
+			{ID: "failing"},
+		},
+	}
+}
+
 type JobConfig struct {
 	PluginName      string
 	Name            string
@@ -47,17 +67,58 @@ type JobConfig struct {
 	FullName        string
 	Module          Module
 	Out             io.Writer
+	Sink            MetricsSink
 	UpdateEvery     int
 	AutoDetectEvery int
 	Priority        int
+	// Labels are attached to every chart of the job.
+	Labels map[string]string
+	// Vnode is the virtual node the job charts belong to, nil means the local host.
+	Vnode *VirtualNode
+	// CollectTimeout is the data collection timeout in seconds, zero disables it.
+	CollectTimeout int
+	// OnCollectHang is called from the job goroutine after maxCollectTimeouts consecutive collect timeouts.
+	OnCollectHang func()
+	// Limiter limits the number of the jobs collecting data at once, nil means no limit.
+	Limiter CollectLimiter
+	// Backoff is the data collection backoff after penaltyStep consecutive failures.
+	Backoff Backoff
+}
+
+// CollectLimiter limits the number of the jobs collecting data at once.
+type CollectLimiter interface {
+	// Acquire blocks until the job is allowed to collect data.
+	Acquire()
+	// Release is called when the job data collection is finished.
+	Release()
+}
+
+// MetricsSink is an additional job output, it receives the job charts along with the collected metrics
+// after every successful data collection.
+type MetricsSink interface {
+	// Update is called with a copy of the job active charts, the sink owns the passed values.
+	Update(job SinkJob, charts Charts, metrics map[string]int64)
+	// Remove is called when the job is stopped.
+	Remove(fullName string)
+}
+
+// SinkJob describes the job to a MetricsSink.
+type SinkJob struct {
+	FullName    string
+	ModuleName  string
+	UpdateEvery int
 }
 
 const (
-	penaltyStep = 5
-	maxPenalty  = 600
-	infTries    = -1
+	penaltyStep        = 5
+	infTries           = -1
+	maxCollectTimeouts = 3
 )
 
+// FloatPrecision is the precision the FloatCollector values are sent with, the plugins.d protocol values are integers.
+// The values are multiplied by FloatPrecision and the dimensions divisor is multiplied by FloatPrecision.
+const FloatPrecision = 1000
+
 func NewJob(cfg JobConfig) *Job {
 	var buf bytes.Buffer
 	return &Job{
@@ -68,17 +129,48 @@ func NewJob(cfg JobConfig) *Job {
 		updateEvery:     cfg.UpdateEvery,
 		AutoDetectEvery: cfg.AutoDetectEvery,
 		priority:        cfg.Priority,
+		labels:          newJobLabels(cfg.Labels),
+		vnode:           cfg.Vnode,
+		vnodes:          make(map[string]bool),
+		floats:          isFloatCollector(cfg.Module),
+		collectTimeout:  time.Duration(cfg.CollectTimeout) * time.Second,
+		onCollectHang:   cfg.OnCollectHang,
+		limiter:         cfg.Limiter,
+		backoff:         cfg.Backoff,
 		module:          cfg.Module,
 		out:             cfg.Out,
+		sink:            cfg.Sink,
 		AutoDetectTries: infTries,
 		runChart:        newRuntimeChart(cfg.PluginName),
+		statusChart:     newCollectionStatusChart(cfg.PluginName),
 		stop:            make(chan struct{}),
 		tick:            make(chan int),
 		buf:             &buf,
 		api:             netdataapi.New(&buf),
+		stats:           &jobStats{},
 	}
 }
 
+// JobStats is a snapshot of the job runtime statistics.
+type JobStats struct {
+	LastRun         time.Time
+	LastRunDuration time.Duration
+	Retries         int
+	Penalty         int
+	// Failing is whether the job data collection is failing repeatedly and the job is backed off.
+	Failing         bool
+	LastError       string
+	LastErrorTime   time.Time
+	SkippedTicks    int
+	Panics          int
+	CollectTimeouts int
+}
+
+type jobStats struct {
+	mux sync.Mutex
+	JobStats
+}
+
 // Job represents a job. It's a module wrapper.
 type Job struct {
 	pluginName string
@@ -90,23 +182,54 @@ type Job struct {
 	AutoDetectEvery int
 	AutoDetectTries int
 	priority        int
+	labels          []Label
+
+	vnode *VirtualNode
+	// vnodes are the defined virtual nodes GUIDs.
+	vnodes map[string]bool
+	// host is the virtual node GUID the following output belongs to, empty means the local host.
+	host string
 
 	*logger.Logger
 
 	module Module
+	// floats is whether the module is a FloatCollector.
+	floats bool
 
 	initialized bool
 	panicked    bool
+	timedOut    bool
+	// started is whether the job main loop has been run, only a started job owns its sink metrics.
+	started bool
+
+	collectTimeout time.Duration
+	onCollectHang  func()
+	limiter        CollectLimiter
+	// pending is the result of the collect that exceeded the timeout and is still running.
+	pending chan collectResult
+	// release releases the limiter slot of the pending collect.
+	release  func()
+	timeouts int
 
 	runChart *Chart
 	charts   *Charts
 	tick     chan int
 	out      io.Writer
+	sink     MetricsSink
 	buf      *bytes.Buffer
 	api      *netdataapi.API
 
+	// statusChart shows whether the job data collection is failing, it is created when the job is backed off.
+	statusChart *Chart
+
+	backoff Backoff
 	retries int
+	// penalty is the number of seconds the next data collection is delayed for by the backoff.
+	penalty int
+	// nextRun is the clock of the next data collection.
+	nextRun int
 	prevRun time.Time
+	stats   *jobStats
 
 	stop chan struct{}
 }
@@ -116,32 +239,44 @@ type Job struct {
 const RRD_ID_LENGTH_MAX = 200
 
 // FullName returns job full name.
-func (j Job) FullName() string {
+func (j *Job) FullName() string {
 	return j.fullName
 }
 
 // ModuleName returns job module name.
-func (j Job) ModuleName() string {
+func (j *Job) ModuleName() string {
 	return j.moduleName
 }
 
 // Name returns job name.
-func (j Job) Name() string {
+func (j *Job) Name() string {
 	return j.name
 }
 
+// UpdateEvery returns the job data collection interval.
+func (j *Job) UpdateEvery() int {
+	return j.updateEvery
+}
+
+// Stats returns the job runtime statistics.
+func (j *Job) Stats() JobStats {
+	j.stats.mux.Lock()
+	defer j.stats.mux.Unlock()
+	return j.stats.JobStats
+}
+
 // Panicked returns 'panicked' flag value.
-func (j Job) Panicked() bool {
+func (j *Job) Panicked() bool {
 	return j.panicked
 }
 
 // AutoDetectionEvery returns value of AutoDetectEvery.
-func (j Job) AutoDetectionEvery() int {
+func (j *Job) AutoDetectionEvery() int {
 	return j.AutoDetectEvery
 }
 
 // RetryAutoDetection returns whether it is needed to retry autodetection.
-func (j Job) RetryAutoDetection() bool {
+func (j *Job) RetryAutoDetection() bool {
 	return j.AutoDetectEvery > 0 && (j.AutoDetectTries == infTries || j.AutoDetectTries > 0)
 }
 
@@ -153,6 +288,7 @@ func (j *Job) AutoDetection() (ok bool) {
 			j.panicked = true
 			j.disableAutoDetection()
 			j.Errorf("PANIC %v", r)
+			j.setLastError(fmt.Sprintf("PANIC: %v", r))
 			if logger.IsDebug() {
 				j.Errorf("STACK: %s", debug.Stack())
 			}
@@ -164,16 +300,19 @@ func (j *Job) AutoDetection() (ok bool) {
 
 	if ok = j.init(); !ok {
 		j.Error("init failed")
+		j.setLastError("init failed")
 		j.disableAutoDetection()
 		return
 	}
 	if ok = j.check(); !ok {
 		j.Error("check failed")
+		j.setLastError("check failed")
 		return
 	}
 	j.Info("check success")
 	if ok = j.postCheck(); !ok {
 		j.Error("postCheck failed")
+		j.setLastError("postCheck failed")
 		j.disableAutoDetection()
 		return
 	}
@@ -186,11 +325,15 @@ func (j *Job) Tick(clock int) {
 	case j.tick <- clock:
 	default:
 		j.Debug("skip the tick due to previous run hasn't been finished")
+		j.stats.mux.Lock()
+		j.stats.SkippedTicks++
+		j.stats.mux.Unlock()
 	}
 }
 
 // Start starts job main loop.
 func (j *Job) Start() {
+	j.started = true
 	j.Infof("started, data collection interval %ds", j.updateEvery)
 	defer func() { j.Info("stopped") }()
 
@@ -200,16 +343,32 @@ LOOP:
 		case <-j.stop:
 			break LOOP
 		case t := <-j.tick:
-			if t%(j.updateEvery+j.penalty()) == 0 {
+			if t%j.updateEvery == 0 && t >= j.nextRun {
 				j.runOnce()
+				j.nextRun = t + j.updateEvery + j.penalty
 			}
 		}
 	}
-	j.module.Cleanup()
+	j.cleanupModule()
 	j.Cleanup()
 	j.stop <- struct{}{}
 }
 
+// cleanupModule cleans up the module. The module Cleanup is never called concurrently with Collect:
+// if the collect that exceeded the timeout is still running, the module is cleaned up once it has finished.
+func (j *Job) cleanupModule() {
+	if j.pending == nil {
+		j.module.Cleanup()
+		return
+	}
+	go func(ch chan collectResult) {
+		<-ch
+		j.module.Cleanup()
+	}(j.pending)
+	j.pending = nil
+	j.releaseSlot()
+}
+
 // Stop stops job main loop. It blocks until the job is stopped.
 func (j *Job) Stop() {
 	// TODO: should have blocking and non blocking stop
@@ -227,9 +386,15 @@ func (j *Job) Cleanup() {
 	}
 	j.buf.Reset()
 
-	if j.runChart.created {
-		j.runChart.MarkRemove()
-		j.createChart(j.runChart)
+	if j.sink != nil && j.started {
+		j.sink.Remove(j.FullName())
+	}
+
+	for _, chart := range []*Chart{j.runChart, j.statusChart} {
+		if chart.created {
+			chart.MarkRemove()
+			j.createChart(chart)
+		}
 	}
 	if j.charts != nil {
 		for _, chart := range *j.charts {
@@ -239,6 +404,7 @@ func (j *Job) Cleanup() {
 			}
 		}
 	}
+	j.switchHost("")
 	if j.buf.Len() > 0 {
 		writeLock.Lock()
 		_, _ = io.Copy(j.out, j.buf)
@@ -287,33 +453,123 @@ func (j *Job) runOnce() {
 	metrics := j.collect()
 
 	if j.panicked {
+		j.updateStats(curTime)
 		return
 	}
 
 	if j.processMetrics(metrics, curTime, sinceLastRun) {
-		j.retries = 0
+		j.recover()
 	} else {
-		j.retries++
+		if !j.timedOut {
+			j.setLastError("no metrics collected")
+		}
+		j.fail()
 	}
+	j.updateStatusChart(sinceLastRun)
+	j.updateStats(curTime)
+	j.switchHost("")
 
 	writeLock.Lock()
 	_, _ = io.Copy(j.out, j.buf)
 	writeLock.Unlock()
 	j.buf.Reset()
+
+	if j.timeouts >= maxCollectTimeouts {
+		j.releaseSlot()
+		if j.onCollectHang != nil {
+			j.Errorf("collect timed out %d times in a row", j.timeouts)
+			j.timeouts = 0
+			j.onCollectHang()
+		}
+	}
 }
 
-func (j *Job) collect() (result map[string]int64) {
-	j.panicked = false
+type collectResult struct {
+	metrics  map[string]int64
+	panicked bool
+}
+
+func (j *Job) collect() map[string]int64 {
+	j.panicked, j.timedOut = false, false
+
+	if j.collectTimeout <= 0 {
+		release := j.acquireSlot()
+		res := j.safeCollect()
+		release()
+		j.panicked = res.panicked
+		return res.metrics
+	}
+
+	// the module Collect is never called concurrently, the job waits for the previous one if it hasn't finished yet
+	if j.pending == nil {
+		// waiting for a limiter slot is not a part of the collect time
+		j.release = j.acquireSlot()
+		j.pending = make(chan collectResult, 1)
+		go func(ch chan collectResult, release func()) {
+			defer release()
+			ch <- j.safeCollect()
+		}(j.pending, j.release)
+	}
+
+	t := time.NewTimer(j.collectTimeout)
+	defer t.Stop()
+
+	select {
+	case res := <-j.pending:
+		j.pending, j.release = nil, nil
+		j.timeouts = 0
+		j.panicked = res.panicked
+		return res.metrics
+	case <-t.C:
+		j.timedOut = true
+		j.timeouts++
+		j.Warningf("collect timeout (%s) exceeded (%d/%d)", j.collectTimeout, j.timeouts, maxCollectTimeouts)
+		j.setLastError("collect timeout")
+		j.stats.mux.Lock()
+		j.stats.CollectTimeouts++
+		j.stats.mux.Unlock()
+		return nil
+	}
+}
+
+// acquireSlot waits for a limiter slot, the returned func releases it (only the first call has effect).
+func (j *Job) acquireSlot() func() {
+	if j.limiter == nil {
+		return func() {}
+	}
+	j.limiter.Acquire()
+	var once sync.Once
+	return func() { once.Do(j.limiter.Release) }
+}
+
+// releaseSlot releases the limiter slot of the pending collect, so the hung collect doesn't block the other jobs.
+func (j *Job) releaseSlot() {
+	if j.release != nil {
+		j.release()
+		j.release = nil
+	}
+}
+
+func (j *Job) safeCollect() (res collectResult) {
 	defer func() {
 		if r := recover(); r != nil {
-			j.panicked = true
+			res.panicked = true
 			j.Errorf("PANIC: %v", r)
+			j.setLastError(fmt.Sprintf("PANIC: %v", r))
+			j.stats.mux.Lock()
+			j.stats.Panics++
+			j.stats.mux.Unlock()
 			if logger.IsDebug() {
 				j.Errorf("STACK: %s", debug.Stack())
 			}
 		}
 	}()
-	return j.module.Collect()
+	if fc, ok := j.module.(FloatCollector); ok {
+		res.metrics = scaleFloats(fc.CollectFloat())
+	} else {
+		res.metrics = j.module.Collect()
+	}
+	return res
 }
 
 func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinceLastRun int) bool {
@@ -333,6 +589,7 @@ func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinc
 					len(typeID), RRD_ID_LENGTH_MAX, typeID)
 				chart.ignore = true
 			}
+			j.addJobLabels(chart)
 			j.createChart(chart)
 		}
 		if chart.remove {
@@ -353,9 +610,53 @@ func (j *Job) processMetrics(metrics map[string]int64, startTime time.Time, sinc
 		return false
 	}
 	j.updateChart(j.runChart, map[string]int64{"time": elapsed}, sinceLastRun)
+	j.updateSink(metrics)
 	return true
 }
 
+func (j *Job) updateSink(metrics map[string]int64) {
+	if j.sink == nil {
+		return
+	}
+	charts := make(Charts, 0, len(*j.charts))
+	for _, chart := range *j.charts {
+		if chart.ignore || chart.Obsolete || !chart.updated {
+			continue
+		}
+		chart = chart.Copy()
+		chart.Labels = append([]Label(nil), chart.Labels...)
+		if j.floats {
+			for _, dim := range chart.Dims {
+				dim.Div = j.dimDiv(chart, dim)
+			}
+		}
+		chart.typ, chart.id = getChartType(chart, j), getChartID(chart, j)
+		charts = append(charts, chart)
+	}
+	collected := make(map[string]int64, len(metrics))
+	for k, v := range metrics {
+		collected[k] = v
+	}
+	j.sink.Update(SinkJob{
+		FullName:    j.FullName(),
+		ModuleName:  j.ModuleName(),
+		UpdateEvery: j.updateEvery,
+	}, charts, collected)
+}
+
+// addJobLabels adds the job labels to the chart, the chart own labels take precedence.
+func (j *Job) addJobLabels(chart *Chart) {
+LOOP:
+	for _, l := range j.labels {
+		for _, cl := range chart.Labels {
+			if cl.Key == l.Key {
+				continue LOOP
+			}
+		}
+		chart.Labels = append(chart.Labels, l)
+	}
+}
+
 func (j *Job) createChart(chart *Chart) {
 	defer func() { chart.created = true }()
 	if chart.ignore {
@@ -366,6 +667,7 @@ func (j *Job) createChart(chart *Chart) {
 		chart.Priority = j.priority
 		j.priority++
 	}
+	j.switchChartHost(chart)
 	_ = j.api.CHART(
 		getChartType(chart, j),
 		getChartID(chart, j),
@@ -400,7 +702,7 @@ func (j *Job) createChart(chart *Chart) {
 			dim.Name,
 			dim.Algo.String(),
 			handleZero(dim.Mul),
-			handleZero(dim.Div),
+			j.dimDiv(chart, dim),
 			dim.DimOpts.String(),
 		)
 	}
@@ -429,6 +731,7 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 		sinceLastRun = 0
 	}
 
+	j.switchChartHost(chart)
 	_ = j.api.BEGIN(
 		getChartType(chart, j),
 		getChartID(chart, j),
@@ -452,7 +755,7 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 
 	for _, vr := range chart.Vars {
 		if v, ok := collected[vr.ID]; ok {
-			_ = j.api.VARIABLE(vr.ID, v)
+			_ = j.api.VARIABLE(vr.ID, j.varValue(chart, v))
 		}
 
 	}
@@ -466,12 +769,110 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 	return chart.updated
 }
 
-func (j Job) penalty() int {
-	v := j.retries / penaltyStep * penaltyStep * j.updateEvery / 2
-	if v > maxPenalty {
-		return maxPenalty
+// switchChartHost switches the output to the chart virtual node, the runtime charts belong to the local host.
+func (j *Job) switchChartHost(chart *Chart) {
+	vnode := chart.Vnode
+	if vnode == nil && !j.isRuntimeChart(chart) {
+		vnode = j.vnode
 	}
-	return v
+	if vnode == nil {
+		j.switchHost("")
+		return
+	}
+
+	guid := vnode.ID()
+	if !j.vnodes[guid] {
+		_ = j.api.HOSTINFO(guid, vnode.Hostname, vnode.Labels)
+		j.vnodes[guid] = true
+	}
+	j.switchHost(guid)
+}
+
+// switchHost switches the output to the virtual node, the empty guid switches back to the local host.
+// The job output always ends on the local host, the plugin output is shared by all the jobs.
+func (j *Job) switchHost(guid string) {
+	if j.host == guid {
+		return
+	}
+	_ = j.api.HOST(guid)
+	j.host = guid
+}
+
+// dimDiv returns the dimension divisor, the FloatCollector values are scaled by FloatPrecision.
+func (j *Job) dimDiv(chart *Chart, dim *Dim) int {
+	if !j.floats || j.isRuntimeChart(chart) {
+		return handleZero(dim.Div)
+	}
+	return handleZero(dim.Div) * FloatPrecision
+}
+
+// isRuntimeChart returns whether the chart is one of the job runtime charts, and not a module chart.
+func (j *Job) isRuntimeChart(chart *Chart) bool {
+	return chart == j.runChart || chart == j.statusChart
+}
+
+// updateStatusChart reports whether the job data collection is failing. The chart is created when the job
+// is backed off for the first time, the jobs that never fail don't have it.
+func (j *Job) updateStatusChart(sinceLastRun int) {
+	failing := j.retries >= penaltyStep
+	if !failing && !j.statusChart.created {
+		return
+	}
+	if !j.statusChart.created {
+		j.statusChart.ID = fmt.Sprintf("collection_status_of_%s", j.FullName())
+		j.createChart(j.statusChart)
+	}
+	var v int64
+	if failing {
+		v = 1
+	}
+	j.updateChart(j.statusChart, map[string]int64{"ok": 1 - v, "failing": v}, sinceLastRun)
+}
+
+// varValue returns the chart variable value, the variables have no divisor so the FloatCollector values are unscaled.
+func (j *Job) varValue(chart *Chart, v int64) int64 {
+	if !j.floats || j.isRuntimeChart(chart) {
+		return v
+	}
+	return int64(math.Round(float64(v) / FloatPrecision))
+}
+
+// fail counts the failed data collection, the job is backed off after penaltyStep consecutive failures.
+func (j *Job) fail() {
+	if j.retries++; j.retries < penaltyStep {
+		return
+	}
+	j.penalty = j.backoff.Delay(j.updateEvery, j.retries-penaltyStep+1) - j.updateEvery
+	if j.retries == penaltyStep {
+		j.Warningf("data collection is failing (%d times in a row), backing off", j.retries)
+	}
+}
+
+// recover resets the backoff after the successful data collection.
+func (j *Job) recover() {
+	if j.retries >= penaltyStep {
+		j.Infof("data collection recovered after %d failures", j.retries)
+	}
+	j.retries, j.penalty = 0, 0
+}
+
+func (j *Job) updateStats(runStart time.Time) {
+	j.stats.mux.Lock()
+	defer j.stats.mux.Unlock()
+
+	j.stats.LastRun = runStart
+	j.stats.LastRunDuration = time.Since(runStart)
+	j.stats.Retries = j.retries
+	j.stats.Penalty = j.penalty
+	j.stats.Failing = j.retries >= penaltyStep
+}
+
+func (j *Job) setLastError(msg string) {
+	j.stats.mux.Lock()
+	defer j.stats.mux.Unlock()
+
+	j.stats.LastError = msg
+	j.stats.LastErrorTime = time.Now()
 }
 
 func getChartType(chart *Chart, j *Job) string {
@@ -504,6 +905,43 @@ func getChartID(chart *Chart, j *Job) string {
 	return chart.id
 }
 
+func isFloatCollector(mod Module) bool {
+	_, ok := mod.(FloatCollector)
+	return ok
+}
+
+// scaleFloats converts the FloatCollector values to the protocol integer values. NaN, Inf and the values
+// that overflow int64 after scaling are skipped.
+func scaleFloats(metrics map[string]float64) map[string]int64 {
+	if metrics == nil {
+		return nil
+	}
+	scaled := make(map[string]int64, len(metrics))
+	for k, v := range metrics {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		v = math.Round(v * FloatPrecision)
+		if v >= float64(math.MaxInt64) || v < float64(math.MinInt64) {
+			continue
+		}
+		scaled[k] = int64(v)
+	}
+	return scaled
+}
+
+func newJobLabels(labels map[string]string) []Label {
+	var ls []Label
+	for k, v := range labels {
+		if k == "" || v == "" {
+			continue
+		}
+		ls = append(ls, Label{Key: k, Value: v, Source: LabelSourceConf})
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Key < ls[j].Key })
+	return ls
+}
+
 func calcSinceLastRun(curTime, prevRun time.Time) int {
 	if prevRun.IsZero() {
 		return 0
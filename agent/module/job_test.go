@@ -3,8 +3,12 @@
 package module
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -149,6 +153,7 @@ func TestJob_AutoDetection_FailCheck(t *testing.T) {
 
 	assert.False(t, job.AutoDetection())
 	assert.True(t, m.CleanupDone)
+	assert.Equal(t, "check failed", job.Stats().LastError)
 }
 
 func TestJob_AutoDetection_FailPostCheck(t *testing.T) {
@@ -280,6 +285,598 @@ func TestJob_MainLoop_Panic(t *testing.T) {
 
 	assert.True(t, job.Panicked())
 	assert.True(t, m.CleanupDone)
+	assert.Equal(t, "PANIC: panic in Collect", job.Stats().LastError)
+	assert.NotZero(t, job.Stats().Panics)
+}
+
+func TestJob_Stats(t *testing.T) {
+	var collected map[string]int64
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return collected },
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+
+	assert.Zero(t, job.Stats())
+
+	for i := 0; i < penaltyStep; i++ {
+		job.runOnce()
+	}
+	stats := job.Stats()
+	assert.False(t, stats.LastRun.IsZero())
+	assert.Equal(t, penaltyStep, stats.Retries)
+	assert.Equal(t, job.penalty, stats.Penalty)
+	assert.True(t, stats.Failing)
+	assert.NotZero(t, stats.Penalty)
+	assert.Equal(t, "no metrics collected", stats.LastError)
+	assert.False(t, stats.LastErrorTime.IsZero())
+
+	collected = map[string]int64{"id1": 1}
+	job.runOnce()
+	stats = job.Stats()
+	assert.Zero(t, stats.Retries)
+	assert.Zero(t, stats.Penalty)
+	assert.Equal(t, "no metrics collected", stats.LastError)
+}
+
+func TestJob_CollectTimeout(t *testing.T) {
+	var calls int32
+	block := make(chan struct{})
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 {
+			atomic.AddInt32(&calls, 1)
+			<-block
+			return map[string]int64{"id1": 1}
+		},
+	}
+	var hangs int
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+	job.collectTimeout = time.Millisecond * 50
+	job.onCollectHang = func() { hangs++ }
+
+	for i := 0; i < maxCollectTimeouts; i++ {
+		job.runOnce()
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "collect must not be called while the previous one is running")
+	assert.Equal(t, 1, hangs)
+	stats := job.Stats()
+	assert.Equal(t, maxCollectTimeouts, stats.CollectTimeouts)
+	assert.Equal(t, "collect timeout", stats.LastError)
+
+	close(block)
+	job.runOnce()
+
+	assert.Equal(t, 0, job.timeouts)
+	assert.Zero(t, job.Stats().Retries)
+	assert.Nil(t, job.pending)
+}
+
+func TestJob_Stop_PendingCollect(t *testing.T) {
+	block := make(chan struct{})
+	cleanedUp := make(chan struct{})
+	var closed, closedOnCollect bool
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 {
+			<-block
+			closedOnCollect = closed
+			return map[string]int64{"id1": 1}
+		},
+		CleanupFunc: func() {
+			closed = true
+			close(cleanedUp)
+		},
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+	job.collectTimeout = time.Millisecond * 50
+
+	done := make(chan struct{})
+	go func() { defer close(done); job.Start() }()
+	job.tick <- 1
+	job.Stop()
+	<-done
+
+	select {
+	case <-cleanedUp:
+		t.Fatal("module is cleaned up while collect is running")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	close(block)
+	select {
+	case <-cleanedUp:
+	case <-time.After(time.Second * 5):
+		t.Fatal("module is not cleaned up after collect has finished")
+	}
+	assert.False(t, closedOnCollect)
+}
+
+type mockSink struct {
+	job     SinkJob
+	charts  Charts
+	metrics map[string]int64
+	removed string
+}
+
+func (m *mockSink) Update(job SinkJob, charts Charts, metrics map[string]int64) {
+	m.job, m.charts, m.metrics = job, charts, metrics
+}
+
+func (m *mockSink) Remove(fullName string) { m.removed = fullName }
+
+func TestJob_Sink(t *testing.T) {
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id1", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+				&Chart{ID: "id2", Title: "title", Units: "units", Dims: Dims{{ID: "id2"}}},
+				&Chart{ID: "id3", Title: "title", Units: "units", Dims: Dims{{ID: "id3"}}, Opts: Opts{Obsolete: true}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return map[string]int64{"id1": 1, "id3": 3} },
+	}
+	sink := &mockSink{}
+	job := newTestJob()
+	job.sink = sink
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+
+	job.runOnce()
+
+	assert.Equal(t, SinkJob{FullName: job.FullName(), ModuleName: modName, UpdateEvery: 1}, sink.job)
+	assert.Equal(t, map[string]int64{"id1": 1, "id3": 3}, sink.metrics)
+	if assert.Len(t, sink.charts, 1) {
+		assert.Equal(t, "id1", sink.charts[0].ID)
+		assert.Equal(t, job.FullName()+".id1", sink.charts[0].TypeID())
+		assert.False(t, sink.charts[0] == (*job.charts)[0])
+	}
+
+	job.Cleanup()
+	assert.Empty(t, sink.removed, "not started job removes the sink metrics")
+
+	go job.Start()
+	job.Stop()
+	assert.Equal(t, job.FullName(), sink.removed)
+}
+
+func TestJob_Labels(t *testing.T) {
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id1", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}},
+					Labels: []Label{{Key: "env", Value: "chart", Source: LabelSourceK8s}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return map[string]int64{"id1": 1} },
+	}
+	var buf bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName:  pluginName,
+		Name:        jobName,
+		ModuleName:  modName,
+		FullName:    modName + "_" + jobName,
+		Module:      m,
+		Out:         &buf,
+		UpdateEvery: 1,
+		Labels:      map[string]string{"env": "prod", "team": "web", "empty": ""},
+	})
+	job.charts = job.module.Charts()
+
+	job.runOnce()
+
+	expected := []Label{
+		{Key: "env", Value: "chart", Source: LabelSourceK8s},
+		{Key: "team", Value: "web", Source: LabelSourceConf},
+	}
+	assert.Equal(t, expected, (*job.charts)[0].Labels)
+	assert.Contains(t, buf.String(), "CLABEL 'env' 'chart' '4'\nCLABEL 'team' 'web' '1'\nCLABEL_COMMIT\n")
+}
+
+func TestJob_Vnode(t *testing.T) {
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id1", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+				&Chart{ID: "id2", Title: "title", Units: "units", Dims: Dims{{ID: "id2"}},
+					Vnode: &VirtualNode{GUID: "guid2", Hostname: "host2"}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return map[string]int64{"id1": 1, "id2": 2} },
+	}
+	var buf bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName:  pluginName,
+		Name:        jobName,
+		ModuleName:  modName,
+		FullName:    modName + "_" + jobName,
+		Module:      m,
+		Out:         &buf,
+		UpdateEvery: 1,
+		Vnode:       &VirtualNode{GUID: "guid1", Hostname: "host1", Labels: map[string]string{"env": "prod"}},
+	})
+	job.charts = job.module.Charts()
+
+	job.runOnce()
+	job.runOnce()
+	out := buf.String()
+
+	assert.Equal(t, 1, strings.Count(out, "HOST_DEFINE 'guid1' 'host1'\nHOST_LABEL 'env' 'prod'\nHOST_DEFINE_END\n"))
+	assert.Equal(t, 1, strings.Count(out, "HOST_DEFINE 'guid2' 'host2'\nHOST_DEFINE_END\n"))
+	assert.Less(t, strings.Index(out, "CHART 'netdata.execution_time_of_"), strings.Index(out, "HOST "))
+	assert.Less(t, strings.Index(out, "HOST 'guid1'"), strings.Index(out, "CHART '"+modName+"_"+jobName+".id1'"))
+	assert.Less(t, strings.Index(out, "HOST 'guid2'"), strings.Index(out, "CHART '"+modName+"_"+jobName+".id2'"))
+	assert.Equal(t, 2, strings.Count(out, "HOST ''\n"))
+	assert.Equal(t, strings.LastIndex(out, "HOST "), strings.LastIndex(out, "HOST ''"))
+}
+
+type mockFloatModule struct {
+	MockModule
+	CollectFloatFunc func() map[string]float64
+}
+
+func (m *mockFloatModule) CollectFloat() map[string]float64 { return m.CollectFloatFunc() }
+
+func TestJob_FloatCollector(t *testing.T) {
+	m := &mockFloatModule{
+		MockModule: MockModule{
+			ChartsFunc: func() *Charts {
+				return &Charts{
+					&Chart{
+						ID: "id1", Title: "title", Units: "units",
+						Dims: Dims{{ID: "id1"}, {ID: "id2", Div: 10}, {ID: "id3"}, {ID: "id4"}},
+						Vars: Vars{{ID: "var1"}},
+					},
+				}
+			},
+		},
+		CollectFloatFunc: func() map[string]float64 {
+			return map[string]float64{"id1": 1.5, "id2": 0.0125, "id3": math.NaN(), "id4": 1e17, "var1": 42.4}
+		},
+	}
+	var buf bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName:  pluginName,
+		Name:        jobName,
+		ModuleName:  modName,
+		FullName:    modName + "_" + jobName,
+		Module:      m,
+		Out:         &buf,
+		UpdateEvery: 1,
+	})
+	job.charts = job.module.Charts()
+
+	job.runOnce()
+	out := buf.String()
+
+	assert.Contains(t, out, "DIMENSION 'id1' '' '' '1' '1000' ''\n")
+	assert.Contains(t, out, "DIMENSION 'id2' '' '' '1' '10000' ''\n")
+	assert.Contains(t, out, "DIMENSION 'time' '' '' '1' '1' ''\n")
+	assert.Contains(t, out, "SET 'id1' = 1500\n")
+	assert.Contains(t, out, "SET 'id2' = 13\n")
+	assert.Contains(t, out, "SET 'id3' = \n")
+	assert.Contains(t, out, "SET 'id4' = \n")
+	assert.Contains(t, out, "VARIABLE CHART 'var1' = 42\n")
+}
+
+func TestScaleFloats(t *testing.T) {
+	scaled := scaleFloats(map[string]float64{
+		"value":     1.2345,
+		"negative":  -2.5,
+		"nan":       math.NaN(),
+		"inf":       math.Inf(1),
+		"overflow":  1e16,
+		"underflow": -1e16,
+		"max":       9e15,
+	})
+
+	assert.Equal(t, map[string]int64{"value": 1235, "negative": -2500, "max": 9e18}, scaled)
+	assert.Nil(t, scaleFloats(nil))
+}
+
+type mockLimiter struct{ acquired, released int }
+
+func (m *mockLimiter) Acquire() { m.acquired++ }
+func (m *mockLimiter) Release() { m.released++ }
+
+func TestJob_Limiter(t *testing.T) {
+	limiter := &mockLimiter{}
+	m := &MockModule{
+		CollectFunc: func() map[string]int64 {
+			assert.Equal(t, 1, limiter.acquired-limiter.released)
+			return nil
+		},
+	}
+	job := NewJob(JobConfig{
+		PluginName:  pluginName,
+		Name:        jobName,
+		ModuleName:  modName,
+		FullName:    modName + "_" + jobName,
+		Module:      m,
+		Out:         ioutil.Discard,
+		UpdateEvery: 1,
+		Limiter:     limiter,
+	})
+	job.charts = &Charts{}
+
+	job.runOnce()
+	job.runOnce()
+
+	assert.Equal(t, 2, limiter.acquired)
+	assert.Equal(t, 2, limiter.released)
+}
+
+func TestJob_Backoff(t *testing.T) {
+	var calls int
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { calls++; return nil },
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+
+	done := make(chan struct{})
+	go func() { defer close(done); job.Start() }()
+	for i := 1; i <= 20; i++ {
+		job.tick <- i
+	}
+	job.Stop()
+	<-done
+
+	// collects at 1-5, then backs off: 7, 11, 19
+	assert.Equal(t, 8, calls)
+	assert.Equal(t, 8, job.retries)
+	assert.Equal(t, 15, job.penalty)
+	assert.True(t, job.Stats().Failing)
+}
+
+func TestJob_Backoff_Schedule(t *testing.T) {
+	var calls int32
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 {
+			if atomic.AddInt32(&calls, 1) <= penaltyStep+1 {
+				return nil
+			}
+			return map[string]int64{"id1": 1}
+		},
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 2
+
+	done := make(chan struct{})
+	go func() { defer close(done); job.Start() }()
+
+	// the odd ticks don't run the job, the odd tick is received only after the previous tick run has finished
+	var collectedAt []int
+	var prev int32
+	for clock := 2; clock <= 30; clock += 2 {
+		job.tick <- clock
+		job.tick <- clock + 1
+		if n := atomic.LoadInt32(&calls); n != prev {
+			collectedAt = append(collectedAt, clock)
+			prev = n
+		}
+	}
+	job.Stop()
+	<-done
+
+	// fails at 2-10 and 14 (penalty 2), skips the ticks till 22 (penalty 6), recovers and collects every update_every
+	assert.Equal(t, []int{2, 4, 6, 8, 10, 14, 22, 24, 26, 28, 30}, collectedAt)
+	assert.Zero(t, job.penalty)
+}
+
+func TestJob_Backoff_Recover(t *testing.T) {
+	var collected map[string]int64
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return collected },
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+
+	for i := 0; i < penaltyStep+1; i++ {
+		job.runOnce()
+	}
+	assert.True(t, job.Stats().Failing)
+
+	collected = map[string]int64{"id1": 1}
+	job.runOnce()
+	assert.Zero(t, job.retries)
+	assert.Zero(t, job.penalty)
+	assert.False(t, job.Stats().Failing)
+}
+
+func TestJob_Backoff_StatusChart(t *testing.T) {
+	var collected map[string]int64
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return collected },
+	}
+	var buf bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName:  pluginName,
+		Name:        jobName,
+		ModuleName:  modName,
+		FullName:    modName + "_" + jobName,
+		Module:      m,
+		Out:         &buf,
+		UpdateEvery: 1,
+	})
+	job.charts = job.module.Charts()
+
+	for i := 0; i < penaltyStep-1; i++ {
+		job.runOnce()
+	}
+	assert.NotContains(t, buf.String(), "collection_status_of_")
+
+	job.runOnce()
+	out := buf.String()
+	assert.Contains(t, out, "CHART 'netdata.collection_status_of_"+modName+"_"+jobName+"'")
+	assert.Contains(t, out, "SET 'ok' = 0\nSET 'failing' = 1\n")
+
+	buf.Reset()
+	collected = map[string]int64{"id1": 1}
+	job.runOnce()
+	assert.Contains(t, buf.String(), "SET 'ok' = 1\nSET 'failing' = 0\n")
+}
+
+func TestJob_Backoff_Max(t *testing.T) {
+	job := newTestJob()
+	job.module = &MockModule{}
+	job.charts = &Charts{}
+	job.updateEvery = 5
+	job.backoff = Backoff{Max: 60}
+
+	for i := 0; i < 20; i++ {
+		job.runOnce()
+	}
+	assert.Equal(t, 55, job.penalty)
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	tests := map[string]struct {
+		backoff  Backoff
+		interval int
+		attempt  int
+		expected int
+	}{
+		"zero interval":              {interval: 0, attempt: 3, expected: 0},
+		"first attempt":              {interval: 10, attempt: 0, expected: 10},
+		"doubles":                    {interval: 10, attempt: 3, expected: 80},
+		"default ceiling":            {interval: 10, attempt: 30, expected: MaxBackoff},
+		"custom ceiling":             {backoff: Backoff{Max: 100}, interval: 10, attempt: 5, expected: 100},
+		"ceiling below the interval": {backoff: Backoff{Max: 100}, interval: 300, attempt: 5, expected: 300},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.backoff.Delay(test.interval, test.attempt))
+		})
+	}
+}
+
+func TestBackoff_Delay_Jitter(t *testing.T) {
+	b := Backoff{Jitter: true}
+
+	assert.Equal(t, 10, b.Delay(10, 0))
+	for i := 0; i < 100; i++ {
+		v := b.Delay(10, 3)
+		assert.GreaterOrEqual(t, v, 60)
+		assert.LessOrEqual(t, v, 80)
+	}
+}
+
+type slowLimiter struct {
+	mockLimiter
+	wait time.Duration
+}
+
+func (m *slowLimiter) Acquire() { time.Sleep(m.wait); m.mockLimiter.Acquire() }
+
+func TestJob_Limiter_NotCountedAsCollectTime(t *testing.T) {
+	limiter := &slowLimiter{wait: time.Millisecond * 100}
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { return map[string]int64{"id1": 1} },
+	}
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+	job.collectTimeout = time.Millisecond * 50
+	job.limiter = limiter
+
+	job.runOnce()
+
+	assert.False(t, job.timedOut)
+	assert.Zero(t, job.Stats().CollectTimeouts)
+	assert.Nil(t, job.release)
+}
+
+func TestJob_Limiter_HungCollectReleasesSlot(t *testing.T) {
+	limiter := &mockLimiter{}
+	block := make(chan struct{})
+	defer close(block)
+	m := &MockModule{
+		ChartsFunc: func() *Charts {
+			return &Charts{
+				&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}},
+			}
+		},
+		CollectFunc: func() map[string]int64 { <-block; return nil },
+	}
+	var hangs int
+	job := newTestJob()
+	job.module = m
+	job.charts = job.module.Charts()
+	job.updateEvery = 1
+	job.collectTimeout = time.Millisecond * 20
+	job.limiter = limiter
+	job.onCollectHang = func() { hangs++ }
+
+	for i := 0; i < maxCollectTimeouts; i++ {
+		job.runOnce()
+	}
+
+	assert.Equal(t, 1, hangs)
+	assert.Equal(t, 1, limiter.acquired)
+	assert.Equal(t, 1, limiter.released)
+}
+
+func TestVirtualNode_ID(t *testing.T) {
+	assert.Equal(t, "guid", VirtualNode{GUID: "guid", Hostname: "host"}.ID())
+	assert.Equal(t, VirtualNode{Hostname: "host"}.ID(), VirtualNode{Hostname: "host"}.ID())
+	assert.NotEqual(t, VirtualNode{Hostname: "host1"}.ID(), VirtualNode{Hostname: "host2"}.ID())
 }
 
 func TestJob_Tick(t *testing.T) {
@@ -287,4 +884,5 @@ func TestJob_Tick(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		job.Tick(i)
 	}
+	assert.Equal(t, 3, job.Stats().SkippedTicks)
 }
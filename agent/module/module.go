@@ -29,6 +29,14 @@ type Module interface {
 	GetBase() *Base
 }
 
+// FloatCollector is an optional interface for the modules that collect floating-point values.
+// If a module implements it, the job calls CollectFloat instead of Collect, so the module doesn't need to scale
+// the values and set the dimensions divisor to keep the fractional part.
+type FloatCollector interface {
+	// CollectFloat collects metrics.
+	CollectFloat() map[string]float64
+}
+
 // Base is a helper struct. All modules should embed this struct.
 type Base struct {
 	*logger.Logger
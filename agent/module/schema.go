@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schema is the module configuration schema, it is derived from the module struct yaml tags.
+// It is a subset of the JSON Schema, so it can be used for editor integration.
+// The fields with the `required:"true"` tag are required.
+type Schema struct {
+	// Type is one of "string", "integer", "number", "boolean", "array", "object", empty means any type.
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+const (
+	schemaString  = "string"
+	schemaInteger = "integer"
+	schemaNumber  = "number"
+	schemaBoolean = "boolean"
+	schemaArray   = "array"
+	schemaObject  = "object"
+)
+
+var (
+	typeBase            = reflect.TypeOf(Base{})
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeUnmarshaler     = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	typeTextUnmarshaler = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+	jobParamsSchema     = map[string]*Schema{
+		"name":                     {Type: schemaString},
+		"module":                   {Type: schemaString},
+		"update_every":             {Type: schemaInteger},
+		"autodetection_retry":      {Type: schemaInteger},
+		"priority":                 {Type: schemaInteger},
+		"collect_timeout":          {Type: schemaInteger},
+		"backoff_max":              {Type: schemaInteger},
+		"backoff_jitter":           {Type: schemaBoolean},
+		"labels":                   {Type: schemaObject, AdditionalProperties: &Schema{Type: schemaString}},
+		"restart_on_secret_change": {Type: schemaBoolean},
+		"vnode": {
+			Type: schemaObject,
+			Properties: map[string]*Schema{
+				"guid":     {Type: schemaString},
+				"hostname": {Type: schemaString},
+				"labels":   {Type: schemaObject, AdditionalProperties: &Schema{Type: schemaString}},
+			},
+			Required: []string{"hostname"},
+		},
+	}
+)
+
+// NewSchema returns the schema of the v type.
+func NewSchema(v interface{}) *Schema {
+	return newSchema(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// Schema returns the module job configuration schema.
+func (c Creator) Schema() *Schema {
+	return NewJobSchema(c.Create())
+}
+
+// NewJobSchema returns the module job configuration schema, it includes the parameters common for all modules.
+func NewJobSchema(mod Module) *Schema {
+	s := NewSchema(mod)
+	if s.Properties == nil {
+		s.Properties = make(map[string]*Schema)
+	}
+	for k, v := range jobParamsSchema {
+		if _, ok := s.Properties[k]; !ok {
+			s.Properties[k] = v
+		}
+	}
+	return s
+}
+
+// ConfigFileSchema returns the module configuration file schema.
+func (c Creator) ConfigFileSchema() *Schema {
+	return &Schema{
+		Type: schemaObject,
+		Properties: map[string]*Schema{
+			"update_every":        {Type: schemaInteger},
+			"autodetection_retry": {Type: schemaInteger},
+			"priority":            {Type: schemaInteger},
+			"labels":              jobParamsSchema["labels"],
+			"jobs":                {Type: schemaArray, Items: c.Schema()},
+		},
+	}
+}
+
+func newSchema(typ reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == typeDuration || reflect.PtrTo(typ).Implements(typeUnmarshaler) || reflect.PtrTo(typ).Implements(typeTextUnmarshaler) {
+		return &Schema{}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: schemaString}
+	case reflect.Bool:
+		return &Schema{Type: schemaBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: schemaInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: schemaNumber}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: schemaArray, Items: newSchema(typ.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: schemaObject, AdditionalProperties: newSchema(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return &Schema{Type: schemaObject}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		s := &Schema{Type: schemaObject, Properties: make(map[string]*Schema)}
+		addStructFields(s, typ, seen)
+		sort.Strings(s.Required)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func addStructFields(s *Schema, typ reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type == typeBase || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.IndexByte(tag, ','); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if strings.Contains(opts, "inline") {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Struct:
+				addStructFields(s, ft, seen)
+			case reflect.Map:
+				s.AdditionalProperties = newSchema(ft.Elem(), seen)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		s.Properties[name] = newSchema(field.Type, seen)
+		if field.Tag.Get("required") == "true" {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Validate checks the configuration against the schema.
+// It returns the unknown keys and the errors (wrong value types and missing required keys).
+func (s *Schema) Validate(cfg map[string]interface{}) (unknown []string, errs []string) {
+	v := &schemaValidator{}
+	m := make(map[interface{}]interface{}, len(cfg))
+	for k, val := range cfg {
+		m[k] = val
+	}
+	v.validateObject(s, "", m)
+	sort.Strings(v.unknown)
+	sort.Strings(v.errs)
+	return v.unknown, v.errs
+}
+
+type schemaValidator struct {
+	unknown []string
+	errs    []string
+}
+
+func (v *schemaValidator) validate(s *Schema, path string, value interface{}) {
+	if value == nil || s.Type == "" {
+		return
+	}
+
+	switch s.Type {
+	case schemaString:
+		switch value.(type) {
+		case []interface{}, map[interface{}]interface{}, map[string]interface{}, map[string]string:
+			v.typeError(s, path, value)
+		}
+	case schemaInteger:
+		switch value := value.(type) {
+		case int, int64, uint64:
+		case float64:
+			if value != float64(int64(value)) {
+				v.typeError(s, path, value)
+			}
+		default:
+			v.typeError(s, path, value)
+		}
+	case schemaNumber:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+		default:
+			v.typeError(s, path, value)
+		}
+	case schemaBoolean:
+		if _, ok := value.(bool); !ok {
+			v.typeError(s, path, value)
+		}
+	case schemaArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			v.typeError(s, path, value)
+			return
+		}
+		if s.Items == nil {
+			return
+		}
+		for i, item := range items {
+			v.validate(s.Items, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	case schemaObject:
+		m, ok := toObject(value)
+		if !ok {
+			v.typeError(s, path, value)
+			return
+		}
+		v.validateObject(s, path, m)
+	}
+}
+
+func (v *schemaValidator) validateObject(s *Schema, path string, m map[interface{}]interface{}) {
+	for _, name := range s.Required {
+		if m[name] == nil {
+			v.errs = append(v.errs, fmt.Sprintf("'%s' is required", joinPath(path, name)))
+		}
+	}
+	for key, value := range m {
+		name := fmt.Sprint(key)
+		if prop, ok := s.Properties[name]; ok {
+			v.validate(prop, joinPath(path, name), value)
+		} else if s.AdditionalProperties != nil {
+			v.validate(s.AdditionalProperties, joinPath(path, name), value)
+		} else if s.Properties != nil {
+			v.unknown = append(v.unknown, joinPath(path, name))
+		}
+	}
+}
+
+func (v *schemaValidator) typeError(s *Schema, path string, value interface{}) {
+	v.errs = append(v.errs, fmt.Sprintf("'%s': expected %s, got %s", path, s.Type, valueType(value)))
+}
+
+func toObject(value interface{}) (map[interface{}]interface{}, bool) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		return value, true
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(value))
+		for k, v := range value {
+			m[k] = v
+		}
+		return m, true
+	case map[string]string:
+		m := make(map[interface{}]interface{}, len(value))
+		for k, v := range value {
+			m[k] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return schemaString
+	case int, int64, uint64:
+		return schemaInteger
+	case float64:
+		return schemaNumber
+	case bool:
+		return schemaBoolean
+	case []interface{}:
+		return schemaArray
+	case map[interface{}]interface{}, map[string]interface{}, map[string]string:
+		return schemaObject
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
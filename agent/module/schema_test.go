@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testSchemaInline struct {
+		Timeout time.Duration `yaml:"timeout"`
+	}
+	testSchemaModule struct {
+		Base
+		testSchemaInline `yaml:",inline"`
+		URL              string            `yaml:"url" required:"true"`
+		Port             int               `yaml:"port"`
+		Ratio            float64           `yaml:"ratio"`
+		Enabled          bool              `yaml:"enabled"`
+		Tags             []string          `yaml:"tags"`
+		Headers          map[string]string `yaml:"headers"`
+		Auth             struct {
+			Username string `yaml:"username"`
+		} `yaml:"auth"`
+		Ignored  string `yaml:"-"`
+		Untagged string
+		internal string
+	}
+)
+
+func TestNewSchema(t *testing.T) {
+	expected := &Schema{
+		Type: schemaObject,
+		Properties: map[string]*Schema{
+			"timeout": {},
+			"url":     {Type: schemaString},
+			"port":    {Type: schemaInteger},
+			"ratio":   {Type: schemaNumber},
+			"enabled": {Type: schemaBoolean},
+			"tags":    {Type: schemaArray, Items: &Schema{Type: schemaString}},
+			"headers": {Type: schemaObject, AdditionalProperties: &Schema{Type: schemaString}},
+			"auth": {
+				Type:       schemaObject,
+				Properties: map[string]*Schema{"username": {Type: schemaString}},
+			},
+			"untagged": {Type: schemaString},
+		},
+		Required: []string{"url"},
+	}
+
+	assert.Equal(t, expected, NewSchema(&testSchemaModule{}))
+}
+
+func TestSchema_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg             map[string]interface{}
+		expectedUnknown []string
+		expectedErrs    []string
+	}{
+		"valid": {
+			cfg: map[string]interface{}{
+				"url":     "http://127.0.0.1",
+				"port":    80,
+				"ratio":   1,
+				"enabled": true,
+				"timeout": "1s",
+				"tags":    []interface{}{"a", "b"},
+				"headers": map[interface{}]interface{}{"X-Key": "value"},
+				"auth":    map[interface{}]interface{}{"username": "user"},
+			},
+		},
+		"unknown keys": {
+			cfg: map[string]interface{}{
+				"url":  "http://127.0.0.1",
+				"prot": 80,
+				"auth": map[interface{}]interface{}{"usrname": "user"},
+			},
+			expectedUnknown: []string{"auth.usrname", "prot"},
+		},
+		"wrong types": {
+			cfg: map[string]interface{}{
+				"url":     "http://127.0.0.1",
+				"port":    "80",
+				"ratio":   "1.5",
+				"enabled": "yes",
+				"tags":    "a",
+				"headers": []interface{}{"X-Key"},
+			},
+			expectedErrs: []string{
+				"'enabled': expected boolean, got string",
+				"'headers': expected object, got array",
+				"'port': expected integer, got string",
+				"'ratio': expected number, got string",
+				"'tags': expected array, got string",
+			},
+		},
+		"wrong nested types": {
+			cfg: map[string]interface{}{
+				"url":  "http://127.0.0.1",
+				"port": 1.5,
+				"tags": []interface{}{"a", []interface{}{"b"}},
+			},
+			expectedErrs: []string{
+				"'port': expected integer, got number",
+				"'tags[1]': expected string, got array",
+			},
+		},
+		"missing required": {
+			cfg:          map[string]interface{}{"port": 80},
+			expectedErrs: []string{"'url' is required"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			unknown, errs := NewSchema(&testSchemaModule{}).Validate(test.cfg)
+
+			assert.Equal(t, test.expectedUnknown, unknown)
+			assert.Equal(t, test.expectedErrs, errs)
+		})
+	}
+}
+
+func TestCreator_Schema(t *testing.T) {
+	creator := Creator{Create: func() Module { return &MockModule{} }}
+
+	unknown, errs := creator.Schema().Validate(map[string]interface{}{
+		"name":         "name",
+		"module":       "module",
+		"update_every": 1,
+		"update_evry":  1,
+		"priority":     "high",
+		"labels":       map[interface{}]interface{}{"key": "value"},
+	})
+
+	assert.Equal(t, []string{"update_evry"}, unknown)
+	assert.Equal(t, []string{"'priority': expected integer, got string"}, errs)
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package module
+
+import "github.com/google/uuid"
+
+// VirtualNode is a Netdata virtual node: a monitored device or remote host that is shown as a separate node,
+// and not as a part of the host the plugin is running on.
+type VirtualNode struct {
+	// GUID is the node unique identifier, if not set it is derived from the Hostname.
+	GUID     string            `yaml:"guid"`
+	Hostname string            `yaml:"hostname"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+// ID returns the node GUID.
+func (v VirtualNode) ID() string {
+	if v.GUID != "" {
+		return v.GUID
+	}
+	return uuid.NewSHA1(uuid.NameSpaceDNS, []byte(v.Hostname)).String()
+}
@@ -5,6 +5,7 @@ package netdataapi
 import (
 	"fmt"
 	"io"
+	"sort"
 )
 
 type (
@@ -108,6 +109,50 @@ func (a *API) DISABLE() error {
 	return err
 }
 
+// HOSTDEFINE start defining a virtual node (host).
+func (a *API) HOSTDEFINE(guid, hostname string) error {
+	_, err := fmt.Fprintf(a, "HOST_DEFINE '%s' '%s'\n", guid, hostname)
+	return err
+}
+
+// HOSTLABEL add a label to the virtual node being defined.
+func (a *API) HOSTLABEL(key, value string) error {
+	_, err := fmt.Fprintf(a, "HOST_LABEL '%s' '%s'\n", key, value)
+	return err
+}
+
+// HOSTDEFINEEND complete the virtual node definition. Should be called after HOSTDEFINE and HOSTLABEL.
+func (a *API) HOSTDEFINEEND() error {
+	_, err := fmt.Fprintf(a, "HOST_DEFINE_END\n\n")
+	return err
+}
+
+// HOSTINFO define a virtual node with labels.
+func (a *API) HOSTINFO(guid, hostname string, labels map[string]string) error {
+	if err := a.HOSTDEFINE(guid, hostname); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := a.HOSTLABEL(k, labels[k]); err != nil {
+			return err
+		}
+	}
+
+	return a.HOSTDEFINEEND()
+}
+
+// HOST switch the following commands to the virtual node, the empty guid switches back to the local host.
+func (a *API) HOST(guid string) error {
+	_, err := fmt.Fprintf(a, "HOST '%s'\n\n", guid)
+	return err
+}
+
 // EMPTYLINE write an empty line.
 func (a *API) EMPTYLINE() error {
 	_, err := fmt.Fprintf(a, "\n")
@@ -151,3 +151,29 @@ func TestAPI_FLUSH(t *testing.T) {
 		b.String(),
 	)
 }
+
+func TestAPI_HOSTINFO(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.HOSTINFO("guid", "hostname", map[string]string{"b": "2", "a": "1"})
+
+	assert.Equal(
+		t,
+		"HOST_DEFINE 'guid' 'hostname'\nHOST_LABEL 'a' '1'\nHOST_LABEL 'b' '2'\nHOST_DEFINE_END\n\n",
+		b.String(),
+	)
+}
+
+func TestAPI_HOST(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.HOST("guid")
+
+	assert.Equal(
+		t,
+		"HOST 'guid'\n\n",
+		b.String(),
+	)
+}
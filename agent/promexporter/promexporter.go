@@ -0,0 +1,306 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package promexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const defaultPath = "/metrics"
+
+// Config is the Prometheus exporter configuration. Empty address disables the exporter.
+type Config struct {
+	Address string `yaml:"address"`
+	Path    string `yaml:"path"`
+	// Standalone disables the Netdata plugins.d protocol output.
+	Standalone bool `yaml:"standalone"`
+}
+
+// Exporter exposes the jobs charts as Prometheus metrics. It implements module.MetricsSink.
+type Exporter struct {
+	*logger.Logger
+	address string
+	path    string
+
+	mux  sync.Mutex
+	jobs map[string]*jobMetrics
+}
+
+type (
+	jobMetrics struct {
+		samples []sample
+		// previous collected values, needed for the 'percentage-of-incremental-row' dimensions.
+		prev map[string]int64
+	}
+	sample struct {
+		name   string
+		typ    string
+		help   string
+		labels []label
+		value  float64
+	}
+	label struct {
+		key   string
+		value string
+	}
+)
+
+func New(cfg Config) (*Exporter, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("address not set")
+	}
+	path := cfg.Path
+	if path == "" {
+		path = defaultPath
+	}
+	return &Exporter{
+		Logger:  logger.New("promexporter", "exporter"),
+		address: cfg.Address,
+		path:    path,
+		jobs:    make(map[string]*jobMetrics),
+	}, nil
+}
+
+// Run serves the metrics until the context is canceled.
+func (e *Exporter) Run(ctx context.Context) {
+	e.Info("instance is started")
+	defer func() { e.Info("instance is stopped") }()
+
+	ln, err := net.Listen("tcp", e.address)
+	if err != nil {
+		e.Errorf("listen on '%s': %v", e.address, err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(e.path, e)
+	srv := &http.Server{Handler: mux}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			e.Error(err)
+		}
+	}()
+	e.Infof("serving on '%s%s'", e.address, e.path)
+
+	<-ctx.Done()
+
+	sctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_ = srv.Shutdown(sctx)
+	<-done
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method '%s' not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.writeMetrics(w)
+}
+
+// Update converts the job charts to the Prometheus samples.
+func (e *Exporter) Update(job module.SinkJob, charts module.Charts, metrics map[string]int64) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	jm, ok := e.jobs[job.FullName]
+	if !ok {
+		jm = &jobMetrics{}
+		e.jobs[job.FullName] = jm
+	}
+
+	jm.samples = jm.samples[:0]
+	for _, chart := range charts {
+		jm.samples = append(jm.samples, chartSamples(job, chart, metrics, jm.prev)...)
+	}
+	jm.prev = metrics
+}
+
+// Remove removes the job samples.
+func (e *Exporter) Remove(fullName string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	delete(e.jobs, fullName)
+}
+
+func (e *Exporter) writeMetrics(w io.Writer) {
+	e.mux.Lock()
+	var samples []sample
+	for _, jm := range e.jobs {
+		samples = append(samples, jm.samples...)
+	}
+	e.mux.Unlock()
+
+	// samples of the same metric must be grouped together
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].name < samples[j].name })
+
+	var b strings.Builder
+	var prev string
+	for _, s := range samples {
+		if s.name != prev {
+			prev = s.name
+			fmt.Fprintf(&b, "# HELP %s %s\n", s.name, escapeHelp(s.help))
+			fmt.Fprintf(&b, "# TYPE %s %s\n", s.name, s.typ)
+		}
+		b.WriteString(s.name)
+		b.WriteByte('{')
+		for i, l := range s.labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", l.key, escapeLabelValue(l.value))
+		}
+		b.WriteByte('}')
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		b.WriteByte('\n')
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+func chartSamples(job module.SinkJob, chart *module.Chart, metrics, prev map[string]int64) []sample {
+	ctx := chart.Ctx
+	if ctx == "" {
+		ctx = job.ModuleName + "." + chart.ID
+	}
+	name := sanitizeName(ctx)
+	help := fmt.Sprintf("%s (%s)", chart.Title, chart.Units)
+
+	labels := []label{
+		{key: "job", value: job.FullName},
+		{key: "module", value: job.ModuleName},
+		{key: "chart", value: chart.TypeID()},
+	}
+	for _, l := range chart.Labels {
+		if l.Key == "" || l.Value == "" {
+			continue
+		}
+		key := strings.ReplaceAll(sanitizeName(l.Key), ":", "_")
+		if key == "job" || key == "module" || key == "chart" || key == "dimension" {
+			key = "label_" + key
+		}
+		labels = append(labels, label{key: key, value: l.Value})
+	}
+
+	var absTotal, incTotal float64
+	for _, dim := range chart.Dims {
+		v, ok := metrics[dim.ID]
+		if !ok {
+			continue
+		}
+		switch dim.Algo {
+		case module.PercentOfAbsolute:
+			absTotal += float64(v)
+		case module.PercentOfIncremental:
+			if p, ok := prev[dim.ID]; ok {
+				incTotal += float64(v - p)
+			}
+		}
+	}
+
+	var samples []sample
+	for _, dim := range chart.Dims {
+		v, ok := metrics[dim.ID]
+		if !ok {
+			continue
+		}
+
+		s := sample{
+			name:   name,
+			typ:    "gauge",
+			help:   help,
+			labels: append(append([]label{}, labels...), label{key: "dimension", value: firstNotEmpty(dim.Name, dim.ID)}),
+		}
+
+		switch dim.Algo {
+		case module.Incremental:
+			s.name += "_total"
+			s.typ = "counter"
+			s.value = scale(v, dim)
+		case module.PercentOfAbsolute:
+			s.value = percent(float64(v), absTotal)
+		case module.PercentOfIncremental:
+			p, ok := prev[dim.ID]
+			if !ok {
+				continue
+			}
+			s.value = percent(float64(v-p), incTotal)
+		default:
+			s.value = scale(v, dim)
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+func scale(v int64, dim *module.Dim) float64 {
+	mul, div := dim.Mul, dim.Div
+	if mul == 0 {
+		mul = 1
+	}
+	if div == 0 {
+		div = 1
+	}
+	return float64(v) * float64(mul) / float64(div)
+}
+
+func percent(v, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return v * 100 / total
+}
+
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpReplacer.Replace(s) }
+func escapeLabelValue(s string) string { return labelReplacer.Replace(s) }
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
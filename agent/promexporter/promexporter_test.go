@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package promexporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+
+	exp, err := New(Config{Address: "127.0.0.1:9188"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPath, exp.path)
+}
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	job := module.SinkJob{FullName: "example_local", ModuleName: "example", UpdateEvery: 1}
+	charts := module.Charts{
+		{
+			ID: "requests", Title: "Requests", Units: "requests/s", Ctx: "example.requests",
+			Labels: []module.Label{{Key: "url", Value: "http://127.0.0.1"}},
+			Dims: module.Dims{
+				{ID: "requests", Algo: module.Incremental},
+			},
+		},
+		{
+			ID: "memory", Title: "Memory", Units: "KiB", Ctx: "example.memory",
+			Dims: module.Dims{
+				{ID: "used", Div: 1024},
+				{ID: "not_collected"},
+			},
+		},
+		{
+			ID: "ratio", Title: "Ratio", Units: "percentage", Ctx: "example.ratio",
+			Dims: module.Dims{
+				{ID: "hit", Algo: module.PercentOfAbsolute},
+				{ID: "miss", Algo: module.PercentOfAbsolute},
+			},
+		},
+		{
+			ID: "inc_ratio", Title: "Incremental ratio", Units: "percentage", Ctx: "example.inc_ratio",
+			Dims: module.Dims{
+				{ID: "inc_hit", Name: "hit", Algo: module.PercentOfIncremental},
+				{ID: "inc_miss", Name: "miss", Algo: module.PercentOfIncremental},
+			},
+		},
+	}
+
+	exp, err := New(Config{Address: "127.0.0.1:9188"})
+	require.NoError(t, err)
+
+	exp.Update(job, charts, map[string]int64{
+		"requests": 10, "used": 2048, "hit": 1, "miss": 3, "inc_hit": 10, "inc_miss": 10,
+	})
+	exp.Update(job, charts, map[string]int64{
+		"requests": 20, "used": 4096, "hit": 3, "miss": 1, "inc_hit": 13, "inc_miss": 11,
+	})
+
+	expected := `# HELP example_inc_ratio Incremental ratio (percentage)
+# TYPE example_inc_ratio gauge
+example_inc_ratio{job="example_local",module="example",chart="inc_ratio",dimension="hit"} 75
+example_inc_ratio{job="example_local",module="example",chart="inc_ratio",dimension="miss"} 25
+# HELP example_memory Memory (KiB)
+# TYPE example_memory gauge
+example_memory{job="example_local",module="example",chart="memory",dimension="used"} 4
+# HELP example_ratio Ratio (percentage)
+# TYPE example_ratio gauge
+example_ratio{job="example_local",module="example",chart="ratio",dimension="hit"} 75
+example_ratio{job="example_local",module="example",chart="ratio",dimension="miss"} 25
+# HELP example_requests_total Requests (requests/s)
+# TYPE example_requests_total counter
+example_requests_total{job="example_local",module="example",chart="requests",url="http://127.0.0.1",dimension="requests"} 20
+`
+	assert.Equal(t, expected, scrape(t, exp))
+
+	exp.Remove(job.FullName)
+	assert.Empty(t, scrape(t, exp))
+}
+
+func scrape(t *testing.T, exp *Exporter) string {
+	w := httptest.NewRecorder()
+	exp.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	bs, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(bs)
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package agent
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/netdata/go.d.plugin/agent/job/build"
+	"github.com/netdata/go.d.plugin/agent/job/confgroup"
+	"github.com/netdata/go.d.plugin/agent/job/discovery"
+)
+
+// confCheckEvery is the plugin config file modification check interval.
+const confCheckEvery = time.Second * 5
+
+// discoveryRunner runs the discovery manager, the manager is replaced on the configuration reload.
+// The builder keeps the received config groups, so only the jobs which configs changed are stopped/started.
+type discoveryRunner struct {
+	mux    sync.Mutex
+	cfg    discovery.Config
+	mgr    *discovery.Manager
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *discoveryRunner) start(ctx context.Context, cfg discovery.Config, mgr *discovery.Manager, in chan []*confgroup.Group) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	r.mux.Lock()
+	r.cfg, r.mgr, r.cancel, r.done = cfg, mgr, cancel, done
+	r.mux.Unlock()
+
+	go func() { defer close(done); mgr.Run(ctx, in) }()
+}
+
+func (r *discoveryRunner) stop() {
+	r.mux.Lock()
+	cancel, done := r.cancel, r.done
+	r.mux.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (r *discoveryRunner) config() discovery.Config {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.cfg
+}
+
+// Stats implements selfmon.DiscoveryStater.
+func (r *discoveryRunner) Stats() (groups, configs int) {
+	r.mux.Lock()
+	mgr := r.mgr
+	r.mux.Unlock()
+
+	if mgr == nil {
+		return 0, 0
+	}
+	return mgr.Stats()
+}
+
+// reload applies the new configuration without stopping the running instance.
+// It returns false if the changes require the instance restart.
+func (a *Agent) reload(ctx context.Context, prev config, r *discoveryRunner, in chan []*confgroup.Group, builder *build.Manager) (config, bool) {
+	cfg := a.loadPluginConfig()
+	if !prev.reloadable(cfg) {
+		a.Info("the plugin settings have changed, restarting the instance")
+		return cfg, false
+	}
+	a.applyLogFormat(cfg)
+
+	enabled := a.loadEnabledModules(cfg)
+	if len(enabled) == 0 {
+		a.Info("no modules to run, restarting the instance")
+		return cfg, false
+	}
+
+	discCfg := a.buildDiscoveryConf(cfg, enabled)
+	if !reflect.DeepEqual(discCfg.SD, r.config().SD) {
+		a.Info("the service discovery pipelines have changed, restarting the instance")
+		return cfg, false
+	}
+
+	mgr, err := discovery.NewManager(discCfg)
+	if err != nil {
+		a.Errorf("couldn't reload the configuration: %v", err)
+		return prev, true
+	}
+
+	r.stop()
+	builder.SetModules(ctx, enabled)
+
+	// the discovery re-reads all the config groups, except the ones which sources are gone
+	var groups []*confgroup.Group
+	for _, source := range staleSources(r.config(), discCfg) {
+		groups = append(groups, &confgroup.Group{Source: source})
+	}
+	if len(groups) > 0 {
+		select {
+		case <-ctx.Done():
+		case in <- groups:
+		}
+	}
+
+	r.start(ctx, discCfg, mgr, in)
+	a.Infof("configuration reloaded, using config: %s", cfg)
+	return cfg, true
+}
+
+// staleSources returns the file and dummy config group sources that the new discovery config doesn't have.
+func staleSources(prev, cur discovery.Config) []string {
+	seen := make(map[string]bool)
+	for _, v := range cur.File.Read {
+		seen[v] = true
+	}
+	for _, v := range cur.Dummy.Names {
+		seen[v] = true
+	}
+
+	var sources []string
+	for _, v := range append(append([]string{}, prev.File.Read...), prev.Dummy.Names...) {
+		if !seen[v] {
+			sources = append(sources, v)
+		}
+	}
+	return sources
+}
+
+// reloadable returns whether the new config changes only the settings that can be applied without the restart:
+// the modules, 'default_run' and 'log_format'.
+func (c config) reloadable(other config) bool {
+	c.DefaultRun, c.Modules, c.LogFormat = false, nil, ""
+	other.DefaultRun, other.Modules, other.LogFormat = false, nil, ""
+	return reflect.DeepEqual(c, other)
+}
+
+// watchPluginConfig notifies about the plugin config file modifications.
+func (a *Agent) watchPluginConfig(ctx context.Context, reload chan<- struct{}) {
+	if len(a.ConfDir) == 0 {
+		return
+	}
+
+	tk := time.NewTicker(confCheckEvery)
+	defer tk.Stop()
+
+	prev := a.pluginConfigStat()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+			cur := a.pluginConfigStat()
+			if cur.equal(prev) {
+				continue
+			}
+			prev = cur
+			a.Info("the config file has changed, reloading the configuration")
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+type fileStat struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (s fileStat) equal(other fileStat) bool {
+	return s.path == other.path && s.size == other.size && s.modTime.Equal(other.modTime)
+}
+
+func (a *Agent) pluginConfigStat() fileStat {
+	path, err := a.ConfDir.Find(a.Name + ".conf")
+	if err != nil || path == "" {
+		return fileStat{}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStat{path: path}
+	}
+	return fileStat{path: path, size: fi.Size(), modTime: fi.ModTime()}
+}
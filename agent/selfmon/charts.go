@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package selfmon
+
+import (
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+const prioSelfMon = 145100
+
+func newCharts(pluginName, ctxPrefix string) *module.Charts {
+	charts := module.Charts{
+		{
+			ID:    "jobs_states",
+			Title: "Jobs by state",
+			Units: "jobs",
+			Fam:   "jobs",
+			Ctx:   ctxPrefix + "jobs_states",
+			Type:  module.Stacked,
+		},
+		{
+			ID:    "discovery",
+			Title: "Discovered config groups and configs",
+			Units: "items",
+			Fam:   "discovery",
+			Ctx:   ctxPrefix + "discovery",
+			Dims: module.Dims{
+				{ID: "discovery_groups", Name: "groups"},
+				{ID: "discovery_configs", Name: "configs"},
+			},
+		},
+		{
+			ID:    "jobs_overruns",
+			Title: "Jobs skipped ticks and recovered panics",
+			Units: "events/s",
+			Fam:   "jobs",
+			Ctx:   ctxPrefix + "jobs_overruns",
+			Dims: module.Dims{
+				{ID: "jobs_skipped_ticks", Name: "skipped_ticks", Algo: module.Incremental},
+				{ID: "jobs_panics", Name: "panics", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "jobs_collection",
+			Title: "Jobs with failing data collection",
+			Units: "jobs",
+			Fam:   "jobs",
+			Ctx:   ctxPrefix + "jobs_collection",
+			Dims: module.Dims{
+				{ID: "jobs_collection_failing", Name: "failing"},
+			},
+		},
+		{
+			ID:    "log_messages",
+			Title: "Log messages",
+			Units: "messages/s",
+			Fam:   "logger",
+			Ctx:   ctxPrefix + "log_messages",
+			Type:  module.Stacked,
+			Dims: module.Dims{
+				{ID: "log_critical", Name: "critical", Algo: module.Incremental},
+				{ID: "log_error", Name: "error", Algo: module.Incremental},
+				{ID: "log_warning", Name: "warning", Algo: module.Incremental},
+				{ID: "log_info", Name: "info", Algo: module.Incremental},
+				{ID: "log_debug", Name: "debug", Algo: module.Incremental},
+				{ID: "log_suppressed", Name: "suppressed", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "goroutines",
+			Title: "Goroutines",
+			Units: "goroutines",
+			Fam:   "runtime",
+			Ctx:   ctxPrefix + "goroutines",
+			Dims: module.Dims{
+				{ID: "goroutines"},
+			},
+		},
+		{
+			ID:    "memory",
+			Title: "Memory",
+			Units: "KiB",
+			Fam:   "runtime",
+			Ctx:   ctxPrefix + "memory",
+			Dims: module.Dims{
+				{ID: "mem_heap_alloc", Name: "heap_alloc", Div: 1024},
+				{ID: "mem_heap_inuse", Name: "heap_inuse", Div: 1024},
+				{ID: "mem_sys", Name: "sys", Div: 1024},
+			},
+		},
+		{
+			ID:    "gc",
+			Title: "Garbage collector",
+			Units: "events/s",
+			Fam:   "runtime",
+			Ctx:   ctxPrefix + "gc",
+			Dims: module.Dims{
+				{ID: "gc_num", Name: "collections", Algo: module.Incremental},
+			},
+		},
+		{
+			ID:    "gc_pause",
+			Title: "Garbage collector pause time",
+			Units: "ms",
+			Fam:   "runtime",
+			Ctx:   ctxPrefix + "gc_pause",
+			Dims: module.Dims{
+				{ID: "gc_pause_total_ns", Name: "pause", Algo: module.Incremental, Div: 1e6},
+			},
+		},
+	}
+	for i, chart := range charts {
+		chart.Fam = fmt.Sprintf("%s %s", pluginName, chart.Fam)
+		chart.Priority = prioSelfMon + i
+	}
+	return &charts
+}
+
+func newJobsStateDim(state string) *module.Dim {
+	return &module.Dim{ID: "jobs_state_" + state, Name: state}
+}
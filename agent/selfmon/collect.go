@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package selfmon
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+func (s *SelfMon) collect() map[string]int64 {
+	mx := make(map[string]int64)
+
+	s.collectJobs(mx)
+	s.collectDiscovery(mx)
+	collectLogger(mx)
+	collectRuntime(mx)
+
+	return mx
+}
+
+func (s *SelfMon) collectJobs(mx map[string]int64) {
+	chart := s.charts.Get("jobs_states")
+
+	var skipped, panics, failing int64
+	for _, st := range s.jobs.JobStatuses() {
+		if !chart.HasDim("jobs_state_" + st.State) {
+			if err := chart.AddDim(newJobsStateDim(st.State)); err != nil {
+				s.Warning(err)
+			}
+			chart.MarkNotCreated()
+		}
+		mx["jobs_state_"+st.State]++
+		skipped += int64(st.SkippedTicks)
+		panics += int64(st.Panics)
+		if st.CollectionFailing {
+			failing++
+		}
+	}
+	// states no job is in at the moment
+	for _, dim := range chart.Dims {
+		if _, ok := mx[dim.ID]; !ok {
+			mx[dim.ID] = 0
+		}
+	}
+
+	mx["jobs_skipped_ticks"] = skipped
+	mx["jobs_panics"] = panics
+	mx["jobs_collection_failing"] = failing
+}
+
+func (s *SelfMon) collectDiscovery(mx map[string]int64) {
+	if s.discovery == nil {
+		return
+	}
+	groups, configs := s.discovery.Stats()
+	mx["discovery_groups"] = int64(groups)
+	mx["discovery_configs"] = int64(configs)
+}
+
+func collectLogger(mx map[string]int64) {
+	stats := logger.GlobalMsgCountWatcher.Stats()
+	for severity, n := range stats.Logged {
+		mx["log_"+strings.ToLower(severity.String())] = n
+	}
+	mx["log_suppressed"] = stats.Suppressed
+}
+
+func collectRuntime(mx map[string]int64) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	mx["goroutines"] = int64(runtime.NumGoroutine())
+	mx["mem_heap_alloc"] = int64(ms.HeapAlloc)
+	mx["mem_heap_inuse"] = int64(ms.HeapInuse)
+	mx["mem_sys"] = int64(ms.Sys)
+	mx["gc_num"] = int64(ms.NumGC)
+	mx["gc_pause_total_ns"] = int64(ms.PauseTotalNs)
+}
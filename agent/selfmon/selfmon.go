@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package selfmon
+
+import (
+	"regexp"
+
+	"github.com/netdata/go.d.plugin/agent/job/build"
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+// ModuleName is the self-monitoring job module name.
+const ModuleName = "internal"
+
+type (
+	// JobsStatuser is implemented by build.Manager.
+	JobsStatuser interface {
+		JobStatuses() []build.JobStatus
+	}
+	// DiscoveryStater is implemented by discovery.Manager.
+	DiscoveryStater interface {
+		Stats() (groups, configs int)
+	}
+)
+
+// Config is the self-monitoring job configuration.
+type Config struct {
+	PluginName string
+	Jobs       JobsStatuser
+	Discovery  DiscoveryStater
+}
+
+// SelfMon is a module that collects the plugin (orchestrator) metrics.
+type SelfMon struct {
+	module.Base
+
+	jobs      JobsStatuser
+	discovery DiscoveryStater
+
+	charts *module.Charts
+}
+
+func New(cfg Config) *SelfMon {
+	return &SelfMon{
+		jobs:      cfg.Jobs,
+		discovery: cfg.Discovery,
+		charts:    newCharts(cfg.PluginName, ctxPrefix(cfg.PluginName)),
+	}
+}
+
+// FullName returns the self-monitoring job full name for the plugin.
+func FullName(pluginName string) string {
+	return ctxName(pluginName) + "_" + ModuleName
+}
+
+func (s *SelfMon) Init() bool {
+	if s.jobs == nil {
+		s.Error("jobs statuser not set")
+		return false
+	}
+	return true
+}
+
+func (s *SelfMon) Check() bool {
+	return len(s.Collect()) > 0
+}
+
+func (s *SelfMon) Charts() *module.Charts {
+	return s.charts
+}
+
+func (s *SelfMon) Collect() map[string]int64 {
+	mx := s.collect()
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
+
+func (SelfMon) Cleanup() {}
+
+var reSpace = regexp.MustCompile(`\s+`)
+
+// ctxName follows the module.Job runtime chart naming.
+func ctxName(pluginName string) string {
+	name := pluginName
+	if name == "go.d" || name == "" {
+		name = "go"
+	}
+	return reSpace.ReplaceAllString(name, "_")
+}
+
+func ctxPrefix(pluginName string) string {
+	return "netdata." + ctxName(pluginName) + "_plugin_"
+}
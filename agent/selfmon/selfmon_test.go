@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package selfmon
+
+import (
+	"testing"
+
+	"github.com/netdata/go.d.plugin/agent/job/build"
+	"github.com/netdata/go.d.plugin/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullName(t *testing.T) {
+	assert.Equal(t, "go_internal", FullName("go.d"))
+	assert.Equal(t, "my_plugin_internal", FullName("my plugin"))
+}
+
+func TestSelfMon_Init(t *testing.T) {
+	assert.False(t, New(Config{}).Init())
+	assert.True(t, New(Config{Jobs: &mockJobs{}}).Init())
+}
+
+func TestSelfMon_Charts(t *testing.T) {
+	charts := New(Config{PluginName: "go.d"}).Charts()
+
+	require.NotNil(t, charts)
+	assert.NoError(t, charts.Check())
+	assert.Equal(t, "netdata.go_plugin_goroutines", charts.Get("goroutines").Ctx)
+}
+
+func TestSelfMon_Collect(t *testing.T) {
+	jobs := &mockJobs{statuses: []build.JobStatus{
+		{FullName: "job1", State: "success", SkippedTicks: 2, Panics: 1},
+		{FullName: "job2", State: "success", SkippedTicks: 1, CollectionFailing: true},
+		{FullName: "job3", State: "failed"},
+	}}
+	s := New(Config{PluginName: "go.d", Jobs: jobs, Discovery: mockDiscovery{groups: 2, configs: 5}})
+	s.Logger = logger.New("", "")
+	require.True(t, s.Init())
+
+	mx := s.Collect()
+
+	assert.Equal(t, int64(2), mx["jobs_state_success"])
+	assert.Equal(t, int64(1), mx["jobs_state_failed"])
+	assert.Equal(t, int64(3), mx["jobs_skipped_ticks"])
+	assert.Equal(t, int64(1), mx["jobs_panics"])
+	assert.Equal(t, int64(1), mx["jobs_collection_failing"])
+	assert.Equal(t, int64(2), mx["discovery_groups"])
+	assert.Equal(t, int64(5), mx["discovery_configs"])
+	assert.NotZero(t, mx["goroutines"])
+	assert.NotZero(t, mx["mem_sys"])
+	for _, key := range []string{"log_critical", "log_error", "log_warning", "log_info", "log_debug", "log_suppressed"} {
+		assert.Contains(t, mx, key)
+	}
+	assert.True(t, s.Charts().Get("jobs_states").HasDim("jobs_state_success"))
+	assert.True(t, s.Charts().Get("jobs_states").HasDim("jobs_state_failed"))
+
+	jobs.statuses = jobs.statuses[:1]
+	mx = s.Collect()
+
+	assert.Equal(t, int64(1), mx["jobs_state_success"])
+	assert.Equal(t, int64(0), mx["jobs_state_failed"])
+}
+
+type mockJobs struct {
+	statuses []build.JobStatus
+}
+
+func (m mockJobs) JobStatuses() []build.JobStatus { return m.statuses }
+
+type mockDiscovery struct {
+	groups, configs int
+}
+
+func (m mockDiscovery) Stats() (int, int) { return m.groups, m.configs }
@@ -6,31 +6,50 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/netdata/go.d.plugin/agent/httpapi"
 	"github.com/netdata/go.d.plugin/agent/job/confgroup"
 	"github.com/netdata/go.d.plugin/agent/job/discovery"
 	"github.com/netdata/go.d.plugin/agent/job/discovery/dummy"
 	"github.com/netdata/go.d.plugin/agent/job/discovery/file"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/pipeline"
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/agent/promexporter"
+	"github.com/netdata/go.d.plugin/logger"
 
 	"gopkg.in/yaml.v2"
 )
 
 func defaultConfig() config {
 	return config{
-		Enabled:    true,
-		DefaultRun: true,
-		MaxProcs:   0,
-		Modules:    nil,
+		Enabled:           true,
+		DefaultRun:        true,
+		MaxProcs:          0,
+		SpreadCollections: true,
+		Modules:           nil,
 	}
 }
 
 type config struct {
-	Enabled    bool            `yaml:"enabled"`
-	DefaultRun bool            `yaml:"default_run"`
-	MaxProcs   int             `yaml:"max_procs"`
-	Modules    map[string]bool `yaml:"modules"`
+	Enabled                  bool                `yaml:"enabled"`
+	DefaultRun               bool                `yaml:"default_run"`
+	MaxProcs                 int                 `yaml:"max_procs"`
+	SpreadCollections        bool                `yaml:"spread_collections"`
+	MaxConcurrentCollections int                 `yaml:"max_concurrent_collections"`
+	Modules                  map[string]bool     `yaml:"modules"`
+	Discovery                discoveryConfig     `yaml:"discovery"`
+	HTTPAPI                  httpapi.Config      `yaml:"http_api"`
+	Exporter                 promexporter.Config `yaml:"prometheus_exporter"`
+	SelfMon                  bool                `yaml:"self_monitoring"`
+	LogFormat                string              `yaml:"log_format"`
+}
+
+type discoveryConfig struct {
+	// Files are service discovery pipeline config files, relative paths are looked up in the modules config dirs.
+	Files     []string          `yaml:"files"`
+	Pipelines []pipeline.Config `yaml:"pipelines"`
 }
 
 func (c config) String() string {
@@ -65,6 +84,23 @@ func (a *Agent) loadPluginConfig() config {
 	return cfg
 }
 
+// applyLogFormat sets the log format from the config file unless it is set from the command line.
+func (a *Agent) applyLogFormat(cfg config) {
+	if a.LogFormat != "" {
+		return
+	}
+	format := logger.FormatText
+	if cfg.LogFormat != "" {
+		v, err := logger.ParseFormat(cfg.LogFormat)
+		if err != nil {
+			a.Warningf("%v, will use '%s'", err, format)
+		} else {
+			format = v
+		}
+	}
+	logger.SetFormat(format)
+}
+
 func (a *Agent) loadEnabledModules(cfg config) module.Registry {
 	a.Info("loading modules")
 
@@ -89,7 +125,7 @@ func (a *Agent) loadEnabledModules(cfg config) module.Registry {
 	return enabled
 }
 
-func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
+func (a *Agent) buildDiscoveryConf(cfg config, enabled module.Registry) discovery.Config {
 	a.Info("building discovery config")
 
 	reg := confgroup.Registry{}
@@ -102,11 +138,13 @@ func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 		})
 	}
 
+	sd := a.loadSDConfigs(cfg.Discovery)
+
 	var readPaths, dummyPaths []string
 
 	if len(a.ModulesConfDir) == 0 {
 		if isInsideK8sCluster() {
-			return discovery.Config{Registry: reg}
+			return discovery.Config{Registry: reg, SD: sd}
 		}
 		a.Info("modules conf dir not provided, will use default config for all enabled modules")
 		for name := range enabled {
@@ -115,6 +153,7 @@ func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 		return discovery.Config{
 			Registry: reg,
 			Dummy:    dummy.Config{Names: dummyPaths},
+			SD:       sd,
 		}
 	}
 
@@ -141,7 +180,8 @@ func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 		}
 	}
 
-	a.Infof("dummy/read/watch paths: %d/%d/%d", len(dummyPaths), len(readPaths), len(a.ModulesSDConfPath))
+	a.Infof("dummy/read/watch paths: %d/%d/%d, sd pipelines: %d",
+		len(dummyPaths), len(readPaths), len(a.ModulesSDConfPath), len(sd))
 	return discovery.Config{
 		Registry: reg,
 		File: file.Config{
@@ -151,7 +191,34 @@ func (a *Agent) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 		Dummy: dummy.Config{
 			Names: dummyPaths,
 		},
+		SD: sd,
+	}
+}
+
+func (a *Agent) loadSDConfigs(cfg discoveryConfig) []pipeline.Config {
+	sd := append([]pipeline.Config{}, cfg.Pipelines...)
+
+	for _, path := range cfg.Files {
+		if !filepath.IsAbs(path) {
+			found, err := a.ModulesConfDir.Find(path)
+			if err != nil {
+				a.Warningf("couldn't find sd pipeline config '%s' in %v, skipping it", path, a.ModulesConfDir)
+				continue
+			}
+			path = found
+		}
+
+		var sdCfg pipeline.Config
+		if err := loadYAML(&sdCfg, path); err != nil {
+			a.Warningf("couldn't load sd pipeline config '%s': %v, skipping it", path, err)
+			continue
+		}
+		if sdCfg.Name == "" {
+			sdCfg.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		sd = append(sd, sdCfg)
 	}
+	return sd
 }
 
 func (c config) isExplicitlyEnabled(moduleName string) bool {
@@ -185,7 +252,8 @@ func (c *config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 	for key, value := range m {
 		switch key {
-		case "enabled", "default_run", "max_procs", "modules":
+		case "enabled", "default_run", "max_procs", "spread_collections", "max_concurrent_collections",
+			"modules", "discovery", "http_api", "prometheus_exporter", "self_monitoring", "log_format":
 			continue
 		}
 		var b bool
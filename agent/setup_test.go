@@ -5,7 +5,11 @@ package agent
 import (
 	"testing"
 
+	"github.com/netdata/go.d.plugin/agent/httpapi"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/kubernetes"
+	"github.com/netdata/go.d.plugin/agent/job/discovery/pipeline"
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/agent/promexporter"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +32,56 @@ func TestConfig_UnmarshalYAML(t *testing.T) {
 				},
 			},
 		},
+		"valid configuration with discovery section": {
+			input: "enabled: yes\ndefault_run: yes\ndiscovery:\n  files:\n    - sd/net_listeners.conf\n  pipelines:\n    - name: k8s\n      discover:\n        kubernetes:\n          - role: pod\n            local_mode: yes",
+			wantCfg: config{
+				Enabled:    true,
+				DefaultRun: true,
+				Discovery: discoveryConfig{
+					Files: []string{"sd/net_listeners.conf"},
+					Pipelines: []pipeline.Config{
+						{
+							Name: "k8s",
+							Discover: pipeline.DiscoveryConfig{
+								K8s: []kubernetes.Config{{Role: "pod", LocalMode: true}},
+							},
+						},
+					},
+				},
+			},
+		},
+		"valid configuration with http api section": {
+			input: "enabled: yes\ndefault_run: yes\nhttp_api:\n  address: unix:///run/go.d.sock",
+			wantCfg: config{
+				Enabled:    true,
+				DefaultRun: true,
+				HTTPAPI:    httpapi.Config{Address: "unix:///run/go.d.sock"},
+			},
+		},
+		"valid configuration with plugin settings": {
+			input: "enabled: yes\ndefault_run: yes\nself_monitoring: yes\nlog_format: json\nprometheus_exporter:\n  address: 127.0.0.1:9100\nmodules:\n  module1: yes",
+			wantCfg: config{
+				Enabled:    true,
+				DefaultRun: true,
+				SelfMon:    true,
+				LogFormat:  "json",
+				Exporter:   promexporter.Config{Address: "127.0.0.1:9100"},
+				Modules: map[string]bool{
+					"module1": true,
+				},
+			},
+		},
+		"valid configuration with plugin settings and broken modules section": {
+			input: "enabled: yes\ndefault_run: yes\nself_monitoring: yes\nspread_collections: no\nmodules:\nmodule1: yes",
+			wantCfg: config{
+				Enabled:    true,
+				DefaultRun: true,
+				SelfMon:    true,
+				Modules: map[string]bool{
+					"module1": true,
+				},
+			},
+		},
 		"valid configuration with broken modules section": {
 			input: "enabled: yes\ndefault_run: yes\nmodules:\nmodule1: yes\nmodule2: yes",
 			wantCfg: config{
@@ -62,9 +116,11 @@ func TestAgent_loadConfig(t *testing.T) {
 				ConfDir: []string{"testdata"},
 			},
 			wantCfg: config{
-				Enabled:    true,
-				DefaultRun: true,
-				MaxProcs:   1,
+				Enabled:                  true,
+				DefaultRun:               true,
+				MaxProcs:                 1,
+				SpreadCollections:        true,
+				MaxConcurrentCollections: 10,
 				Modules: map[string]bool{
 					"module1": true,
 					"module2": true,
@@ -207,3 +263,19 @@ func TestAgent_loadEnabledModules(t *testing.T) {
 func TestAgent_buildDiscoveryConf(t *testing.T) {
 
 }
+
+func TestAgent_loadSDConfigs(t *testing.T) {
+	a := New(Config{Name: "test", ModulesConfDir: []string{"../config/go.d"}})
+
+	cfgs := a.loadSDConfigs(discoveryConfig{
+		Files:     []string{"sd/net_listeners.conf", "sd/not_exists.conf"},
+		Pipelines: []pipeline.Config{{Name: "inline"}},
+	})
+
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, "inline", cfgs[0].Name)
+	assert.Equal(t, "net_listeners", cfgs[1].Name)
+	assert.Len(t, cfgs[1].Discover.NetListeners, 1)
+	assert.NotEmpty(t, cfgs[1].Classify)
+	assert.NotEmpty(t, cfgs[1].Compose)
+}
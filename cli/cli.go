@@ -15,6 +15,10 @@ type Option struct {
 	ConfDir     []string `short:"c" long:"config-dir" description:"config dir to read"`
 	WatchPath   []string `short:"w" long:"watch-path" description:"config path to watch"`
 	Debug       bool     `short:"d" long:"debug" description:"debug mode"`
+	LogFormat   string   `long:"log-format" description:"log output format (text, json, logfmt), overrides the config file"`
+	CheckConfig string   `long:"check-config" description:"run module config file jobs once, print charts and collected metrics and exit ('-' for stdin)"`
+	CheckJob    string   `long:"check-job" description:"job name to run in the check config mode (default: all jobs)"`
+	DumpSchema  string   `long:"dump-schema" description:"print the module config file JSON schema and exit"`
 	Version     bool     `short:"v" long:"version" description:"display the version and exit"`
 }
 
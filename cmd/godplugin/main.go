@@ -3,6 +3,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/user"
@@ -10,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/netdata/go.d.plugin/agent"
+	"github.com/netdata/go.d.plugin/agent/checkconfig"
+	"github.com/netdata/go.d.plugin/agent/module"
 	"github.com/netdata/go.d.plugin/cli"
 	"github.com/netdata/go.d.plugin/logger"
 	"github.com/netdata/go.d.plugin/pkg/multipath"
@@ -99,6 +102,23 @@ func main() {
 		logger.SetSeverity(logger.DEBUG)
 	}
 
+	if opts.LogFormat != "" {
+		format, err := logger.ParseFormat(opts.LogFormat)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		logger.SetFormat(format)
+	}
+
+	if opts.DumpSchema != "" {
+		os.Exit(dumpSchema(opts.DumpSchema))
+	}
+
+	if opts.CheckConfig != "" {
+		os.Exit(checkConfig(opts))
+	}
+
 	a := agent.New(agent.Config{
 		Name:              name,
 		ConfDir:           confDir(opts),
@@ -108,6 +128,7 @@ func main() {
 		LockDir:           lockDir,
 		RunModule:         opts.Module,
 		MinUpdateEvery:    opts.UpdateEvery,
+		LogFormat:         opts.LogFormat,
 	})
 
 	a.Debugf("plugin: name=%s, version=%s", a.Name, version)
@@ -118,6 +139,42 @@ func main() {
 	a.Run()
 }
 
+func checkConfig(opts *cli.Option) int {
+	logger.Prefix = name
+
+	var moduleName string
+	if opts.Module != "all" {
+		moduleName = opts.Module
+	}
+
+	checker := checkconfig.New(checkconfig.Config{
+		Path:       opts.CheckConfig,
+		ModuleName: moduleName,
+		JobName:    opts.CheckJob,
+	})
+	if err := checker.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "check config: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func dumpSchema(moduleName string) int {
+	creator, ok := module.DefaultRegistry[moduleName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "dump schema: unknown module '%s'\n", moduleName)
+		return 1
+	}
+
+	bs, err := json.MarshalIndent(creator.ConfigFileSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "dump schema: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(bs))
+	return 0
+}
+
 func parseCLI() *cli.Option {
 	opt, err := cli.Parse(os.Args)
 	if err != nil {
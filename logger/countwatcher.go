@@ -3,6 +3,7 @@
 package logger
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,6 +35,15 @@ type MsgCountWatcher struct {
 
 	mux   sync.Mutex
 	items map[int64]*Logger
+
+	logged     [DEBUG + 1]int64
+	suppressed int64
+}
+
+// MsgCountStats is the number of logged messages per severity and the number of suppressed (rate limited) messages.
+type MsgCountStats struct {
+	Logged     map[Severity]int64
+	Suppressed int64
 }
 
 // Register adds logger to the collection.
@@ -52,6 +62,28 @@ func (m *MsgCountWatcher) Unregister(logger *Logger) {
 	delete(m.items, logger.id)
 }
 
+// Stats returns the total number of logged and suppressed messages.
+func (m *MsgCountWatcher) Stats() MsgCountStats {
+	stats := MsgCountStats{
+		Logged:     make(map[Severity]int64),
+		Suppressed: atomic.LoadInt64(&m.suppressed),
+	}
+	for i := range m.logged {
+		stats.Logged[Severity(i)] = atomic.LoadInt64(&m.logged[i])
+	}
+	return stats
+}
+
+func (m *MsgCountWatcher) countLogged(severity Severity) {
+	if severity >= 0 && int(severity) < len(m.logged) {
+		atomic.AddInt64(&m.logged[severity], 1)
+	}
+}
+
+func (m *MsgCountWatcher) countSuppressed() {
+	atomic.AddInt64(&m.suppressed, 1)
+}
+
 func (m *MsgCountWatcher) start() {
 LOOP:
 	for {
@@ -74,6 +106,10 @@ func (m *MsgCountWatcher) resetCount() {
 	defer m.mux.Unlock()
 
 	for _, v := range m.items {
-		atomic.StoreInt64(&v.msgCount, 0)
+		if n := atomic.SwapInt64(&v.msgCount, 0); n > msgPerSecondLimit && v.formatter != nil {
+			suppressed := n - msgPerSecondLimit
+			v.formatter.OutputSuppressed(v.modName, v.jobName, 2, suppressed,
+				fmt.Sprintf("messages suppressed: %d (limit %d per second)", suppressed, msgPerSecondLimit))
+		}
 	}
 }
@@ -3,6 +3,8 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"sync/atomic"
 	"testing"
@@ -57,3 +59,91 @@ func TestMsgCountWatcher(t *testing.T) {
 		assert.Equal(t, int64(0), atomic.LoadInt64(&logger.msgCount))
 	}
 }
+
+func TestMsgCountWatcher_Stats(t *testing.T) {
+	before := GlobalMsgCountWatcher.Stats()
+
+	logger := NewLimited("", "")
+	defer GlobalMsgCountWatcher.Unregister(logger)
+	logger.formatter.SetOutput(ioutil.Discard)
+
+	logger.Error()
+	for i := 0; i < msgPerSecondLimit; i++ {
+		logger.Info()
+	}
+
+	after := GlobalMsgCountWatcher.Stats()
+	assert.Equal(t, int64(1), after.Logged[ERROR]-before.Logged[ERROR])
+	assert.Equal(t, int64(msgPerSecondLimit-1), after.Logged[INFO]-before.Logged[INFO])
+	assert.Equal(t, int64(1), after.Suppressed-before.Suppressed)
+}
+
+func TestMsgCountWatcher_SuppressedRecord(t *testing.T) {
+	cw := newMsgCountWatcher(time.Hour)
+	defer cw.stop()
+
+	var buf bytes.Buffer
+	logger := New("mod", "job")
+	logger.limited = true
+	logger.formatter.SetOutput(&buf)
+	cw.Register(logger)
+
+	for i := 0; i < msgPerSecondLimit+5; i++ {
+		logger.Info()
+	}
+	buf.Reset()
+	cw.resetCount()
+
+	assert.Contains(t, buf.String(), "messages suppressed: 5")
+	buf.Reset()
+	cw.resetCount()
+	assert.Empty(t, buf.String())
+}
+
+func TestMsgCountWatcher_SuppressedRecord_Structured(t *testing.T) {
+	tests := map[string]struct {
+		format Format
+		check  func(t *testing.T, out []byte)
+	}{
+		"json": {
+			format: FormatJSON,
+			check: func(t *testing.T, out []byte) {
+				var rec map[string]interface{}
+				require.NoError(t, json.Unmarshal(out, &rec))
+				assert.Equal(t, "warning", rec["level"])
+				assert.Equal(t, "job", rec["job"])
+				assert.Equal(t, float64(5), rec["suppressed"])
+			},
+		},
+		"logfmt": {
+			format: FormatLogfmt,
+			check: func(t *testing.T, out []byte) {
+				assert.Regexp(t, `^time=\S+ level=warning plugin=goplugin module=mod job=job msg=".+" suppressed=5\n$`, string(out))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			SetFormat(test.format)
+			defer SetFormat(FormatText)
+
+			cw := newMsgCountWatcher(time.Hour)
+			defer cw.stop()
+
+			var buf bytes.Buffer
+			logger := New("mod", "job")
+			logger.limited = true
+			logger.formatter.SetOutput(&buf)
+			cw.Register(logger)
+
+			for i := 0; i < msgPerSecondLimit+5; i++ {
+				logger.Info()
+			}
+			buf.Reset()
+			cw.resetCount()
+
+			test.check(t, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Format is a log output format.
+type Format string
+
+const (
+	// FormatText is the default human-readable format.
+	FormatText Format = "text"
+	// FormatJSON is one JSON object per line format.
+	FormatJSON Format = "json"
+	// FormatLogfmt is the logfmt (key=value pairs) format.
+	FormatLogfmt Format = "logfmt"
+)
+
+var globalFormat atomic.Value
+
+func init() {
+	globalFormat.Store(FormatText)
+}
+
+// ParseFormat returns the log format by its name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatText, FormatJSON, FormatLogfmt:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown log format '%s' (supported: %s, %s, %s)", name, FormatText, FormatJSON, FormatLogfmt)
+}
+
+// SetFormat sets global log output format.
+func SetFormat(format Format) {
+	globalFormat.Store(format)
+}
+
+func currentFormat() Format {
+	return globalFormat.Load().(Format)
+}
+
+type record struct {
+	Time   string `json:"time"`
+	Level  string `json:"level"`
+	Plugin string `json:"plugin,omitempty"`
+	Module string `json:"module,omitempty"`
+	Job    string `json:"job,omitempty"`
+	Msg    string `json:"msg"`
+	// Suppressed is the number of the rate limited messages, it is set only in the messages suppressed record.
+	Suppressed int64 `json:"suppressed,omitempty"`
+}
+
+func newRecord(t time.Time, severity Severity, plugin, module, job, msg string) record {
+	return record{
+		Time:   t.Format(time.RFC3339Nano),
+		Level:  strings.ToLower(severity.String()),
+		Plugin: plugin,
+		Module: module,
+		Job:    job,
+		Msg:    strings.TrimSuffix(msg, "\n"),
+	}
+}
+
+func (r record) appendJSON(buf []byte) []byte {
+	bs, err := json.Marshal(r)
+	if err != nil {
+		bs, _ = json.Marshal(record{Time: r.Time, Level: r.Level, Msg: err.Error()})
+	}
+	return append(buf, bs...)
+}
+
+func (r record) appendLogfmt(buf []byte) []byte {
+	buf = appendLogfmtPair(buf, "time", r.Time)
+	buf = appendLogfmtPair(buf, "level", r.Level)
+	if r.Plugin != "" {
+		buf = appendLogfmtPair(buf, "plugin", r.Plugin)
+	}
+	if r.Module != "" {
+		buf = appendLogfmtPair(buf, "module", r.Module)
+	}
+	if r.Job != "" {
+		buf = appendLogfmtPair(buf, "job", r.Job)
+	}
+	buf = appendLogfmtPair(buf, "msg", r.Msg)
+	if r.Suppressed != 0 {
+		buf = appendLogfmtPair(buf, "suppressed", strconv.FormatInt(r.Suppressed, 10))
+	}
+	return buf[:len(buf)-1]
+}
+
+func appendLogfmtPair(buf []byte, key, value string) []byte {
+	buf = append(buf, key...)
+	buf = append(buf, '=')
+	if value == "" || strings.ContainsAny(value, " =\"\\\t\r\n") {
+		buf = strconv.AppendQuote(buf, value)
+	} else {
+		buf = append(buf, value...)
+	}
+	return append(buf, ' ')
+}
@@ -13,6 +13,7 @@ import (
 type (
 	formatter struct {
 		colored bool
+		plugin  string
 		prefix  string
 		out     io.Writer // destination for output
 		flag    int       // properties
@@ -27,6 +28,7 @@ func newFormatter(out io.Writer, isCLI bool, prefix string) *formatter {
 		return &formatter{
 			out:     out,
 			colored: true,
+			plugin:  prefix,
 			flag:    log.Lshortfile,
 			buf:     make([]byte, 0, 120),
 		}
@@ -34,6 +36,7 @@ func newFormatter(out io.Writer, isCLI bool, prefix string) *formatter {
 	return &formatter{
 		out:     out,
 		colored: false,
+		plugin:  prefix,
 		prefix:  prefix + " ",
 		flag:    log.Ldate | log.Ltime,
 		buf:     make([]byte, 0, 120),
@@ -46,6 +49,12 @@ func (l *formatter) SetOutput(out io.Writer) {
 
 func (l *formatter) Output(severity Severity, module, job string, callDepth int, s string) {
 	now := time.Now() // get this early.
+
+	if format := currentFormat(); format == FormatJSON || format == FormatLogfmt {
+		l.outputStructured(format, newRecord(now, severity, l.plugin, module, job, s))
+		return
+	}
+
 	var file string
 	var line int
 	if l.flag&(log.Lshortfile|log.Llongfile) != 0 {
@@ -73,6 +82,32 @@ func (l *formatter) Output(severity Severity, module, job string, callDepth int,
 	l.buf = l.buf[:0]
 }
 
+// OutputSuppressed writes the messages suppressed record, in the structured formats the number
+// of the suppressed messages is a separate field.
+func (l *formatter) OutputSuppressed(module, job string, callDepth int, suppressed int64, s string) {
+	if format := currentFormat(); format == FormatJSON || format == FormatLogfmt {
+		r := newRecord(time.Now(), WARNING, l.plugin, module, job, s)
+		r.Suppressed = suppressed
+		l.outputStructured(format, r)
+		return
+	}
+	l.Output(WARNING, module, job, callDepth+1, s)
+}
+
+func (l *formatter) outputStructured(format Format, r record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if format == FormatJSON {
+		l.buf = r.appendJSON(l.buf)
+	} else {
+		l.buf = r.appendLogfmt(l.buf)
+	}
+	l.buf = append(l.buf, '\n')
+	_, _ = l.out.Write(l.buf)
+	l.buf = l.buf[:0]
+}
+
 // formatModuleJob write module name and job name to buf
 // format: $module[$job]
 func (l *formatter) formatModuleJob(module string, job string) {
@@ -4,9 +4,12 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormatter_Output_cli(t *testing.T) {
@@ -32,3 +35,47 @@ func TestFormatter_Output_file(t *testing.T) {
 	assert.NotContains(t, out.String(), "formatter_test.go:")
 	assert.Contains(t, out.String(), "hello")
 }
+
+func TestFormatter_Output_json(t *testing.T) {
+	SetFormat(FormatJSON)
+	defer SetFormat(FormatText)
+
+	out := &bytes.Buffer{}
+	fmtter := newFormatter(out, false, "test")
+
+	fmtter.Output(ERROR, "mod1", "job1", 1, "hello \"world\"\n")
+
+	var rec map[string]string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &rec))
+	assert.NotEmpty(t, rec["time"])
+	delete(rec, "time")
+	assert.Equal(t, map[string]string{
+		"level":  "error",
+		"plugin": "test",
+		"module": "mod1",
+		"job":    "job1",
+		"msg":    `hello "world"`,
+	}, rec)
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+}
+
+func TestFormatter_Output_logfmt(t *testing.T) {
+	SetFormat(FormatLogfmt)
+	defer SetFormat(FormatText)
+
+	out := &bytes.Buffer{}
+	fmtter := newFormatter(out, true, "test")
+
+	fmtter.Output(INFO, "mod1", "job1", 1, "hello world")
+
+	assert.Regexp(t, `^time=\S+ level=info plugin=test module=mod1 job=job1 msg="hello world"\n$`, out.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"text", "json", "logfmt", "JSON"} {
+		_, err := ParseFormat(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -178,13 +178,16 @@ func (l *Logger) output(severity Severity, callDepth int, msg string) {
 	}
 
 	if l == nil || l.formatter == nil {
+		GlobalMsgCountWatcher.countLogged(severity)
 		base.formatter.Output(severity, base.modName, base.jobName, callDepth+2, msg)
 		return
 	}
 
 	if l.limited && globalSeverity < DEBUG && atomic.AddInt64(&l.msgCount, 1) > msgPerSecondLimit {
+		GlobalMsgCountWatcher.countSuppressed()
 		return
 	}
+	GlobalMsgCountWatcher.countLogged(severity)
 	l.formatter.Output(severity, l.modName, l.jobName, callDepth+2, msg)
 }
 
@@ -34,6 +34,7 @@ import (
 	_ "github.com/netdata/go.d.plugin/modules/k8s_state"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd"
 	_ "github.com/netdata/go.d.plugin/modules/lighttpd2"
+	_ "github.com/netdata/go.d.plugin/modules/logmetrics"
 	_ "github.com/netdata/go.d.plugin/modules/logstash"
 	_ "github.com/netdata/go.d.plugin/modules/mongodb"
 	_ "github.com/netdata/go.d.plugin/modules/mysql"
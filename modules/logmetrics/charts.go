@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"strconv"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/logs"
+)
+
+type (
+	Charts = module.Charts
+	Chart  = module.Chart
+	Dims   = module.Dims
+	Dim    = module.Dim
+)
+
+const (
+	prioLines = module.Priority + iota
+	prioSyslogMessages
+	prioBackfillLines
+	// prioRules is the priority of the first rule chart, the rule charts follow in the rules order.
+	prioRules
+)
+
+var (
+	linesChart = Chart{
+		ID:       "log_lines",
+		Title:    "Log Lines",
+		Units:    "lines/s",
+		Fam:      "log lines",
+		Ctx:      "logmetrics.log_lines",
+		Priority: prioLines,
+		Dims: Dims{
+			{ID: "lines", Name: "total", Algo: module.Incremental},
+			{ID: "unmatched", Algo: module.Incremental},
+			{ID: "invalid_values", Name: "invalid values", Algo: module.Incremental},
+		},
+	}
+	syslogMessagesChart = Chart{
+		ID:       "syslog_messages",
+		Title:    "Syslog Messages",
+		Units:    "messages/s",
+		Fam:      "log lines",
+		Ctx:      "logmetrics.syslog_messages",
+		Priority: prioSyslogMessages,
+		Dims: Dims{
+			{ID: "syslog_received", Name: "received", Algo: module.Incremental},
+			{ID: "syslog_dropped", Name: "dropped", Algo: module.Incremental},
+		},
+	}
+	backfillLinesChart = Chart{
+		ID:       "backfill_lines",
+		Title:    "Backfilled Lines",
+		Units:    "lines/s",
+		Fam:      "log lines",
+		Ctx:      "logmetrics.backfill_lines",
+		Priority: prioBackfillLines,
+		Dims: Dims{
+			{ID: "backfill_lines", Name: "replayed", Algo: module.Incremental},
+		},
+	}
+)
+
+func (l *LogMetrics) createCharts() error {
+	charts := &Charts{}
+	if err := charts.Add(linesChart.Copy()); err != nil {
+		return err
+	}
+	if _, ok := l.file.(*logs.SyslogReader); ok {
+		if err := charts.Add(syslogMessagesChart.Copy()); err != nil {
+			return err
+		}
+	}
+	if _, ok := l.file.(*logs.Reader); ok && l.Backfill.Enabled {
+		if err := charts.Add(backfillLinesChart.Copy()); err != nil {
+			return err
+		}
+	}
+	for i, r := range l.rules {
+		r.chart = newRuleChart(r, prioRules+i)
+		if err := charts.Add(r.chart); err != nil {
+			return err
+		}
+	}
+	l.charts = charts
+	return nil
+}
+
+func newRuleChart(r *rule, priority int) *Chart {
+	chart := &Chart{
+		ID:       r.Name,
+		Title:    r.Title,
+		Units:    r.Units,
+		Fam:      r.Name,
+		Ctx:      "logmetrics." + r.Name,
+		Priority: priority,
+	}
+	if chart.Title == "" {
+		chart.Title = r.Name
+	}
+	if chart.Units == "" {
+		chart.Units = defaultUnits(r.Type)
+	}
+
+	switch {
+	case r.Type == typeHistogram:
+		for i, bound := range r.Buckets {
+			chart.Dims = append(chart.Dims, &Dim{
+				ID:   r.Name + "_bucket_" + strconv.Itoa(i+1),
+				Name: strconv.FormatFloat(bound, 'f', -1, 64),
+				Algo: module.Incremental,
+			})
+		}
+		chart.Dims = append(chart.Dims, &Dim{ID: r.Name + "_count", Name: "+Inf", Algo: module.Incremental})
+	case r.Type == typeSummary && len(r.Dimensions) == 0:
+		for _, v := range []string{"min", "avg", "max"} {
+			chart.Dims = append(chart.Dims, &Dim{ID: r.Name + "_value_" + v, Name: v, Div: r.Divisor})
+		}
+	case len(r.Dimensions) == 0:
+		chart.Dims = append(chart.Dims, newRuleDim(r, r.defaultDimension()))
+	}
+	return chart
+}
+
+func newRuleDim(r *rule, dim string) *Dim {
+	d := &Dim{ID: r.Name + "_" + dim, Name: dim, Div: r.Divisor}
+	switch r.Type {
+	case typeCounter:
+		d.Algo = module.Incremental
+	case typeSummary:
+		d.ID += "_avg"
+	}
+	return d
+}
+
+func (r *rule) addDimToChart(dim string) {
+	if r.chart == nil {
+		return
+	}
+	if err := r.chart.AddDim(newRuleDim(r, dim)); err != nil {
+		return
+	}
+	r.chart.MarkNotCreated()
+}
+
+func defaultUnits(typ string) string {
+	switch typ {
+	case typeCounter, typeHistogram:
+		return "events/s"
+	case typeUniqueCounter:
+		return "values"
+	default:
+		return "value"
+	}
+}
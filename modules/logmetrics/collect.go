@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"io"
+
+	"github.com/netdata/go.d.plugin/pkg/logs"
+	"github.com/netdata/go.d.plugin/pkg/stm"
+)
+
+func (l *LogMetrics) collect() (map[string]int64, error) {
+	for _, r := range l.rules {
+		r.reset()
+	}
+
+	var mx map[string]int64
+
+	n, err := l.collectLogLines()
+
+	if n > 0 || err == nil {
+		mx = stm.ToMap(l.mx)
+		l.collectRules(mx)
+		l.collectSourceStats(mx)
+	}
+	return mx, err
+}
+
+func (l *LogMetrics) collectLogLines() (int, error) {
+	logOnce := true
+	var n int
+	l.src.err = nil
+	for {
+		l.line.reset()
+		err := l.parser.ReadLine(l.line)
+		if err != nil {
+			if err == io.EOF {
+				return n, nil
+			}
+			if !logs.IsParseError(err) && l.src.err != nil {
+				return n, err
+			}
+			n++
+			if logOnce {
+				l.Infof("unmatched line: %v (parser: %s)", err, l.parser.Info())
+				logOnce = false
+			}
+			l.mx.Lines.Inc()
+			l.mx.Unmatched.Inc()
+			continue
+		}
+		n++
+		l.mx.Lines.Inc()
+		for _, r := range l.rules {
+			r.process(l.line)
+		}
+	}
+}
+
+func (l *LogMetrics) collectRules(mx map[string]int64) {
+	var badValues int64
+	for _, r := range l.rules {
+		r.writeTo(mx)
+		badValues += r.badValues
+		if r.limitHit {
+			l.Warningf("rule '%s': reached max_dimensions (%d), new values are collected into the '%s' dimension",
+				r.Name, r.MaxDimensions, dimOther)
+			r.limitHit = false
+		}
+	}
+	mx["invalid_values"] = badValues
+}
+
+func (l *LogMetrics) collectSourceStats(mx map[string]int64) {
+	if reader, ok := l.file.(*logs.SyslogReader); ok {
+		stats := reader.Stats()
+		mx["syslog_received"] = stats.Received
+		mx["syslog_dropped"] = stats.Dropped
+	}
+	if reader, ok := l.file.(*logs.Reader); ok && l.Backfill.Enabled {
+		mx["backfill_lines"] = reader.BackfillStats().Lines
+	}
+}
+
+// sourceReader records the log source read errors. Not every parser returns logs.ParseError for a malformed line
+// (the json parser returns the decoder error as is), a line error not caused by the source is an unmatched line.
+type sourceReader struct {
+	io.Reader
+	err error
+}
+
+func (r *sourceReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/netdata/go.d.plugin/pkg/logs"
+)
+
+func (l LogMetrics) validateConfig() error {
+	if len(l.Rules) == 0 {
+		return errors.New("'rules' not set")
+	}
+	if (l.Source == "" || l.Source == logs.SourceFile) && l.Path == "" {
+		return errors.New("'path' not set")
+	}
+	return nil
+}
+
+func (l LogMetrics) initRules() ([]*rule, error) {
+	var rules []*rule
+	seen := make(map[string]bool)
+	for i, cfg := range l.Rules {
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("rule %d: duplicate name '%s'", i+1, cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		r, err := newRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %v", i+1, cfg.Name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (l *LogMetrics) createLogReader() error {
+	l.Cleanup()
+	l.Debug("starting log reader creating")
+
+	var reader logs.Source
+	var err error
+	switch l.Source {
+	case "", logs.SourceFile:
+		reader, err = l.createFileReader()
+	case logs.SourceJournal:
+		reader, err = l.createJournalReader()
+	case logs.SourceSyslog:
+		reader, err = logs.NewSyslogReader(l.Syslog, l.Logger)
+	default:
+		err = fmt.Errorf("unknown source '%s'", l.Source)
+	}
+	if err != nil {
+		return fmt.Errorf("creating log reader: %v", err)
+	}
+
+	l.Debugf("created log reader, source '%s'", reader.Name())
+	l.file = reader
+	return nil
+}
+
+func (l *LogMetrics) createFileReader() (logs.Source, error) {
+	cfg := logs.ReaderConfig{
+		Path:        l.Path,
+		ExcludePath: l.ExcludePath,
+		AllFiles:    l.TailAllFiles,
+		Backfill:    l.Backfill,
+	}
+	if l.PersistOffsets {
+		cfg.CheckpointFile = logs.DefaultCheckpointFile(l.Path, l.ExcludePath)
+	}
+	reader, err := logs.NewReader(cfg, l.Logger)
+	if err != nil {
+		return nil, err
+	}
+	l.Debugf("tailing files %v", reader.Filenames())
+	return reader, nil
+}
+
+func (l *LogMetrics) createJournalReader() (logs.Source, error) {
+	cfg := l.Journal
+	if l.PersistOffsets {
+		cfg.CursorFile = logs.DefaultJournalCursorFile(cfg)
+	}
+	return logs.NewJournalReader(cfg, l.Logger)
+}
+
+func (l *LogMetrics) createParser() error {
+	l.Debug("starting parser creating")
+	lastLine, err := l.file.LastLine()
+	if err != nil {
+		return fmt.Errorf("read last line: %v", err)
+	}
+
+	lastLine = bytes.TrimRight(lastLine, "\n")
+	l.Debugf("last line: '%s'", string(lastLine))
+
+	l.src = &sourceReader{Reader: l.file}
+	l.parser, err = logs.NewParser(l.Parser, l.src)
+	if err != nil {
+		return fmt.Errorf("create parser: %v", err)
+	}
+	l.Debugf("created parser: %s", l.parser.Info())
+
+	// an empty log is fine, the rules may match the lines that are not written yet
+	if len(lastLine) == 0 {
+		return nil
+	}
+
+	l.line.reset()
+	if err = l.parser.Parse(lastLine, l.line); err != nil {
+		return fmt.Errorf("parse last line: %v (%s)", err, string(lastLine))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+// logLine keeps the values of the fields used by the rules, the other fields are ignored.
+type logLine struct {
+	known  map[string]bool
+	values map[string]string
+}
+
+func newLogLine(rules []*rule) *logLine {
+	l := &logLine{
+		known:  make(map[string]bool),
+		values: make(map[string]string),
+	}
+	for _, r := range rules {
+		for _, field := range r.fields() {
+			l.known[field] = true
+		}
+	}
+	return l
+}
+
+func (l *logLine) Assign(field string, value string) error {
+	if l.known[field] {
+		l.values[field] = value
+	}
+	return nil
+}
+
+// get returns the field value, or an empty string if the field is not in the line.
+func (l *logLine) get(field string) string {
+	return l.values[field]
+}
+
+func (l *logLine) reset() {
+	for k := range l.values {
+		delete(l.values, k)
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"github.com/netdata/go.d.plugin/pkg/logs"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+)
+
+func init() {
+	creator := module.Creator{
+		Create: func() module.Module { return New() },
+	}
+
+	module.Register("logmetrics", creator)
+}
+
+func New() *LogMetrics {
+	cfg := logs.ParserConfig{
+		LogType: logs.TypeJSON,
+		CSV: logs.CSVConfig{
+			FieldsPerRecord:  -1,
+			Delimiter:        " ",
+			TrimLeadingSpace: false,
+		},
+		LTSV: logs.LTSVConfig{
+			FieldDelimiter: "\t",
+			ValueDelimiter: ":",
+		},
+	}
+	return &LogMetrics{
+		Config: Config{
+			ExcludePath:    "*.gz",
+			PersistOffsets: true,
+			Parser:         cfg,
+		},
+	}
+}
+
+type (
+	Config struct {
+		Parser         logs.ParserConfig   `yaml:",inline"`
+		Source         string              `yaml:"source"`
+		Path           string              `yaml:"path"`
+		ExcludePath    string              `yaml:"exclude_path"`
+		TailAllFiles   bool                `yaml:"tail_all_files"`
+		PersistOffsets bool                `yaml:"persist_offsets"`
+		Backfill       logs.BackfillConfig `yaml:"backfill"`
+		Journal        logs.JournalConfig  `yaml:"journal"`
+		Syslog         logs.SyslogConfig   `yaml:"syslog"`
+		Rules          []ruleConfig        `yaml:"rules" required:"true"`
+	}
+
+	LogMetrics struct {
+		module.Base
+		Config `yaml:",inline"`
+
+		file   logs.Source
+		src    *sourceReader
+		parser logs.Parser
+		line   *logLine
+		rules  []*rule
+
+		mx     *metricsData
+		charts *module.Charts
+	}
+)
+
+func (l *LogMetrics) Init() bool {
+	if err := l.validateConfig(); err != nil {
+		l.Errorf("config validation: %v", err)
+		return false
+	}
+
+	rules, err := l.initRules()
+	if err != nil {
+		l.Errorf("init rules: %v", err)
+		return false
+	}
+	l.rules = rules
+	l.line = newLogLine(rules)
+	l.mx = &metricsData{}
+	return true
+}
+
+func (l *LogMetrics) Check() bool {
+	// Note: these inits are here to make auto detection retry working
+	if err := l.createLogReader(); err != nil {
+		l.Warning("check failed: ", err)
+		return false
+	}
+
+	if err := l.createParser(); err != nil {
+		l.Warning("check failed: ", err)
+		return false
+	}
+
+	if err := l.createCharts(); err != nil {
+		l.Warning("check failed: ", err)
+		return false
+	}
+	return true
+}
+
+func (l *LogMetrics) Charts() *module.Charts {
+	return l.charts
+}
+
+func (l *LogMetrics) Collect() map[string]int64 {
+	mx, err := l.collect()
+	if err != nil {
+		l.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
+
+func (l *LogMetrics) Cleanup() {
+	if l.file != nil {
+		_ = l.file.Close()
+	}
+}
@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/netdata/go.d.plugin/pkg/logs"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testAppLog, _ = ioutil.ReadFile("testdata/app.log")
+)
+
+func Test_readTestData(t *testing.T) {
+	assert.NotNil(t, testAppLog)
+}
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestLogMetrics_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"success on default config with rules": {
+			config: prepareConfig(),
+		},
+		"fails on no rules": {
+			wantFail: true,
+			config:   Config{Path: "testdata/app.log"},
+		},
+		"fails on no path": {
+			wantFail: true,
+			config:   Config{Rules: []ruleConfig{{Name: "requests", Type: typeCounter}}},
+		},
+		"fails on invalid rule name": {
+			wantFail: true,
+			config:   Config{Path: "testdata/app.log", Rules: []ruleConfig{{Name: "req.total", Type: typeCounter}}},
+		},
+		"fails on duplicate rule name": {
+			wantFail: true,
+			config: Config{Path: "testdata/app.log", Rules: []ruleConfig{
+				{Name: "requests", Type: typeCounter},
+				{Name: "requests", Type: typeCounter},
+			}},
+		},
+		"fails on unknown rule type": {
+			wantFail: true,
+			config:   Config{Path: "testdata/app.log", Rules: []ruleConfig{{Name: "requests", Type: "meter"}}},
+		},
+		"fails on no value": {
+			wantFail: true,
+			config:   Config{Path: "testdata/app.log", Rules: []ruleConfig{{Name: "queue", Type: typeGauge}}},
+		},
+		"fails on histogram with dimensions": {
+			wantFail: true,
+			config: Config{Path: "testdata/app.log", Rules: []ruleConfig{
+				{Name: "latency", Type: typeHistogram, Value: "duration", Dimensions: []string{"method"}},
+			}},
+		},
+		"fails on invalid match expression": {
+			wantFail: true,
+			config: Config{Path: "testdata/app.log", Rules: []ruleConfig{
+				{Name: "errors", Type: typeCounter, Match: []fieldMatch{{Field: "level", Expr: "^ error"}}},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lm := New()
+			lm.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, lm.Init())
+			} else {
+				assert.True(t, lm.Init())
+			}
+		})
+	}
+}
+
+func TestLogMetrics_Check(t *testing.T) {
+	lm := New()
+	defer lm.Cleanup()
+	lm.Config = prepareConfig()
+	require.True(t, lm.Init())
+
+	assert.True(t, lm.Check())
+}
+
+func TestLogMetrics_Check_ErrorOnCreatingLogReaderNoLogFile(t *testing.T) {
+	lm := New()
+	defer lm.Cleanup()
+	lm.Config = prepareConfig()
+	lm.Path = "testdata/not_exists.log"
+	require.True(t, lm.Init())
+
+	assert.False(t, lm.Check())
+}
+
+func TestLogMetrics_Check_ErrorOnParsingLastLine(t *testing.T) {
+	lm := New()
+	defer lm.Cleanup()
+	lm.Config = prepareConfig()
+	lm.Parser.LogType = logs.TypeRegExp
+	lm.Parser.RegExp.Pattern = `^(?P<level>[a-z]+) (?P<method>[A-Z]+)$`
+	require.True(t, lm.Init())
+
+	assert.False(t, lm.Check())
+}
+
+func TestLogMetrics_Charts(t *testing.T) {
+	lm := New()
+	defer lm.Cleanup()
+	lm.Config = prepareConfig()
+	require.True(t, lm.Init())
+	require.True(t, lm.Check())
+
+	charts := lm.Charts()
+	require.NotNil(t, charts)
+	for _, id := range []string{linesChart.ID, "requests", "errors", "queue", "duration", "users", "latency"} {
+		assert.Truef(t, charts.Has(id), "chart '%s' is not created", id)
+	}
+	assert.Len(t, charts.Get("requests").Dims, 0)
+	assert.Len(t, charts.Get("latency").Dims, 4)
+}
+
+func TestLogMetrics_Cleanup(t *testing.T) {
+	New().Cleanup()
+}
+
+func TestLogMetrics_Collect(t *testing.T) {
+	lm := prepareLogMetricsCollect(t)
+
+	expected := map[string]int64{
+		"duration_value_avg":   406,
+		"duration_value_count": 5,
+		"duration_value_max":   1500,
+		"duration_value_min":   10,
+		"duration_value_sum":   2030,
+		"errors_total":         1,
+		"invalid_values":       2,
+		"latency_bucket_1":     2,
+		"latency_bucket_2":     4,
+		"latency_bucket_3":     4,
+		"latency_count":        5,
+		"latency_sum":          2030,
+		"lines":                7,
+		"queue_value":          1,
+		"requests_GET":         3,
+		"requests_POST":        1,
+		"requests_other":       2,
+		"unmatched":            1,
+		"users_total":          4,
+	}
+
+	mx := lm.Collect()
+
+	assert.Equal(t, expected, mx)
+
+	var dims []string
+	for _, dim := range lm.Charts().Get("requests").Dims {
+		dims = append(dims, dim.ID)
+	}
+	assert.Equal(t, []string{"requests_GET", "requests_POST", "requests_other"}, dims)
+}
+
+func TestLogMetrics_Collect_ResetsPerCollectionValues(t *testing.T) {
+	lm := prepareLogMetricsCollect(t)
+	_ = lm.Collect()
+
+	mx := lm.Collect()
+
+	assert.Equal(t, int64(3), mx["requests_GET"])
+	assert.Equal(t, int64(1), mx["queue_value"])
+	assert.Equal(t, int64(0), mx["duration_value_count"])
+	assert.NotContains(t, mx, "duration_value_avg")
+	assert.Equal(t, int64(0), mx["users_total"])
+}
+
+func TestLogMetrics_Collect_ReadError(t *testing.T) {
+	lm := prepareLogMetricsCollect(t)
+	lm.src = &sourceReader{Reader: iotest.ErrReader(errors.New("read error"))}
+	p, err := logs.NewJSONParser(lm.Parser.JSON, lm.src)
+	require.NoError(t, err)
+	lm.parser = p
+
+	_, err = lm.collectLogLines()
+
+	assert.EqualError(t, err, "read error")
+}
+
+func prepareConfig() Config {
+	cfg := New().Config
+	cfg.Path = "testdata/app.log"
+	cfg.PersistOffsets = false
+	cfg.Rules = []ruleConfig{
+		{Name: "requests", Type: typeCounter, Dimensions: []string{"method"}, MaxDimensions: 2},
+		{Name: "errors", Type: typeCounter, Match: []fieldMatch{{Field: "level", Expr: "= error"}}},
+		{Name: "queue", Type: typeGauge, Value: "queue"},
+		{Name: "duration", Type: typeSummary, Value: "duration", Units: "milliseconds"},
+		{Name: "users", Type: typeUniqueCounter, Value: "user"},
+		{Name: "latency", Type: typeHistogram, Value: "duration", Buckets: []float64{100, 500, 1000}},
+	}
+	return cfg
+}
+
+func prepareLogMetricsCollect(t *testing.T) *LogMetrics {
+	t.Helper()
+	lm := New()
+	lm.Config = prepareConfig()
+	require.True(t, lm.Init())
+	require.True(t, lm.Check())
+	defer lm.Cleanup()
+
+	lm.src = &sourceReader{Reader: bytes.NewReader(testAppLog)}
+	p, err := logs.NewJSONParser(lm.Parser.JSON, lm.src)
+	require.NoError(t, err)
+	lm.parser = p
+	return lm
+}
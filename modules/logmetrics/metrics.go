@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import "github.com/netdata/go.d.plugin/pkg/metrics"
+
+type metricsData struct {
+	Lines     metrics.Counter `stm:"lines"`
+	Unmatched metrics.Counter `stm:"unmatched"`
+}
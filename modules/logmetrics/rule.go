@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logmetrics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/matcher"
+	"github.com/netdata/go.d.plugin/pkg/metrics"
+)
+
+const (
+	typeCounter       = "counter"
+	typeGauge         = "gauge"
+	typeHistogram     = "histogram"
+	typeSummary       = "summary"
+	typeUniqueCounter = "unique_counter"
+)
+
+const (
+	defaultMaxDimensions = 100
+	// dimOther is the dimension the values above the max_dimensions limit are collected into.
+	dimOther = "other"
+	// dimUnknown is the dimension if the dimension fields values are empty.
+	dimUnknown = "unknown"
+)
+
+var reRuleName = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+type (
+	ruleConfig struct {
+		Name          string       `yaml:"name"`
+		Title         string       `yaml:"title"`
+		Units         string       `yaml:"units"`
+		Type          string       `yaml:"type"`
+		Match         []fieldMatch `yaml:"match"`
+		Value         string       `yaml:"value"`
+		Multiplier    int          `yaml:"multiplier"`
+		Divisor       int          `yaml:"divisor"`
+		Dimensions    []string     `yaml:"dimensions"`
+		MaxDimensions int          `yaml:"max_dimensions"`
+		Buckets       []float64    `yaml:"buckets"`
+	}
+	fieldMatch struct {
+		Field string `yaml:"field"`
+		Expr  string `yaml:"expr"`
+	}
+)
+
+type (
+	// rule updates a metric using the matched log lines values.
+	rule struct {
+		ruleConfig
+		matchers []fieldMatcher
+
+		counters   metrics.CounterVec
+		gauges     metrics.GaugeVec
+		summaries  metrics.SummaryVec
+		uniques    metrics.UniqueCounterVec
+		histogram  metrics.Histogram
+		dims       map[string]bool
+		limitHit   bool  // the max_dimensions limit is hit, reset after it is reported
+		badValues  int64 // the matched lines with a non-numeric or negative counter value
+		chart      *module.Chart
+		dimBuilder strings.Builder
+	}
+	fieldMatcher struct {
+		field string
+		m     matcher.Matcher
+	}
+)
+
+func newRule(cfg ruleConfig) (*rule, error) {
+	if !reRuleName.MatchString(cfg.Name) {
+		return nil, fmt.Errorf("invalid name '%s', allowed characters are letters, digits, '_' and '-'", cfg.Name)
+	}
+	if cfg.Multiplier == 0 {
+		cfg.Multiplier = 1
+	}
+	if cfg.Divisor == 0 {
+		cfg.Divisor = 1
+	}
+	if cfg.MaxDimensions <= 0 {
+		cfg.MaxDimensions = defaultMaxDimensions
+	}
+
+	r := &rule{ruleConfig: cfg, dims: make(map[string]bool)}
+
+	switch cfg.Type {
+	case typeCounter:
+		r.counters = metrics.NewCounterVec()
+	case typeGauge:
+		r.gauges = metrics.NewGaugeVec()
+	case typeSummary:
+		r.summaries = metrics.NewSummaryVec()
+	case typeUniqueCounter:
+		r.uniques = metrics.NewUniqueCounterVec(false)
+	case typeHistogram:
+		if len(cfg.Dimensions) > 0 {
+			return nil, errors.New("histogram doesn't support dimensions")
+		}
+		if len(r.Buckets) == 0 {
+			r.Buckets = metrics.DefBuckets
+		}
+		r.histogram = metrics.NewHistogram(r.Buckets)
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", cfg.Type)
+	}
+
+	if cfg.Value == "" && cfg.Type != typeCounter {
+		return nil, fmt.Errorf("'value' is required for the '%s' type", cfg.Type)
+	}
+
+	for _, fm := range cfg.Match {
+		if fm.Field == "" {
+			return nil, errors.New("match: 'field' is not set")
+		}
+		m, err := matcher.Parse(fm.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("match '%s': %v", fm.Field, err)
+		}
+		r.matchers = append(r.matchers, fieldMatcher{field: fm.Field, m: m})
+	}
+	return r, nil
+}
+
+// fields returns the log line fields the rule uses.
+func (r *rule) fields() []string {
+	var fields []string
+	for _, m := range r.matchers {
+		fields = append(fields, m.field)
+	}
+	if r.Value != "" {
+		fields = append(fields, r.Value)
+	}
+	return append(fields, r.Dimensions...)
+}
+
+func (r *rule) process(line *logLine) {
+	for _, m := range r.matchers {
+		if !m.m.MatchString(line.get(m.field)) {
+			return
+		}
+	}
+
+	var value float64
+	if r.Value != "" && r.Type != typeUniqueCounter {
+		v, err := strconv.ParseFloat(line.get(r.Value), 64)
+		if err != nil {
+			r.badValues++
+			return
+		}
+		value = v * float64(r.Multiplier)
+	}
+
+	switch r.Type {
+	case typeCounter:
+		if value < 0 {
+			r.badValues++
+			return
+		}
+		c := r.counters.Get(r.dimension(line))
+		if r.Value == "" {
+			c.Inc()
+		} else {
+			c.Add(value)
+		}
+	case typeGauge:
+		r.gauges.Get(r.dimension(line)).Set(value)
+	case typeSummary:
+		r.summaries.Get(r.dimension(line)).Observe(value)
+	case typeUniqueCounter:
+		r.uniques.Get(r.dimension(line)).Insert(line.get(r.Value))
+	case typeHistogram:
+		r.histogram.Observe(value)
+	}
+}
+
+// dimension returns the dimension the line values are collected into, a new dimension is added to the chart.
+func (r *rule) dimension(line *logLine) string {
+	if len(r.Dimensions) == 0 {
+		return r.defaultDimension()
+	}
+
+	r.dimBuilder.Reset()
+	for i, field := range r.Dimensions {
+		if i > 0 {
+			r.dimBuilder.WriteByte('_')
+		}
+		r.dimBuilder.WriteString(line.get(field))
+	}
+	dim := cleanDimension(r.dimBuilder.String())
+	if dim == "" {
+		dim = dimUnknown
+	}
+
+	if r.dims[dim] {
+		return dim
+	}
+	if len(r.dims) >= r.MaxDimensions {
+		dim = dimOther
+		if r.dims[dim] {
+			return dim
+		}
+		r.limitHit = true
+	}
+	r.dims[dim] = true
+	r.addDimToChart(dim)
+	return dim
+}
+
+// defaultDimension returns the dimension of a rule without dimension fields.
+func (r *rule) defaultDimension() string {
+	switch r.Type {
+	case typeGauge, typeSummary:
+		return "value"
+	default:
+		return "total"
+	}
+}
+
+// reset resets the values that are calculated per collection.
+func (r *rule) reset() {
+	if r.summaries != nil {
+		r.summaries.Reset()
+	}
+	if r.uniques.Items != nil {
+		r.uniques.Reset()
+	}
+}
+
+func (r *rule) writeTo(mx map[string]int64) {
+	switch r.Type {
+	case typeCounter:
+		r.counters.WriteTo(mx, r.Name, 1, 1)
+	case typeGauge:
+		r.gauges.WriteTo(mx, r.Name, 1, 1)
+	case typeSummary:
+		r.summaries.WriteTo(mx, r.Name, 1, 1)
+	case typeUniqueCounter:
+		r.uniques.WriteTo(mx, r.Name, 1, 1)
+	case typeHistogram:
+		r.histogram.WriteTo(mx, r.Name, 1, 1)
+	}
+}
+
+func cleanDimension(dim string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\'', '"', '\\':
+			return '_'
+		}
+		return r
+	}, dim)
+}
@@ -3,9 +3,6 @@
 package postgres
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/netdata/go.d.plugin/agent/module"
 )
 
@@ -345,34 +342,3 @@ var (
 		},
 	}
 )
-
-func newDatabaseCharts(dbname string) *module.Charts {
-	charts := dbChartsTmpl.Copy()
-	for _, c := range *charts {
-		c.ID = fmt.Sprintf(c.ID, dbname)
-		c.Labels = []module.Label{
-			{Key: "database", Value: dbname},
-		}
-		for _, d := range c.Dims {
-			d.ID = fmt.Sprintf(d.ID, dbname)
-		}
-	}
-	return charts
-}
-
-func (p *Postgres) addNewDatabaseCharts(dbname string) {
-	charts := newDatabaseCharts(dbname)
-	if err := p.Charts().Add(*charts...); err != nil {
-		p.Warning(err)
-	}
-}
-
-func (p *Postgres) removeDatabaseCharts(dbname string) {
-	prefix := fmt.Sprintf("db_%s_", dbname)
-	for _, c := range *p.Charts() {
-		if strings.HasPrefix(c.ID, prefix) {
-			c.MarkRemove()
-			c.MarkNotCreated()
-		}
-	}
-}
@@ -4,6 +4,8 @@ package postgres
 
 import (
 	"context"
+
+	"github.com/netdata/go.d.plugin/agent/module"
 )
 
 func (p *Postgres) collectDatabaseStats(mx map[string]int64) error {
@@ -139,23 +141,12 @@ func (p *Postgres) collectDatabaseList(dbs []string) {
 		return
 	}
 
-	collected := make(map[string]bool)
-	for _, db := range p.databases {
-		collected[db] = true
-	}
 	p.databases = dbs
 
-	seen := make(map[string]bool)
 	for _, db := range dbs {
-		seen[db] = true
-		if !collected[db] {
-			collected[db] = true
-			p.addNewDatabaseCharts(db)
-		}
-	}
-	for db := range collected {
-		if !seen[db] {
-			p.removeDatabaseCharts(db)
+		if _, err := p.dbCharts.Seen(db, module.Label{Key: "database", Value: db}); err != nil {
+			p.Warning(err)
 		}
 	}
+	p.dbCharts.Update()
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/logs"
 )
 
 type (
@@ -42,6 +43,9 @@ const (
 	prioServers
 
 	prioMimeType
+
+	prioSyslogMessages
+	prioBackfillLines
 )
 
 var (
@@ -232,6 +236,31 @@ var (
 		Priority: prioMimeType,
 	}
 
+	// Syslog
+	syslogMessagesChart = Chart{
+		ID:       "syslog_messages",
+		Title:    "Syslog Messages",
+		Units:    "messages/s",
+		Fam:      "syslog",
+		Ctx:      "squidlog.syslog_messages",
+		Priority: prioSyslogMessages,
+		Dims: Dims{
+			{ID: "syslog_received", Name: "received", Algo: module.Incremental},
+			{ID: "syslog_dropped", Name: "dropped", Algo: module.Incremental},
+		},
+	}
+	backfillLinesChart = Chart{
+		ID:       "backfill_lines",
+		Title:    "Backfilled Lines",
+		Units:    "lines/s",
+		Fam:      "backfill",
+		Ctx:      "squidlog.backfill_lines",
+		Priority: prioBackfillLines,
+		Dims: Dims{
+			{ID: "backfill_lines", Name: "replayed", Algo: module.Incremental},
+		},
+	}
+
 	// Hierarchy
 	hierCodeChart = Chart{
 		ID:       "requests_by_hier_code",
@@ -306,6 +335,16 @@ func (s *SquidLog) createCharts(line *logLine) error {
 			return err
 		}
 	}
+	if _, ok := s.file.(*logs.SyslogReader); ok {
+		if err := charts.Add(syslogMessagesChart.Copy()); err != nil {
+			return err
+		}
+	}
+	if _, ok := s.file.(*logs.Reader); ok && s.Backfill.Enabled {
+		if err := charts.Add(backfillLinesChart.Copy()); err != nil {
+			return err
+		}
+	}
 	s.charts = charts
 	return nil
 }
@@ -40,10 +40,22 @@ func (s *SquidLog) collect() (map[string]int64, error) {
 
 	if n > 0 || err == nil {
 		mx = stm.ToMap(s.mx)
+		s.collectSourceStats(mx)
 	}
 	return mx, err
 }
 
+func (s *SquidLog) collectSourceStats(mx map[string]int64) {
+	if reader, ok := s.file.(*logs.SyslogReader); ok {
+		stats := reader.Stats()
+		mx["syslog_received"] = stats.Received
+		mx["syslog_dropped"] = stats.Dropped
+	}
+	if reader, ok := s.file.(*logs.Reader); ok && s.Backfill.Enabled {
+		mx["backfill_lines"] = reader.BackfillStats().Lines
+	}
+}
+
 func (s *SquidLog) collectLogLines() (int, error) {
 	var n int
 	for {
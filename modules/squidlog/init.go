@@ -14,19 +14,56 @@ func (s *SquidLog) createLogReader() error {
 	s.Cleanup()
 	s.Debug("starting log reader creating")
 
-	reader, err := logs.Open(s.Path, s.ExcludePath, s.Logger)
+	var reader logs.Source
+	var err error
+	switch s.Source {
+	case "", logs.SourceFile:
+		reader, err = s.createFileReader()
+	case logs.SourceJournal:
+		reader, err = s.createJournalReader()
+	case logs.SourceSyslog:
+		reader, err = logs.NewSyslogReader(s.Syslog, s.Logger)
+	default:
+		err = fmt.Errorf("unknown source '%s'", s.Source)
+	}
 	if err != nil {
 		return fmt.Errorf("creating log reader: %v", err)
 	}
 
-	s.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
+	s.Debugf("created log reader, source '%s'", reader.Name())
 	s.file = reader
 	return nil
 }
 
+func (s *SquidLog) createFileReader() (logs.Source, error) {
+	cfg := logs.ReaderConfig{
+		Path:        s.Path,
+		ExcludePath: s.ExcludePath,
+		AllFiles:    s.TailAllFiles,
+		Backfill:    s.Backfill,
+	}
+	if s.PersistOffsets {
+		cfg.CheckpointFile = logs.DefaultCheckpointFile(s.Path, s.ExcludePath)
+	}
+	reader, err := logs.NewReader(cfg, s.Logger)
+	if err != nil {
+		return nil, err
+	}
+	s.Debugf("tailing files %v", reader.Filenames())
+	return reader, nil
+}
+
+func (s *SquidLog) createJournalReader() (logs.Source, error) {
+	cfg := s.Journal
+	if s.PersistOffsets {
+		cfg.CursorFile = logs.DefaultJournalCursorFile(cfg)
+	}
+	return logs.NewJournalReader(cfg, s.Logger)
+}
+
 func (s *SquidLog) createParser() error {
 	s.Debug("starting parser creating")
-	lastLine, err := logs.ReadLastLine(s.file.CurrentFilename(), 0)
+	lastLine, err := s.file.LastLine()
 	if err != nil {
 		return fmt.Errorf("read last line: %v", err)
 	}
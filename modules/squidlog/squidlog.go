@@ -29,25 +29,32 @@ func New() *SquidLog {
 	}
 	return &SquidLog{
 		Config: Config{
-			Path:        "/var/log/squid/access.log",
-			ExcludePath: "*.gz",
-			Parser:      cfg,
+			Path:           "/var/log/squid/access.log",
+			ExcludePath:    "*.gz",
+			PersistOffsets: true,
+			Parser:         cfg,
 		},
 	}
 }
 
 type (
 	Config struct {
-		Parser      logs.ParserConfig `yaml:",inline"`
-		Path        string            `yaml:"path"`
-		ExcludePath string            `yaml:"exclude_path"`
+		Parser         logs.ParserConfig   `yaml:",inline"`
+		Source         string              `yaml:"source"`
+		Path           string              `yaml:"path"`
+		ExcludePath    string              `yaml:"exclude_path"`
+		TailAllFiles   bool                `yaml:"tail_all_files"`
+		PersistOffsets bool                `yaml:"persist_offsets"`
+		Backfill       logs.BackfillConfig `yaml:"backfill"`
+		Journal        logs.JournalConfig  `yaml:"journal"`
+		Syslog         logs.SyslogConfig   `yaml:"syslog"`
 	}
 
 	SquidLog struct {
 		module.Base
 		Config `yaml:",inline"`
 
-		file   *logs.Reader
+		file   logs.Source
 		parser logs.Parser
 		line   *logLine
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/netdata/go.d.plugin/agent/module"
+	"github.com/netdata/go.d.plugin/pkg/logs"
 )
 
 type (
@@ -52,6 +53,9 @@ const (
 	prioReqCustomTimeFieldHist // histogram chart per custom time field
 	prioReqURLPattern
 	prioURLPatternStats // 3 charts per url pattern, alphabetical order
+
+	prioSyslogMessages
+	prioBackfillLines
 )
 
 // NOTE: inconsistency with python web_log
@@ -82,6 +86,30 @@ var (
 			{ID: "req_unmatched", Name: "unmatched", Algo: module.Incremental},
 		},
 	}
+	// Syslog
+	syslogMessages = Chart{
+		ID:       "syslog_messages",
+		Title:    "Syslog Messages",
+		Units:    "messages/s",
+		Fam:      "syslog",
+		Ctx:      "web_log.syslog_messages",
+		Priority: prioSyslogMessages,
+		Dims: Dims{
+			{ID: "syslog_received", Name: "received", Algo: module.Incremental},
+			{ID: "syslog_dropped", Name: "dropped", Algo: module.Incremental},
+		},
+	}
+	backfillLines = Chart{
+		ID:       "backfill_lines",
+		Title:    "Backfilled Lines",
+		Units:    "lines/s",
+		Fam:      "backfill",
+		Ctx:      "web_log.backfill_lines",
+		Priority: prioBackfillLines,
+		Dims: Dims{
+			{ID: "backfill_lines", Name: "replayed", Algo: module.Incremental},
+		},
+	}
 	// netdata specific grouping
 	reqTypes = Chart{
 		ID:       "requests_by_type",
@@ -709,6 +737,16 @@ func (w *WebLog) createCharts(line *logLine) error {
 		}
 
 	}
+	if _, ok := w.file.(*logs.SyslogReader); ok {
+		if err := charts.Add(syslogMessages.Copy()); err != nil {
+			return err
+		}
+	}
+	if _, ok := w.file.(*logs.Reader); ok && w.Backfill.Enabled {
+		if err := charts.Add(backfillLines.Copy()); err != nil {
+			return err
+		}
+	}
 	w.charts = charts
 	return nil
 }
@@ -41,10 +41,22 @@ func (w *WebLog) collect() (map[string]int64, error) {
 
 	if n > 0 || err == nil {
 		mx = stm.ToMap(w.mx)
+		w.collectSourceStats(mx)
 	}
 	return mx, err
 }
 
+func (w *WebLog) collectSourceStats(mx map[string]int64) {
+	if reader, ok := w.file.(*logs.SyslogReader); ok {
+		stats := reader.Stats()
+		mx["syslog_received"] = stats.Received
+		mx["syslog_dropped"] = stats.Dropped
+	}
+	if reader, ok := w.file.(*logs.Reader); ok && w.Backfill.Enabled {
+		mx["backfill_lines"] = reader.BackfillStats().Lines
+	}
+}
+
 func (w *WebLog) collectLogLines() (int, error) {
 	logOnce := true
 	var n int
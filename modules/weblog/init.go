@@ -103,18 +103,57 @@ func (w *WebLog) createLogLine() {
 func (w *WebLog) createLogReader() error {
 	w.Cleanup()
 	w.Debug("starting log reader creating")
-	reader, err := logs.Open(w.Path, w.ExcludePath, w.Logger)
+
+	var reader logs.Source
+	var err error
+	switch w.Source {
+	case "", logs.SourceFile:
+		reader, err = w.createFileReader()
+	case logs.SourceJournal:
+		reader, err = w.createJournalReader()
+	case logs.SourceSyslog:
+		reader, err = logs.NewSyslogReader(w.Syslog, w.Logger)
+	default:
+		err = fmt.Errorf("unknown source '%s'", w.Source)
+	}
 	if err != nil {
 		return fmt.Errorf("creating log reader: %v", err)
 	}
-	w.Debugf("created log reader, current file '%s'", reader.CurrentFilename())
+
+	w.Debugf("created log reader, source '%s'", reader.Name())
 	w.file = reader
 	return nil
 }
 
+func (w *WebLog) createFileReader() (logs.Source, error) {
+	cfg := logs.ReaderConfig{
+		Path:        w.Path,
+		ExcludePath: w.ExcludePath,
+		AllFiles:    w.TailAllFiles,
+		Backfill:    w.Backfill,
+	}
+	if w.PersistOffsets {
+		cfg.CheckpointFile = logs.DefaultCheckpointFile(w.Path, w.ExcludePath)
+	}
+	reader, err := logs.NewReader(cfg, w.Logger)
+	if err != nil {
+		return nil, err
+	}
+	w.Debugf("tailing files %v", reader.Filenames())
+	return reader, nil
+}
+
+func (w *WebLog) createJournalReader() (logs.Source, error) {
+	cfg := w.Journal
+	if w.PersistOffsets {
+		cfg.CursorFile = logs.DefaultJournalCursorFile(cfg)
+	}
+	return logs.NewJournalReader(cfg, w.Logger)
+}
+
 func (w *WebLog) createParser() error {
 	w.Debug("starting parser creating")
-	lastLine, err := logs.ReadLastLine(w.file.CurrentFilename(), 0)
+	lastLine, err := w.file.LastLine()
 	if err != nil {
 		return fmt.Errorf("read last line: %v", err)
 	}
@@ -127,6 +166,11 @@ func (w *WebLog) createParser() error {
 	}
 	w.Debugf("created parser: %s", w.parser.Info())
 
+	if _, ok := w.parser.(*logs.W3CParser); ok && bytes.HasPrefix(lastLine, []byte("#")) {
+		// a new W3C log file starts with the directives, there is no entry to verify yet
+		return nil
+	}
+
 	err = w.parser.Parse(lastLine, w.line)
 	if err != nil {
 		return fmt.Errorf("parse last line: %v (%s)", err, string(lastLine))
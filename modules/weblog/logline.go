@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // TODO: it is not clear how to handle "-", current handling is not good
@@ -111,32 +112,34 @@ func (l *logLine) Assign(field string, value string) (err error) {
 	}
 
 	switch field {
-	case "host", "http_host", "v":
+	case "host", "http_host", "v", "cs-host":
 		err = l.assignVhost(value)
-	case "server_port", "p":
+	case "server_port", "p", "s-port":
 		err = l.assignPort(value)
 	case "host:$server_port", "v:%p":
 		err = l.assignVhostWithPort(value)
 	case "scheme":
 		err = l.assignReqScheme(value)
-	case "remote_addr", "a", "h":
+	case "remote_addr", "a", "h", "c-ip":
 		err = l.assignReqClient(value)
 	case "request", "r":
 		err = l.assignRequest(value)
-	case "request_method", "m":
+	case "request_method", "m", "cs-method":
 		err = l.assignReqMethod(value)
-	case "request_uri", "U":
+	case "request_uri", "U", "cs-uri-stem", "cs-uri":
 		err = l.assignReqURL(value)
-	case "server_protocol", "H":
+	case "server_protocol", "H", "cs-version":
 		err = l.assignReqProto(value)
-	case "status", "s", ">s":
+	case "status", "s", ">s", "sc-status":
 		err = l.assignRespCode(value)
-	case "request_length", "I":
+	case "request_length", "I", "cs-bytes":
 		err = l.assignReqSize(value)
-	case "bytes_sent", "body_bytes_sent", "b", "O", "B":
+	case "bytes_sent", "body_bytes_sent", "b", "O", "B", "sc-bytes":
 		err = l.assignRespSize(value)
 	case "request_time", "D":
 		err = l.assignReqProcTime(value)
+	case "time-taken":
+		err = l.assignReqTimeTaken(value)
 	case "upstream_response_time":
 		err = l.assignUpsRespTime(value)
 	case "ssl_protocol":
@@ -318,6 +321,13 @@ func (l *logLine) assignReqProcTime(time string) error {
 		return nil
 	}
 	v, err := strconv.ParseFloat(time, 64)
+	if err != nil {
+		// Go services log durations as "1.5ms"
+		if v, ok := parseDuration(time); ok {
+			l.reqProcTime = v
+			return nil
+		}
+	}
 	if err != nil || !isTimeValid(v) {
 		return fmt.Errorf("assign '%s': %w", time, errBadReqProcTime)
 	}
@@ -325,6 +335,32 @@ func (l *logLine) assignReqProcTime(time string) error {
 	return nil
 }
 
+func (l *logLine) assignReqTimeTaken(time string) error {
+	if time == hyphen {
+		return nil
+	}
+	v, err := strconv.ParseFloat(time, 64)
+	if err != nil || !isTimeValid(v) {
+		return fmt.Errorf("assign '%s': %w", time, errBadReqProcTime)
+	}
+	// W3C time-taken is in seconds, IIS logs it in milliseconds.
+	if strings.IndexByte(time, '.') > 0 {
+		l.reqProcTime = v * 1e6
+	} else {
+		l.reqProcTime = v * 1e3
+	}
+	return nil
+}
+
+// parseDuration parses a Go duration string and returns it in microseconds.
+func parseDuration(s string) (float64, bool) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return float64(d) / float64(time.Microsecond), true
+}
+
 func isUpstreamTimeSeparator(r rune) bool { return r == ',' || r == ':' }
 
 func (l *logLine) assignUpsRespTime(time string) error {
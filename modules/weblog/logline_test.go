@@ -35,6 +35,7 @@ func TestLogLine_Assign(t *testing.T) {
 				"host",
 				"http_host",
 				"v",
+				"cs-host",
 			},
 			cases: []subTest{
 				{input: "1.1.1.1", wantLine: logLine{web: web{vhost: "1.1.1.1"}}},
@@ -54,6 +55,7 @@ func TestLogLine_Assign(t *testing.T) {
 			fields: []string{
 				"server_port",
 				"p",
+				"s-port",
 			},
 			cases: []subTest{
 				{input: "80", wantLine: logLine{web: web{port: "80"}}},
@@ -107,6 +109,7 @@ func TestLogLine_Assign(t *testing.T) {
 				"remote_addr",
 				"a",
 				"h",
+				"c-ip",
 			},
 			cases: []subTest{
 				{input: "1.1.1.1", wantLine: logLine{web: web{reqClient: "1.1.1.1"}}},
@@ -152,6 +155,7 @@ func TestLogLine_Assign(t *testing.T) {
 			fields: []string{
 				"request_method",
 				"m",
+				"cs-method",
 			},
 			cases: []subTest{
 				{input: "GET", wantLine: logLine{web: web{reqMethod: "GET"}}},
@@ -179,6 +183,7 @@ func TestLogLine_Assign(t *testing.T) {
 			fields: []string{
 				"request_uri",
 				"U",
+				"cs-uri-stem",
 			},
 			cases: []subTest{
 				{input: "/server-status?auto", wantLine: logLine{web: web{reqURL: "/server-status?auto"}}},
@@ -193,6 +198,7 @@ func TestLogLine_Assign(t *testing.T) {
 			fields: []string{
 				"server_protocol",
 				"H",
+				"cs-version",
 			},
 			cases: []subTest{
 				{input: "HTTP/1.0", wantLine: logLine{web: web{reqProto: "1.0"}}},
@@ -211,6 +217,7 @@ func TestLogLine_Assign(t *testing.T) {
 				"status",
 				"s",
 				">s",
+				"sc-status",
 			},
 			cases: []subTest{
 				{input: "100", wantLine: logLine{web: web{respCode: 100}}},
@@ -233,6 +240,7 @@ func TestLogLine_Assign(t *testing.T) {
 			fields: []string{
 				"request_length",
 				"I",
+				"cs-bytes",
 			},
 			cases: []subTest{
 				{input: "15", wantLine: logLine{web: web{reqSize: 15}}},
@@ -252,6 +260,7 @@ func TestLogLine_Assign(t *testing.T) {
 				"O",
 				"B",
 				"b",
+				"sc-bytes",
 			},
 			cases: []subTest{
 				{input: "15", wantLine: logLine{web: web{respSize: 15}}},
@@ -272,6 +281,8 @@ func TestLogLine_Assign(t *testing.T) {
 			cases: []subTest{
 				{input: "100222", wantLine: logLine{web: web{reqProcTime: 100222}}},
 				{input: "100.222", wantLine: logLine{web: web{reqProcTime: 100222000}}},
+				{input: "1.5ms", wantLine: logLine{web: web{reqProcTime: 1500}}},
+				{input: "2s", wantLine: logLine{web: web{reqProcTime: 2000000}}},
 				{input: emptyStr, wantLine: emptyLogLine},
 				{input: hyphen, wantLine: emptyLogLine},
 				{input: "-1", wantLine: emptyLogLine, wantErr: errBadReqProcTime},
@@ -279,6 +290,20 @@ func TestLogLine_Assign(t *testing.T) {
 				{input: "number", wantLine: emptyLogLine, wantErr: errBadReqProcTime},
 			},
 		},
+		{
+			name: "Time Taken",
+			fields: []string{
+				"time-taken",
+			},
+			cases: []subTest{
+				{input: "15", wantLine: logLine{web: web{reqProcTime: 15000}}},
+				{input: "0.015", wantLine: logLine{web: web{reqProcTime: 15000}}},
+				{input: emptyStr, wantLine: emptyLogLine},
+				{input: hyphen, wantLine: emptyLogLine},
+				{input: "-1", wantLine: emptyLogLine, wantErr: errBadReqProcTime},
+				{input: "1.5ms", wantLine: emptyLogLine, wantErr: errBadReqProcTime},
+			},
+		},
 		{
 			name: "Upstream Response Time",
 			fields: []string{
@@ -618,34 +643,34 @@ func prepareLogLine(field string, template logLine) logLine {
 	line.reset()
 
 	switch field {
-	case "host", "http_host", "v":
+	case "host", "http_host", "v", "cs-host":
 		line.vhost = template.vhost
-	case "server_port", "p":
+	case "server_port", "p", "s-port":
 		line.port = template.port
 	case "host:$server_port", "v:%p":
 		line.vhost = template.vhost
 		line.port = template.port
 	case "scheme":
 		line.reqScheme = template.reqScheme
-	case "remote_addr", "a", "h":
+	case "remote_addr", "a", "h", "c-ip":
 		line.reqClient = template.reqClient
 	case "request", "r":
 		line.reqMethod = template.reqMethod
 		line.reqURL = template.reqURL
 		line.reqProto = template.reqProto
-	case "request_method", "m":
+	case "request_method", "m", "cs-method":
 		line.reqMethod = template.reqMethod
-	case "request_uri", "U":
+	case "request_uri", "U", "cs-uri-stem", "cs-uri":
 		line.reqURL = template.reqURL
-	case "server_protocol", "H":
+	case "server_protocol", "H", "cs-version":
 		line.reqProto = template.reqProto
-	case "status", "s", ">s":
+	case "status", "s", ">s", "sc-status":
 		line.respCode = template.respCode
-	case "request_length", "I":
+	case "request_length", "I", "cs-bytes":
 		line.reqSize = template.reqSize
-	case "bytes_sent", "body_bytes_sent", "b", "O", "B":
+	case "bytes_sent", "body_bytes_sent", "b", "O", "B", "sc-bytes":
 		line.respSize = template.respSize
-	case "request_time", "D":
+	case "request_time", "D", "time-taken":
 		line.reqProcTime = template.reqProcTime
 	case "upstream_response_time":
 		line.upsRespTime = template.upsRespTime
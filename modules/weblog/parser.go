@@ -3,6 +3,7 @@
 package weblog
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"regexp"
@@ -68,15 +69,49 @@ const (
 )
 
 var (
-	reLTSV = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
-	reJSON = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
+	reLTSV   = regexp.MustCompile(`^[a-zA-Z0-9]+:[^\t]*(\t[a-zA-Z0-9]+:[^\t]*)*$`)
+	reJSON   = regexp.MustCompile(`^[[:space:]]*{.*}[[:space:]]*$`)
+	reLogfmt = regexp.MustCompile(`^[[:space:]]*[^[:space:]="]+=("(\\.|[^"\\])*"|[^[:space:]"]*)([[:space:]]+[^[:space:]="]+(=("(\\.|[^"\\])*"|[^[:space:]"]*))?)*[[:space:]]*$`)
 )
 
+// logfmtMapping maps the common logfmt keys (Go services, Caddy, Traefik) to the known fields.
+// The user defined mapping takes precedence.
+var logfmtMapping = map[string]string{
+	"method":                "request_method",
+	"request>method":        "request_method",
+	"RequestMethod":         "request_method",
+	"uri":                   "request_uri",
+	"url":                   "request_uri",
+	"path":                  "request_uri",
+	"request>uri":           "request_uri",
+	"RequestPath":           "request_uri",
+	"proto":                 "server_protocol",
+	"protocol":              "server_protocol",
+	"request>proto":         "server_protocol",
+	"RequestProtocol":       "server_protocol",
+	"status_code":           "status",
+	"DownstreamStatus":      "status",
+	"size":                  "body_bytes_sent",
+	"bytes":                 "body_bytes_sent",
+	"DownstreamContentSize": "body_bytes_sent",
+	"remote_ip":             "remote_addr",
+	"client_ip":             "remote_addr",
+	"request>remote_ip":     "remote_addr",
+	"ClientHost":            "remote_addr",
+	"request>host":          "host",
+	"RequestHost":           "host",
+	"RequestPort":           "server_port",
+	"RequestScheme":         "scheme",
+	"duration":              "request_time",
+}
+
+var w3cFieldsDirective = []byte("#Fields:")
+
 func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
 	if w.Parser.LogType == typeAuto {
 		w.Debugf("log_type is %s, will try format auto-detection", typeAuto)
 		if len(record) == 0 {
-			return nil, fmt.Errorf("empty line, can't auto-detect format (%s)", w.file.CurrentFilename())
+			return nil, fmt.Errorf("empty line, can't auto-detect format (%s)", w.file.Name())
 		}
 		return w.guessParser(record)
 	}
@@ -92,6 +127,14 @@ func (w *WebLog) newParser(record []byte) (logs.Parser, error) {
 		w.Debugf("config: %+v", w.Parser.RegExp)
 	case logs.TypeJSON:
 		w.Debugf("config: %+v", w.Parser.JSON)
+	case logs.TypeLogfmt:
+		w.Parser.Logfmt.Mapping = withLogfmtMapping(w.Parser.Logfmt.Mapping)
+		w.Debugf("config: %+v", w.Parser.Logfmt)
+	case logs.TypeW3C:
+		if w.Parser.W3C.Fields == "" {
+			w.Parser.W3C.Fields = w.w3cFields(record)
+		}
+		w.Debugf("config: %+v", w.Parser.W3C)
 	}
 	return logs.NewParser(w.Parser, w.file)
 }
@@ -106,6 +149,24 @@ func (w *WebLog) guessParser(record []byte) (logs.Parser, error) {
 		w.Debug("log type is JSON")
 		return logs.NewJSONParser(w.Parser.JSON, w.file)
 	}
+	if fields := w.w3cFields(record); fields != "" {
+		cfg := w.Parser.W3C
+		cfg.Fields = fields
+		parser, err := logs.NewW3CParser(cfg, w.file)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(record, []byte("#")) || parser.Parse(record, newEmptyLogLine()) == nil {
+			w.Debug("log type is W3C")
+			return parser, nil
+		}
+	}
+	if reLogfmt.Match(record) {
+		w.Debug("log type is logfmt")
+		cfg := w.Parser.Logfmt
+		cfg.Mapping = withLogfmtMapping(cfg.Mapping)
+		return logs.NewLogfmtParser(cfg, w.file)
+	}
 	w.Debug("log type is CSV")
 	return w.guessCSVParser(record)
 }
@@ -139,6 +200,33 @@ func (w *WebLog) guessCSVParser(record []byte) (logs.Parser, error) {
 	return nil, errors.New("cannot auto-detect log format, use custom log format")
 }
 
+// w3cFields returns the '#Fields' directive value from the record or the header of the current log file.
+func (w *WebLog) w3cFields(record []byte) string {
+	if bytes.HasPrefix(record, w3cFieldsDirective) {
+		return strings.TrimSpace(string(record[len(w3cFieldsDirective):]))
+	}
+	reader, ok := w.file.(*logs.Reader)
+	if !ok {
+		return ""
+	}
+	fields, err := logs.ReadW3CFields(reader.CurrentFilename())
+	if err != nil {
+		w.Debugf("read w3c fields: %v", err)
+	}
+	return fields
+}
+
+func withLogfmtMapping(mapping map[string]string) map[string]string {
+	m := make(map[string]string, len(logfmtMapping)+len(mapping))
+	for k, v := range logfmtMapping {
+		m[k] = v
+	}
+	for k, v := range mapping {
+		m[k] = v
+	}
+	return m
+}
+
 func checkCSVFormatField(field string) (newName string, offset int, valid bool) {
 	if isTimeField(field) {
 		return "", 1, false
@@ -87,6 +87,10 @@ func TestWebLog_guessParser(t *testing.T) {
 						require.IsType(t, (*logs.CSVParser)(nil), p)
 					case logs.TypeJSON:
 						require.IsType(t, (*logs.JSONParser)(nil), p)
+					case logs.TypeLogfmt:
+						require.IsType(t, (*logs.LogfmtParser)(nil), p)
+					case logs.TypeW3C:
+						require.IsType(t, (*logs.W3CParser)(nil), p)
 					}
 				}
 			})
@@ -35,6 +35,7 @@ func New() *WebLog {
 	return &WebLog{
 		Config: Config{
 			ExcludePath:    "*.gz",
+			PersistOffsets: true,
 			GroupRespCodes: true,
 			Parser:         cfg,
 		},
@@ -56,21 +57,27 @@ type (
 	}
 
 	Config struct {
-		Parser           logs.ParserConfig `yaml:",inline"`
-		Path             string            `yaml:"path"`
-		ExcludePath      string            `yaml:"exclude_path"`
-		URLPatterns      []userPattern     `yaml:"url_patterns"`
-		CustomFields     []customField     `yaml:"custom_fields"`
-		CustomTimeFields []customTimeField `yaml:"custom_time_fields"`
-		Histogram        []float64         `yaml:"histogram"`
-		GroupRespCodes   bool              `yaml:"group_response_codes"`
+		Parser           logs.ParserConfig   `yaml:",inline"`
+		Source           string              `yaml:"source"`
+		Path             string              `yaml:"path"`
+		ExcludePath      string              `yaml:"exclude_path"`
+		TailAllFiles     bool                `yaml:"tail_all_files"`
+		PersistOffsets   bool                `yaml:"persist_offsets"`
+		Backfill         logs.BackfillConfig `yaml:"backfill"`
+		Journal          logs.JournalConfig  `yaml:"journal"`
+		Syslog           logs.SyslogConfig   `yaml:"syslog"`
+		URLPatterns      []userPattern       `yaml:"url_patterns"`
+		CustomFields     []customField       `yaml:"custom_fields"`
+		CustomTimeFields []customTimeField   `yaml:"custom_time_fields"`
+		Histogram        []float64           `yaml:"histogram"`
+		GroupRespCodes   bool                `yaml:"group_response_codes"`
 	}
 
 	WebLog struct {
 		module.Base
 		Config `yaml:",inline"`
 
-		file             *logs.Reader
+		file             logs.Source
 		parser           logs.Parser
 		line             *logLine
 		urlPatterns      []*pattern
@@ -6,6 +6,8 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -456,6 +458,78 @@ func TestWebLog_Collect_CustomLogs(t *testing.T) {
 	testCharts(t, weblog, mx)
 }
 
+func TestWebLog_Collect_W3CLogFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "u_ex220801.log")
+	header := "#Software: Microsoft Internet Information Services 10.0\r\n" +
+		"#Version: 1.0\r\n" +
+		"#Fields: date time s-ip cs-method cs-uri-stem cs-uri-query s-port cs-username c-ip cs-version sc-status sc-bytes time-taken\r\n" +
+		"2022-08-01 10:00:00 10.0.0.1 GET /index.html - 80 - 10.0.0.2 HTTP/1.1 200 612 15\r\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(header), 0644))
+
+	weblog := New()
+	defer weblog.Cleanup()
+	weblog.Path = path
+	weblog.PersistOffsets = false
+	require.True(t, weblog.Init())
+	require.True(t, weblog.Check())
+	require.IsType(t, (*logs.W3CParser)(nil), weblog.parser)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("2022-08-01 10:00:01 10.0.0.1 GET /index.html - 80 - 10.0.0.2 HTTP/1.1 200 612 10\r\n" +
+		"2022-08-01 10:00:02 10.0.0.1 POST /api a=1 80 - 2001:db8::1 HTTP/1.1 500 100 30\r\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	mx := weblog.Collect()
+
+	assert.Equal(t, int64(2), mx["requests"])
+	assert.Equal(t, int64(1), mx["req_method_GET"])
+	assert.Equal(t, int64(1), mx["req_method_POST"])
+	assert.Equal(t, int64(1), mx["resp_code_200"])
+	assert.Equal(t, int64(1), mx["resp_code_500"])
+	assert.Equal(t, int64(1), mx["req_ipv4"])
+	assert.Equal(t, int64(1), mx["req_ipv6"])
+	assert.Equal(t, int64(712), mx["bytes_sent"])
+	assert.Equal(t, int64(10000), mx["req_proc_time_min"])
+	assert.Equal(t, int64(30000), mx["req_proc_time_max"])
+	assert.Equal(t, int64(0), mx["req_unmatched"])
+}
+
+func TestWebLog_Check_ErrorOnBackfillWithoutPersistOffsets(t *testing.T) {
+	weblog := New()
+	defer weblog.Cleanup()
+	weblog.Path = "testdata/common.log"
+	weblog.PersistOffsets = false
+	weblog.Backfill.Enabled = true
+	require.True(t, weblog.Init())
+
+	assert.False(t, weblog.Check())
+}
+
+func TestWebLog_Collect_Backfill(t *testing.T) {
+	defer func(dir string) { logs.CheckpointDir = dir }(logs.CheckpointDir)
+	logs.CheckpointDir = t.TempDir()
+
+	line := `localhost - - [22/Mar/2009:09:30:31 +0100] "POST /example.net HTTP/2" 100 3441` + "\n"
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, ioutil.WriteFile(path+".1", []byte(line+line), 0644))
+	require.NoError(t, ioutil.WriteFile(path, []byte(line), 0644))
+
+	weblog := New()
+	defer weblog.Cleanup()
+	weblog.Path = path
+	weblog.Backfill.Enabled = true
+	require.True(t, weblog.Init())
+	require.True(t, weblog.Check())
+	require.NotNil(t, weblog.Charts().Get(backfillLines.ID))
+
+	mx := weblog.Collect()
+
+	assert.Equal(t, int64(3), mx["requests"])
+	assert.Equal(t, int64(2), mx["backfill_lines"])
+}
+
 func TestWebLog_Collect_CustomTimeFieldsLogs(t *testing.T) {
 	weblog := prepareWebLogCollectCustomTimeFields(t)
 
@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxBackfillBatch is the max number of backfilled bytes returned between two io.EOF,
+// it spreads a large backfill over several data collections.
+const maxBackfillBatch = 4 * 1024 * 1024
+
+// BackfillConfig is a rotated files backfill configuration.
+type BackfillConfig struct {
+	// Enabled makes the Reader replay the rotated files (plain, gzip or zstd compressed) that were not read
+	// ahead of the live files.
+	Enabled bool `yaml:"enabled"`
+	// MaxAge limits the backfill to the files modified within the duration, no limit if not set.
+	MaxAge web.Duration `yaml:"max_age"`
+	// MaxFiles limits the backfill to the most recent rotated files of a live file, no limit if not set.
+	MaxFiles int `yaml:"max_files"`
+}
+
+// BackfillStats is the rotated files backfill progress.
+type BackfillStats struct {
+	Files int
+	Lines int64
+	Done  bool
+}
+
+type (
+	backfill struct {
+		files   []backfillFile
+		current *backfillReader
+		batch   int
+		stats   BackfillStats
+		// progress is the position of the replayed lines returned by the Reader, per live file.
+		progress map[string]*backfillCheckpoint
+	}
+	backfillFile struct {
+		name  string
+		inode uint64
+		// live is the live file the rotated file belongs to.
+		live string
+		// last is whether it is the last rotated file replayed for the live file.
+		last bool
+		// skip is the number of (decompressed) bytes that were read before the file was rotated.
+		skip int64
+	}
+	backfillReader struct {
+		io.Closer
+		*bufio.Reader
+		file backfillFile
+		// pos is the (decompressed) offset of the end of the read lines.
+		pos int64
+	}
+)
+
+func (b *backfill) empty() bool {
+	return len(b.files) == 0 && b.current == nil
+}
+
+// readLines returns the next batch of lines or nil if the batch limit is reached or the backfill is done.
+// The returned pending are the replayed files the lines belong to, in the lines order. A file that has no lines left
+// (or can't be opened) has a pending with no lines, it is replayed once the preceding lines are returned.
+func (b *backfill) readLines(log func(format string, a ...interface{})) ([]byte, []pendingLines) {
+	if b.batch >= maxBackfillBatch {
+		b.batch = 0
+		return nil, nil
+	}
+
+	var lines []byte
+	var pending []pendingLines
+	for len(lines) < readChunkSize && !b.empty() {
+		if b.current == nil {
+			f := b.files[0]
+			b.files = b.files[1:]
+			r, err := openBackfillFile(f)
+			if err != nil {
+				log("backfill: skip file '%s': %v", f.name, err)
+				pending = append(pending, pendingLines{file: &f, end: f.skip, eof: true})
+				continue
+			}
+			b.current = r
+			b.stats.Files++
+		}
+
+		cur := b.current
+		line, err := cur.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] != '\n' {
+			line = append(line, '\n')
+		}
+		lines = append(lines, line...)
+		cur.pos += int64(len(line))
+		if err != nil {
+			_ = cur.Close()
+			b.current = nil
+		}
+
+		if n := len(pending); n > 0 && pending[n-1].file == &cur.file {
+			pending[n-1].left += len(line)
+			pending[n-1].end = cur.pos
+			pending[n-1].eof = err != nil
+		} else {
+			pending = append(pending, pendingLines{file: &cur.file, left: len(line), end: cur.pos, eof: err != nil})
+		}
+	}
+
+	b.batch += len(lines)
+	if b.empty() {
+		b.stats.Done = true
+	}
+	return lines, pending
+}
+
+// consumed moves the replayed position after the data returned by the Reader, data ends at the file offset.
+func (b *backfill) consumed(p *pendingLines, offset int64, data []byte) {
+	b.stats.Lines += int64(bytes.Count(data, []byte{'\n'}))
+	b.progress[p.file.live] = &backfillCheckpoint{
+		File:   p.file.name,
+		Inode:  p.file.inode,
+		Offset: offset,
+		Done:   p.file.last && p.eof && p.left == 0,
+	}
+}
+
+func (b *backfill) close() {
+	if b.current != nil {
+		_ = b.current.Close()
+		b.current = nil
+	}
+	b.files = nil
+}
+
+func openBackfillFile(bf backfillFile) (*backfillReader, error) {
+	f, err := os.Open(bf.name)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = f
+	var closer io.Closer = f
+	switch {
+	case strings.HasSuffix(bf.name, ".gz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		r = gz
+	case strings.HasSuffix(bf.name, ".zst"), strings.HasSuffix(bf.name, ".zstd"):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		r = zr
+		closer = closerFunc(func() error { zr.Close(); return f.Close() })
+	}
+
+	// the skip is past the end if the last line had no new line
+	if bf.skip > 0 {
+		if _, err := io.CopyN(ioutil.Discard, r, bf.skip); err != nil && err != io.EOF {
+			_ = closer.Close()
+			return nil, err
+		}
+	}
+	return &backfillReader{Closer: closer, Reader: bufio.NewReader(r), file: bf, pos: bf.skip}, nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+// rotatedFiles returns the rotated files of the live file that need to be replayed, oldest first.
+// The rotated files are found by the live file name followed by a dot or a dash (access.log.1, access.log.2.gz,
+// access.log-20220101.zst). If there is a checkpoint, only the checkpointed file and the files modified after
+// the checkpoint are replayed, and the bytes read before the rotation are skipped.
+func rotatedFiles(name string, cfg BackfillConfig, cp *checkpoint, now time.Time) []backfillFile {
+	var names []string
+	for _, pattern := range []string{name + ".*", name + "-*"} {
+		matches, _ := filepath.Glob(pattern)
+		names = append(names, matches...)
+	}
+
+	type candidate struct {
+		name  string
+		fi    os.FileInfo
+		inode uint64
+	}
+	var files []candidate
+	var limited bool
+	for _, n := range names {
+		if strings.HasSuffix(n, ".tmp") {
+			continue
+		}
+		fi, err := os.Stat(n)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		// the checkpointed file may have unread lines written before the checkpoint
+		if cp != nil && !cp.Time.IsZero() && fi.ModTime().Before(cp.Time) && fileInode(fi) != cp.Inode {
+			continue
+		}
+		if cfg.MaxAge.Duration > 0 && now.Sub(fi.ModTime()) > cfg.MaxAge.Duration {
+			limited = true
+			continue
+		}
+		files = append(files, candidate{name: n, fi: fi, inode: fileInode(fi)})
+	}
+
+	// the least recently modified first, access.log.2 goes before access.log.1 on a tie
+	sort.SliceStable(files, func(i, j int) bool {
+		if mi, mj := files[i].fi.ModTime(), files[j].fi.ModTime(); !mi.Equal(mj) {
+			return mi.Before(mj)
+		}
+		return files[i].name > files[j].name
+	})
+	if cfg.MaxFiles > 0 && len(files) > cfg.MaxFiles {
+		files = files[len(files)-cfg.MaxFiles:]
+		limited = true
+	}
+
+	bfs := make([]backfillFile, 0, len(files))
+	skipped := cp == nil
+	for _, f := range files {
+		bfs = append(bfs, backfillFile{name: f.name, inode: f.inode})
+		if !skipped && cp.Inode != 0 && f.inode == cp.Inode {
+			bfs[len(bfs)-1].skip = cp.Offset
+			skipped = true
+		}
+	}
+	// the file that was read before the rotation is compressed, assume it is the oldest one
+	if !skipped && !limited && len(bfs) > 0 {
+		bfs[0].skip = cp.Offset
+	}
+	return bfs
+}
+
+// resumedFiles returns the rotated files of an interrupted backfill starting from the replayed position,
+// the files in exclude are left out. The position file is looked up by the inode and then by the name,
+// all the files are returned if it is not found (removed by the log rotation).
+func resumedFiles(files []backfillFile, pos backfillCheckpoint, exclude []backfillFile) []backfillFile {
+	idx := -1
+	for i, f := range files {
+		if pos.Inode != 0 && f.inode == pos.Inode {
+			idx = i
+			break
+		}
+	}
+	for i := 0; idx < 0 && i < len(files); i++ {
+		if files[i].name == pos.File {
+			idx = i
+		}
+	}
+	if idx >= 0 {
+		files = files[idx:]
+		files[0].skip = pos.Offset
+	}
+
+	res := files[:0]
+	for _, f := range files {
+		excluded := false
+		for _, e := range exclude {
+			excluded = excluded || e.name == f.name
+		}
+		if !excluded {
+			res = append(res, f)
+		}
+	}
+	return res
+}
@@ -0,0 +1,261 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/netdata/go.d.plugin/pkg/web"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Backfill(t *testing.T) {
+	tests := map[string]struct {
+		cfg       BackfillConfig
+		wantLines []string
+	}{
+		"all rotated files": {
+			cfg: BackfillConfig{Enabled: true},
+			wantLines: []string{
+				"zst 0", "zst 1",
+				"gz 0", "gz 1",
+				"plain 0", "plain 1",
+				"live 0", "live 1",
+			},
+		},
+		"max files": {
+			cfg: BackfillConfig{Enabled: true, MaxFiles: 1},
+			wantLines: []string{
+				"plain 0", "plain 1",
+				"live 0", "live 1",
+			},
+		},
+		"max age": {
+			cfg: BackfillConfig{Enabled: true, MaxAge: web.Duration{Duration: time.Minute * 90}},
+			wantLines: []string{
+				"gz 0", "gz 1",
+				"plain 0", "plain 1",
+				"live 0", "live 1",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "access.log")
+			now := time.Now()
+			writeRotatedFile(t, filename+".3.zst", "zst", now.Add(-time.Hour*3))
+			writeRotatedFile(t, filename+".2.gz", "gz", now.Add(-time.Hour*1))
+			writeRotatedFile(t, filename+".1", "plain", now.Add(-time.Minute))
+			writeFile(t, filename, "live 0\nlive 1\n")
+
+			cfg := ReaderConfig{
+				Path:           filename,
+				CheckpointFile: filepath.Join(dir, "checkpoints.json"),
+				Backfill:       test.cfg,
+			}
+			reader, err := NewReader(cfg, nil)
+			require.NoError(t, err)
+			defer func() { _ = reader.Close() }()
+
+			assert.Equal(t, test.wantLines, readLines(t, reader))
+			stats := reader.BackfillStats()
+			assert.True(t, stats.Done)
+			assert.Equal(t, int64(len(test.wantLines)-2), stats.Lines)
+		})
+	}
+}
+
+func TestReader_Backfill_ResumesInterrupted(t *testing.T) {
+	allLines := []string{
+		"zst 0", "zst 1",
+		"gz 0", "gz 1",
+		"plain 0", "plain 1",
+		"live 0", "live 1",
+	}
+	tests := map[string]struct {
+		read   int
+		rotate bool
+	}{
+		"nothing read":                    {read: 0},
+		"in the middle":                   {read: len("zst 0\nzst 1\ngz 0\ngz")},
+		"at the file end":                 {read: len("zst 0\nzst 1\n")},
+		"all rotated files":               {read: len("zst 0\nzst 1\ngz 0\ngz 1\nplain 0\nplain 1\n")},
+		"in the middle, live rotated":     {read: len("zst 0\nzst 1\ngz 0\ngz"), rotate: true},
+		"all rotated files, live rotated": {read: len("zst 0\nzst 1\ngz 0\ngz 1\nplain 0\nplain 1\n"), rotate: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "access.log")
+			now := time.Now()
+			writeRotatedFile(t, filename+".3.zst", "zst", now.Add(-time.Hour*3))
+			writeRotatedFile(t, filename+".2.gz", "gz", now.Add(-time.Hour*1))
+			writeRotatedFile(t, filename+".1", "plain", now.Add(-time.Minute))
+			writeFile(t, filename, "live 0\nlive 1\n")
+			cfg := ReaderConfig{
+				Path:           filename,
+				CheckpointFile: filepath.Join(dir, "checkpoints.json"),
+				Backfill:       BackfillConfig{Enabled: true},
+			}
+
+			reader, err := NewReader(cfg, nil)
+			require.NoError(t, err)
+			var lines []string
+			if test.read > 0 {
+				p := make([]byte, test.read)
+				n, err := reader.Read(p)
+				require.NoError(t, err)
+				require.Equal(t, test.read, n)
+				for _, line := range strings.SplitAfter(string(p), "\n") {
+					if strings.HasSuffix(line, "\n") {
+						lines = append(lines, strings.TrimSuffix(line, "\n"))
+					}
+				}
+			}
+			counted := reader.BackfillStats().Lines
+			require.NoError(t, reader.Close())
+
+			wantLines, wantCounted := allLines, int64(len(allLines)-2)
+			if test.rotate {
+				require.NoError(t, os.Rename(filename, filename+".0"))
+				writeFile(t, filename, "new 0\n")
+				wantLines, wantCounted = append(allLines, "new 0"), int64(len(allLines))
+			}
+
+			reader, err = NewReader(cfg, nil)
+			require.NoError(t, err)
+			lines = append(lines, readLines(t, reader)...)
+			counted += reader.BackfillStats().Lines
+			require.NoError(t, reader.Close())
+
+			assert.Equal(t, wantLines, lines)
+			assert.Equal(t, wantCounted, counted)
+
+			// the backfill is done, nothing is replayed again
+			reader, err = NewReader(cfg, nil)
+			require.NoError(t, err)
+			defer func() { _ = reader.Close() }()
+			assert.Empty(t, readLines(t, reader))
+			assert.Equal(t, int64(0), reader.BackfillStats().Lines)
+		})
+	}
+}
+
+func TestReader_Backfill_NoCheckpoint(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "access.log")
+	writeFile(t, filename, "live 0\n")
+
+	_, err := NewReader(ReaderConfig{Path: filename, Backfill: BackfillConfig{Enabled: true}}, nil)
+
+	assert.Equal(t, ErrBackfillNoCheckpoint, err)
+}
+
+func TestReader_Backfill_ResumesFromCheckpoint(t *testing.T) {
+	tests := map[string]struct {
+		rotate func(t *testing.T, filename string)
+	}{
+		"renamed": {
+			rotate: func(t *testing.T, filename string) {
+				require.NoError(t, os.Rename(filename, filename+".1"))
+			},
+		},
+		"renamed and compressed": {
+			rotate: func(t *testing.T, filename string) {
+				bs, err := ioutil.ReadFile(filename)
+				require.NoError(t, err)
+				writeCompressed(t, filename+".1.gz", bs)
+				require.NoError(t, os.Remove(filename))
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "access.log")
+			cfg := ReaderConfig{
+				Path:           filename,
+				CheckpointFile: filepath.Join(dir, "checkpoints.json"),
+				Backfill:       BackfillConfig{Enabled: true},
+			}
+			writeFile(t, filename, "")
+
+			reader, err := NewReader(cfg, nil)
+			require.NoError(t, err)
+			writeFile(t, filename, "read 0\nread 1\n")
+			assert.Equal(t, []string{"read 0", "read 1"}, readLines(t, reader))
+			require.NoError(t, reader.Close())
+
+			// lines written and the file rotated while the reader was stopped
+			writeFile(t, filename, "missed 0\nmissed 1\n")
+			test.rotate(t, filename)
+			writeFile(t, filename, "new 0\n")
+
+			reader, err = NewReader(cfg, nil)
+			require.NoError(t, err)
+			defer func() { _ = reader.Close() }()
+
+			assert.Equal(t, []string{"missed 0", "missed 1", "new 0"}, readLines(t, reader))
+			assert.Equal(t, int64(2), reader.BackfillStats().Lines)
+		})
+	}
+}
+
+func readLines(t *testing.T, r io.Reader) (lines []string) {
+	t.Helper()
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	require.NoError(t, sc.Err())
+	return lines
+}
+
+func writeRotatedFile(t *testing.T, filename, prefix string, mtime time.Time) {
+	t.Helper()
+	var b bytes.Buffer
+	for i := 0; i < 2; i++ {
+		fmt.Fprintf(&b, "%s %d\n", prefix, i)
+	}
+	writeCompressed(t, filename, b.Bytes())
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+}
+
+func writeCompressed(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var w io.WriteCloser
+	switch filepath.Ext(filename) {
+	case ".gz":
+		w = gzip.NewWriter(f)
+	case ".zst":
+		w, err = zstd.NewWriter(f)
+		require.NoError(t, err)
+	default:
+		_, err = f.Write(data)
+		require.NoError(t, err)
+		return
+	}
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
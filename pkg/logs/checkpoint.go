@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckpointDir is the directory the read offsets are persisted to by DefaultCheckpointFile.
+// It is under the NETDATA_LIB_DIR, persisting is disabled if the variable is not set.
+var CheckpointDir = func() string {
+	if dir := os.Getenv("NETDATA_LIB_DIR"); dir != "" {
+		return filepath.Join(dir, "god-logs-checkpoints")
+	}
+	return ""
+}()
+
+// DefaultCheckpointFile returns the CheckpointDir file for a reader with the given path and exclude path.
+// It returns an empty string if the CheckpointDir is not set.
+func DefaultCheckpointFile(path, excludePath string) string {
+	if CheckpointDir == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return checkpointFile(path + "\x00" + excludePath)
+}
+
+func checkpointFile(key string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return filepath.Join(CheckpointDir, fmt.Sprintf("%x.json", h.Sum64()))
+}
+
+type checkpoint struct {
+	Inode  uint64    `json:"inode"`
+	Offset int64     `json:"offset"`
+	Time   time.Time `json:"time"`
+	// Backfill is the rotated files backfill position of the file, set if there was a backfill.
+	Backfill *backfillCheckpoint `json:"backfill,omitempty"`
+}
+
+// backfillCheckpoint is the replayed rotated file and the (decompressed) offset of the lines returned by the Reader.
+// Done is set once all the rotated files are replayed.
+type backfillCheckpoint struct {
+	File   string `json:"file"`
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+	Done   bool   `json:"done"`
+}
+
+func loadCheckpoints(path string) (map[string]checkpoint, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cps map[string]checkpoint
+	return cps, json.Unmarshal(bs, &cps)
+}
+
+func saveCheckpoints(path string, cps map[string]checkpoint) error {
+	return writeJSONFile(path, cps)
+}
+
+// writeJSONFile writes the value to a temporary file and renames it, so the file is never partially written.
+func writeJSONFile(path string, v interface{}) error {
+	bs, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, bs, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import "os"
+
+// fileInode returns 0, the checkpoint of a file is matched by its name only.
+func fileInode(os.FileInfo) uint64 {
+	return 0
+}
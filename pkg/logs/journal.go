@@ -0,0 +1,433 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const (
+	// journalRestartEvery is how often an exited journalctl is restarted.
+	journalRestartEvery = time.Second * 10
+	// journalQueueSize is the max number of messages buffered between the journalctl output and the Read.
+	journalQueueSize = 1024
+	// maxJournalFieldSize is the max size of a binary journal export field.
+	maxJournalFieldSize = 1024 * 1024
+)
+
+// JournalConfig is a JournalReader configuration.
+type JournalConfig struct {
+	// Units filters the entries by the _SYSTEMD_UNIT field, ".service" is appended to the names without a suffix.
+	Units []string `yaml:"units"`
+	// Identifiers filters the entries by the SYSLOG_IDENTIFIER field.
+	Identifiers []string `yaml:"identifiers"`
+	// Directory is the journal files directory, the system and user journals are read if not set.
+	Directory string `yaml:"directory"`
+	// CursorFile is the file the journal cursor is persisted to, it is not persisted if not set.
+	CursorFile string `yaml:"-"`
+}
+
+// DefaultJournalCursorFile returns the CheckpointDir file for a JournalReader with the given matches.
+// It returns an empty string if the CheckpointDir is not set.
+func DefaultJournalCursorFile(cfg JournalConfig) string {
+	if CheckpointDir == "" {
+		return ""
+	}
+	return checkpointFile("journal\x00" + cfg.Directory + "\x00" + strings.Join(journalMatches(cfg), "\x00"))
+}
+
+// JournalReader follows the systemd journal using 'journalctl --output=export' and returns
+// the MESSAGE field of the matched entries, one per line. The cursor of the last returned entry
+// is persisted, after a restart the journal is followed from it.
+type JournalReader struct {
+	directory   string
+	matches     []string
+	units       map[string]bool
+	identifiers map[string]bool
+	cursorFile  string
+	log         *logger.Logger
+
+	run       func(args []string) (io.ReadCloser, error)
+	proc      io.ReadCloser
+	queue     chan journalLine
+	stop      chan struct{}
+	procErr   error
+	lastStart time.Time
+
+	cursor   string
+	lastSave time.Time
+	lines    []byte
+	buf      []byte
+	// pending are the cursors of the buffered messages, in order, with the buffer offsets they end at.
+	pending  []journalPending
+	consumed int
+}
+
+type journalLine struct {
+	cursor string
+	line   []byte
+}
+
+type journalPending struct {
+	cursor string
+	end    int
+}
+
+// NewJournalReader creates a JournalReader and starts following the journal.
+func NewJournalReader(cfg JournalConfig, log *logger.Logger) (*JournalReader, error) {
+	return newJournalReader(cfg, log, execJournalctl)
+}
+
+func newJournalReader(cfg JournalConfig, log *logger.Logger, run func(args []string) (io.ReadCloser, error)) (*JournalReader, error) {
+	j := &JournalReader{
+		directory:   cfg.Directory,
+		matches:     journalMatches(cfg),
+		units:       make(map[string]bool),
+		identifiers: make(map[string]bool),
+		cursorFile:  cfg.CursorFile,
+		log:         log,
+		run:         run,
+	}
+	for _, u := range cfg.Units {
+		j.units[unitName(u)] = true
+	}
+	for _, id := range cfg.Identifiers {
+		j.identifiers[id] = true
+	}
+
+	if j.cursorFile != "" {
+		cursor, err := loadJournalCursor(j.cursorFile)
+		if err != nil {
+			j.log.Warningf("load journal cursor from '%s': %v", j.cursorFile, err)
+		}
+		j.cursor = cursor
+	}
+
+	if err := j.start(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Name returns the journal matches.
+func (j *JournalReader) Name() string {
+	if len(j.matches) == 0 {
+		return "journal"
+	}
+	return fmt.Sprintf("journal (%s)", strings.Join(j.matches, " "))
+}
+
+// LastLine returns the message of the last matched journal entry.
+func (j *JournalReader) LastLine() ([]byte, error) {
+	args := append(j.args(), "--lines=1")
+	out, err := j.run(args)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = out.Close() }()
+
+	var last []byte
+	dec := newJournalExportDecoder(out)
+	for {
+		entry, err := dec.decode()
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if msg, ok := j.message(entry); ok {
+			last = msg
+		}
+	}
+}
+
+func (j *JournalReader) Read(p []byte) (n int, err error) {
+	if len(j.buf) == 0 {
+		if err = j.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, j.buf)
+	j.buf = j.buf[n:]
+	j.consume(n)
+	return n, nil
+}
+
+// consume advances the cursor past the messages whose bytes have been all returned by Read.
+func (j *JournalReader) consume(n int) {
+	j.consumed += n
+	for len(j.pending) > 0 && j.pending[0].end <= j.consumed {
+		j.cursor = j.pending[0].cursor
+		j.pending = j.pending[1:]
+	}
+}
+
+func (j *JournalReader) fill() error {
+	if j.proc == nil && time.Since(j.lastStart) >= journalRestartEvery {
+		if err := j.start(); err != nil {
+			j.log.Warningf("restart journalctl: %v", err)
+		}
+	}
+
+	j.lines = j.lines[:0]
+	j.pending, j.consumed = j.pending[:0], 0
+loop:
+	for j.queue != nil && len(j.lines) < readChunkSize {
+		select {
+		case l, ok := <-j.queue:
+			if !ok {
+				j.log.Warningf("journalctl exited: %v", j.procErr)
+				j.stopProc()
+				break loop
+			}
+			j.lines = append(j.lines, l.line...)
+			j.pending = append(j.pending, journalPending{cursor: l.cursor, end: len(j.lines)})
+		default:
+			break loop
+		}
+	}
+	j.buf = j.lines
+
+	j.saveCursor(false)
+	if len(j.buf) == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+func (j *JournalReader) start() error {
+	j.lastStart = time.Now()
+
+	args := append(j.args(), "--follow")
+	if j.cursor != "" {
+		args = append(args, "--after-cursor="+j.cursor)
+	} else {
+		args = append(args, "--lines=0")
+	}
+	j.log.Debugf("start journalctl %s", strings.Join(args, " "))
+
+	out, err := j.run(args)
+	if err != nil {
+		return err
+	}
+
+	j.proc = out
+	j.queue = make(chan journalLine, journalQueueSize)
+	j.stop = make(chan struct{})
+	j.procErr = nil
+	go j.decode(out, j.queue, j.stop)
+	return nil
+}
+
+func (j *JournalReader) decode(out io.Reader, queue chan journalLine, stop chan struct{}) {
+	defer close(queue)
+
+	dec := newJournalExportDecoder(out)
+	for {
+		entry, err := dec.decode()
+		if err != nil {
+			j.procErr = err
+			return
+		}
+		msg, ok := j.message(entry)
+		if !ok {
+			continue
+		}
+		select {
+		case queue <- journalLine{cursor: entry["__CURSOR"], line: append(msg, '\n')}:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (j *JournalReader) stopProc() {
+	if j.proc == nil {
+		return
+	}
+	close(j.stop)
+	_ = j.proc.Close()
+	// wait for the decoder to exit
+	for range j.queue {
+	}
+	j.proc, j.queue, j.stop = nil, nil, nil
+}
+
+func (j *JournalReader) args() []string {
+	args := []string{"--output=export", "--no-pager", "--quiet"}
+	if j.directory != "" {
+		args = append(args, "--directory="+j.directory)
+	}
+	return append(args, j.matches...)
+}
+
+// message returns the entry MESSAGE if the entry is matched, new lines are replaced with spaces.
+func (j *JournalReader) message(entry map[string]string) ([]byte, bool) {
+	if len(j.units) > 0 && !j.units[entry["_SYSTEMD_UNIT"]] {
+		return nil, false
+	}
+	if len(j.identifiers) > 0 && !j.identifiers[entry["SYSLOG_IDENTIFIER"]] {
+		return nil, false
+	}
+	msg, ok := entry["MESSAGE"]
+	if !ok {
+		return nil, false
+	}
+	return []byte(strings.ReplaceAll(msg, "\n", " ")), true
+}
+
+func (j *JournalReader) saveCursor(force bool) {
+	if j.cursorFile == "" || j.cursor == "" || (!force && time.Since(j.lastSave) < checkpointEvery) {
+		return
+	}
+	j.lastSave = time.Now()
+	if err := writeJSONFile(j.cursorFile, journalCursor{Cursor: j.cursor}); err != nil {
+		j.log.Warningf("save journal cursor to '%s': %v", j.cursorFile, err)
+	}
+}
+
+func (j *JournalReader) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.stopProc()
+	j.saveCursor(true)
+	j.buf, j.pending = nil, nil
+	return nil
+}
+
+// journalMatches returns the journalctl matches, the matches of the same field are combined with a logical OR,
+// the matches of different fields with a logical AND.
+func journalMatches(cfg JournalConfig) []string {
+	var matches []string
+	for _, u := range cfg.Units {
+		matches = append(matches, "_SYSTEMD_UNIT="+unitName(u))
+	}
+	for _, id := range cfg.Identifiers {
+		matches = append(matches, "SYSLOG_IDENTIFIER="+id)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func unitName(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return name + ".service"
+}
+
+type journalCursor struct {
+	Cursor string `json:"cursor"`
+}
+
+func loadJournalCursor(path string) (string, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var c journalCursor
+	return c.Cursor, json.Unmarshal(bs, &c)
+}
+
+func execJournalctl(args []string) (io.ReadCloser, error) {
+	cmd := exec.Command("journalctl", args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &cmdOutput{ReadCloser: out, cmd: cmd}, nil
+}
+
+type cmdOutput struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdOutput) Close() error {
+	_ = c.cmd.Process.Kill()
+	return c.cmd.Wait()
+}
+
+// journalExportDecoder decodes the journal export format (https://systemd.io/JOURNAL_EXPORT_FORMATS/).
+type journalExportDecoder struct {
+	r *bufio.Reader
+}
+
+func newJournalExportDecoder(r io.Reader) *journalExportDecoder {
+	return &journalExportDecoder{r: bufio.NewReader(r)}
+}
+
+var errJournalFieldTooLarge = errors.New("journal export: field is too large")
+
+// decode returns the next entry fields.
+func (d *journalExportDecoder) decode() (map[string]string, error) {
+	entry := make(map[string]string)
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF && len(entry) > 0 && len(line) == 0 {
+				return entry, nil
+			}
+			if err == io.EOF && len(line) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = line[:len(line)-1]
+
+		if len(line) == 0 {
+			if len(entry) == 0 {
+				continue
+			}
+			return entry, nil
+		}
+
+		if i := bytes.IndexByte(line, '='); i >= 0 {
+			entry[string(line[:i])] = string(line[i+1:])
+			continue
+		}
+
+		// binary field: the name, a new line, the little endian 64-bit data size, the data, a new line
+		var size uint64
+		if err = binary.Read(d.r, binary.LittleEndian, &size); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if size > maxJournalFieldSize {
+			return nil, errJournalFieldTooLarge
+		}
+		data := make([]byte, size+1)
+		if _, err = io.ReadFull(d.r, data); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		entry[string(line)] = string(data[:size])
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalExportDecoder_decode(t *testing.T) {
+	f, err := ioutil.ReadFile("testdata/journal.export")
+	require.NoError(t, err)
+
+	dec := newJournalExportDecoder(bytes.NewReader(f))
+	var cursors []string
+	var messages []string
+	for {
+		entry, err := dec.decode()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		cursors = append(cursors, entry["__CURSOR"])
+		messages = append(messages, entry["MESSAGE"])
+	}
+
+	assert.Equal(t, []string{"s=1;i=1", "s=1;i=2", "s=1;i=3", "s=1;i=4", "s=1;i=5"}, cursors)
+	assert.Equal(t, `127.0.0.1 - - [01/Aug/2022:10:00:01 +0000] "GET /multi`+"\n"+`line HTTP/1.1" 404 0`, messages[2])
+}
+
+func TestJournalExportDecoder_decode_UnexpectedEOF(t *testing.T) {
+	f, err := ioutil.ReadFile("testdata/journal.export")
+	require.NoError(t, err)
+	idx := bytes.Index(f, []byte("MESSAGE\n"))
+	require.True(t, idx > 0)
+
+	dec := newJournalExportDecoder(bytes.NewReader(f[:idx+len("MESSAGE\n")+4]))
+	for i := 0; i < 2; i++ {
+		_, err = dec.decode()
+		require.NoError(t, err)
+	}
+	_, err = dec.decode()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestJournalReader_Read(t *testing.T) {
+	tests := map[string]struct {
+		cfg       JournalConfig
+		wantArgs  []string
+		wantLines []string
+	}{
+		"no matches": {
+			cfg:      JournalConfig{},
+			wantArgs: []string{"--output=export", "--no-pager", "--quiet", "--follow", "--lines=0"},
+			wantLines: []string{
+				`127.0.0.1 - - [01/Aug/2022:10:00:00 +0000] "GET / HTTP/1.1" 200 612`,
+				"Accepted publickey for root",
+				`127.0.0.1 - - [01/Aug/2022:10:00:01 +0000] "GET /multi line HTTP/1.1" 404 0`,
+				"upstream timed out",
+				`127.0.0.1 - - [01/Aug/2022:10:00:02 +0000] "POST /api HTTP/1.1" 201 12`,
+			},
+		},
+		"unit and identifier": {
+			cfg: JournalConfig{Units: []string{"nginx"}, Identifiers: []string{"nginx"}, Directory: "/var/log/journal"},
+			wantArgs: []string{
+				"--output=export", "--no-pager", "--quiet", "--directory=/var/log/journal",
+				"SYSLOG_IDENTIFIER=nginx", "_SYSTEMD_UNIT=nginx.service", "--follow", "--lines=0",
+			},
+			wantLines: []string{
+				`127.0.0.1 - - [01/Aug/2022:10:00:00 +0000] "GET / HTTP/1.1" 200 612`,
+				`127.0.0.1 - - [01/Aug/2022:10:00:01 +0000] "GET /multi line HTTP/1.1" 404 0`,
+				`127.0.0.1 - - [01/Aug/2022:10:00:02 +0000] "POST /api HTTP/1.1" 201 12`,
+			},
+		},
+		"unit": {
+			cfg:      JournalConfig{Units: []string{"sshd.service"}},
+			wantArgs: []string{"--output=export", "--no-pager", "--quiet", "_SYSTEMD_UNIT=sshd.service", "--follow", "--lines=0"},
+			wantLines: []string{
+				"Accepted publickey for root",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := newTestJournalctl(t)
+			j, err := newJournalReader(test.cfg, nil, run.run)
+			require.NoError(t, err)
+			defer func() { _ = j.Close() }()
+
+			assert.Equal(t, test.wantLines, readJournalLines(t, j))
+			assert.Equal(t, test.wantArgs, run.args[0])
+		})
+	}
+}
+
+func TestJournalReader_Read_ResumesFromCursor(t *testing.T) {
+	cfg := JournalConfig{
+		Units:      []string{"nginx"},
+		CursorFile: filepath.Join(t.TempDir(), "cursor.json"),
+	}
+
+	run := newTestJournalctl(t)
+	j, err := newJournalReader(cfg, nil, run.run)
+	require.NoError(t, err)
+	assert.Len(t, readJournalLines(t, j), 4)
+	require.NoError(t, j.Close())
+
+	run = newTestJournalctl(t)
+	j, err = newJournalReader(cfg, nil, run.run)
+	require.NoError(t, err)
+	defer func() { _ = j.Close() }()
+
+	assert.Contains(t, run.args[0], "--after-cursor=s=1;i=5")
+	assert.NotContains(t, run.args[0], "--lines=0")
+}
+
+func TestJournalReader_Close_SavesReturnedCursor(t *testing.T) {
+	cfg := JournalConfig{
+		Units:      []string{"nginx"},
+		CursorFile: filepath.Join(t.TempDir(), "cursor.json"),
+	}
+
+	run := newTestJournalctl(t)
+	j, err := newJournalReader(cfg, nil, run.run)
+	require.NoError(t, err)
+
+	first := `127.0.0.1 - - [01/Aug/2022:10:00:00 +0000] "GET / HTTP/1.1" 200 612` + "\n"
+	var p []byte
+	for i := 0; i < 100 && len(p) == 0; i++ {
+		buf := make([]byte, len(first)+5)
+		n, _ := j.Read(buf)
+		p = buf[:n]
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.Equal(t, first, string(p[:len(first)]))
+	require.NoError(t, j.Close())
+
+	cursor, err := loadJournalCursor(cfg.CursorFile)
+	require.NoError(t, err)
+	assert.Equal(t, "s=1;i=1", cursor)
+}
+
+func TestJournalReader_LastLine(t *testing.T) {
+	run := newTestJournalctl(t)
+	j, err := newJournalReader(JournalConfig{Identifiers: []string{"nginx"}}, nil, run.run)
+	require.NoError(t, err)
+	defer func() { _ = j.Close() }()
+
+	line, err := j.LastLine()
+	require.NoError(t, err)
+	assert.Equal(t, `127.0.0.1 - - [01/Aug/2022:10:00:02 +0000] "POST /api HTTP/1.1" 201 12`, string(line))
+	assert.Contains(t, run.args[1], "--lines=1")
+	assert.NotContains(t, run.args[1], "--follow")
+}
+
+func TestJournalReader_Name(t *testing.T) {
+	run := newTestJournalctl(t)
+	j, err := newJournalReader(JournalConfig{Units: []string{"nginx"}}, nil, run.run)
+	require.NoError(t, err)
+	defer func() { _ = j.Close() }()
+
+	assert.Equal(t, "journal (_SYSTEMD_UNIT=nginx.service)", j.Name())
+}
+
+type testJournalctl struct {
+	t    *testing.T
+	args [][]string
+}
+
+// newTestJournalctl returns a journalctl mock that outputs the exported journal file.
+func newTestJournalctl(t *testing.T) *testJournalctl {
+	return &testJournalctl{t: t}
+}
+
+func (j *testJournalctl) run(args []string) (io.ReadCloser, error) {
+	j.args = append(j.args, args)
+	bs, err := ioutil.ReadFile("testdata/journal.export")
+	require.NoError(j.t, err)
+	return ioutil.NopCloser(bytes.NewReader(bs)), nil
+}
+
+func readJournalLines(t *testing.T, j *JournalReader) (lines []string) {
+	t.Helper()
+	// the journal entries are decoded in the background
+	for i := 0; i < 100; i++ {
+		r := bufio.NewReader(j)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		if j.proc == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return lines
+}
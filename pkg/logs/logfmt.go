@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type (
+	LogfmtConfig struct {
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	// LogfmtParser parses 'key=value' pairs separated by spaces (https://brandur.org/logfmt).
+	// Values containing spaces are double-quoted, the keys without a value are skipped.
+	LogfmtParser struct {
+		r       *bufio.Reader
+		mapping map[string]string
+	}
+)
+
+var (
+	errLogfmtNoKey          = errors.New("missing key")
+	errLogfmtUnexpectedQuot = errors.New("unexpected '\"'")
+	errLogfmtUnterminated   = errors.New("unterminated quoted value")
+)
+
+func NewLogfmtParser(config LogfmtConfig, in io.Reader) (*LogfmtParser, error) {
+	parser := &LogfmtParser{
+		r:       bufio.NewReader(in),
+		mapping: config.Mapping,
+	}
+	return parser, nil
+}
+
+func (p *LogfmtParser) ReadLine(line LogLine) error {
+	row, err := p.r.ReadSlice('\n')
+	if err != nil && len(row) == 0 {
+		return err
+	}
+	if len(row) > 0 && row[len(row)-1] == '\n' {
+		row = row[:len(row)-1]
+	}
+	return p.Parse(row, line)
+}
+
+func (p *LogfmtParser) Parse(row []byte, line LogLine) error {
+	if err := p.parse(row, line); err != nil {
+		return &ParseError{msg: fmt.Sprintf("logfmt parse: %v", err), err: err}
+	}
+	return nil
+}
+
+func (p *LogfmtParser) parse(row []byte, line LogLine) error {
+	for {
+		row = bytes.TrimLeft(row, " \t\r")
+		if len(row) == 0 {
+			return nil
+		}
+
+		i := bytes.IndexAny(row, "= \t\r")
+		if i == 0 {
+			return errLogfmtNoKey
+		}
+		if i < 0 {
+			i = len(row)
+		}
+		key := row[:i]
+		if bytes.IndexByte(key, '"') >= 0 {
+			return errLogfmtUnexpectedQuot
+		}
+		if row = row[i:]; len(row) == 0 || row[0] != '=' {
+			// a key without a value
+			continue
+		}
+		row = row[1:]
+
+		var value string
+		if len(row) > 0 && row[0] == '"' {
+			end := quotedValueEnd(row)
+			if end < 0 {
+				return errLogfmtUnterminated
+			}
+			v, err := strconv.Unquote(string(row[:end+1]))
+			if err != nil {
+				return err
+			}
+			value, row = v, row[end+1:]
+		} else {
+			end := bytes.IndexAny(row, " \t\r")
+			if end < 0 {
+				end = len(row)
+			}
+			if bytes.IndexByte(row[:end], '"') >= 0 {
+				return errLogfmtUnexpectedQuot
+			}
+			value, row = string(row[:end]), row[end:]
+		}
+
+		name := string(key)
+		if v, ok := p.mapping[name]; ok {
+			name = v
+		}
+		if err := line.Assign(name, value); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *LogfmtParser) Info() string {
+	return fmt.Sprintf("logfmt: %q", p.mapping)
+}
+
+// quotedValueEnd returns the index of the closing double quote of the quoted value, or -1 if there is none.
+func quotedValueEnd(row []byte) int {
+	for i := 1; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
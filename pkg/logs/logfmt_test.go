@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtParser_ReadLine(t *testing.T) {
+	tests := map[string]struct {
+		config       LogfmtConfig
+		input        string
+		wantAssigned map[string]string
+		wantErr      bool
+		wantParseErr bool
+	}{
+		"plain values": {
+			input:        "method=GET status=200 duration=0.012\n",
+			wantAssigned: map[string]string{"method": "GET", "status": "200", "duration": "0.012"},
+		},
+		"quoted values": {
+			input:        `msg="request \"done\"" path="/a b" empty=""`,
+			wantAssigned: map[string]string{"msg": `request "done"`, "path": "/a b", "empty": ""},
+		},
+		"key without value and extra spaces": {
+			input:        "  level=info  debug   uri=/?a=b\r\n",
+			wantAssigned: map[string]string{"level": "info", "uri": "/?a=b"},
+		},
+		"with mappings": {
+			config:       LogfmtConfig{Mapping: map[string]string{"method": "request_method"}},
+			input:        "method=GET status=200",
+			wantAssigned: map[string]string{"request_method": "GET", "status": "200"},
+		},
+		"error on missing key": {
+			input:        "=GET",
+			wantErr:      true,
+			wantParseErr: true,
+		},
+		"error on unterminated quoted value": {
+			input:        `msg="done status=200`,
+			wantErr:      true,
+			wantParseErr: true,
+		},
+		"error on quote in unquoted value": {
+			input:        `msg=do"ne`,
+			wantErr:      true,
+			wantParseErr: true,
+		},
+		"error on assigning": {
+			input:        "a=1 ERR=2",
+			wantErr:      true,
+			wantParseErr: true,
+		},
+		"error on reading EOF": {
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewLogfmtParser(test.config, strings.NewReader(test.input))
+			require.NoError(t, err)
+
+			err = p.ReadLine(line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, test.wantParseErr, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestLogfmtParser_Info(t *testing.T) {
+	p, err := NewLogfmtParser(LogfmtConfig{}, nil)
+	require.NoError(t, err)
+	assert.NotZero(t, p.Info())
+}
@@ -37,6 +37,8 @@ const (
 	TypeLTSV   = "ltsv"
 	TypeRegExp = "regexp"
 	TypeJSON   = "json"
+	TypeLogfmt = "logfmt"
+	TypeW3C    = "w3c"
 )
 
 type ParserConfig struct {
@@ -45,6 +47,8 @@ type ParserConfig struct {
 	LTSV    LTSVConfig   `yaml:"ltsv_config"`
 	RegExp  RegExpConfig `yaml:"regexp_config"`
 	JSON    JSONConfig   `yaml:"json_config"`
+	Logfmt  LogfmtConfig `yaml:"logfmt_config"`
+	W3C     W3CConfig    `yaml:"w3c_config"`
 }
 
 func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
@@ -57,6 +61,10 @@ func NewParser(config ParserConfig, in io.Reader) (Parser, error) {
 		return NewRegExpParser(config.RegExp, in)
 	case TypeJSON:
 		return NewJSONParser(config.JSON, in)
+	case TypeLogfmt:
+		return NewLogfmtParser(config.Logfmt, in)
+	case TypeW3C:
+		return NewW3CParser(config.W3C, in)
 	default:
 		return nil, fmt.Errorf("invalid type: %q", config.LogType)
 	}
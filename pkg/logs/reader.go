@@ -3,165 +3,527 @@
 package logs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/netdata/go.d.plugin/logger"
 )
 
 const (
-	maxEOF = 60
+	// detachedTTL is how long a rotated (renamed or no longer matched) file is still read after its last write.
+	// Writers keep writing to the old file until they reopen the log.
+	detachedTTL = time.Second * 10
+	// checkpointEvery is how often the read offsets are persisted.
+	checkpointEvery = time.Second * 5
 )
 
 var (
 	ErrNoMatchedFile = errors.New("no matched files")
+	// ErrBackfillNoCheckpoint is returned if the backfill is enabled and the read offsets are not persisted,
+	// the rotated files would be replayed and the lines counted again on every restart.
+	ErrBackfillNoCheckpoint = errors.New("backfill requires the read offsets to be persisted")
 )
 
-// TODO: better reopen algorithm
-// TODO: handle truncate
-// Reader is a log rotate aware Reader
+// ReaderConfig is a Reader configuration.
+type ReaderConfig struct {
+	// Path is a shell file name pattern.
+	Path string
+	// ExcludePath is a shell file name pattern, matched files are not read.
+	ExcludePath string
+	// AllFiles makes the Reader tail all the matched files, only the most recently modified one is tailed otherwise.
+	AllFiles bool
+	// CheckpointFile is the file the read offsets are persisted to, they are not persisted if not set.
+	CheckpointFile string
+	// Backfill is the rotated files backfill configuration, it requires the CheckpointFile.
+	Backfill BackfillConfig
+}
+
+// Reader is a log rotate aware Reader.
+// It tracks the inode and offset of every tailed file, handles both rename (create) and copytruncate rotation,
+// and returns only complete lines.
 type Reader struct {
-	file          *os.File
-	path          string
-	excludePath   string
-	eofCounter    int
-	continuousEOF int
-	log           *logger.Logger
+	path        string
+	excludePath string
+	allFiles    bool
+	log         *logger.Logger
+
+	checkpointFile string
+	checkpoints    map[string]checkpoint
+	lastSave       time.Time
+
+	backfillCfg BackfillConfig
+	backfill    *backfill
+
+	started bool
+	tails   []*tail
+	lines   []byte
+	buf     []byte
+	// pending are the tails the buffered lines belong to, in the buffer order.
+	pending []pendingLines
 }
 
-// Open a file and seek to end of the file.
+// pendingLines are the buffered lines of a tail or a replayed rotated file that are not returned by Read yet.
+type pendingLines struct {
+	tail *tail
+	// file is the replayed rotated file, set instead of the tail for the backfilled lines.
+	file *backfillFile
+	// eof is whether the lines are the last lines of the replayed file.
+	eof bool
+	// left is the number of the bytes not returned yet.
+	left int
+	// end is the file offset of the end of the lines.
+	end int64
+}
+
+// Open creates a Reader that tails the most recently modified matched file, starting from the end of the file.
 // path: shell file name pattern
 // excludePath: shell file name pattern
 func Open(path string, excludePath string, log *logger.Logger) (*Reader, error) {
-	var err error
-	if path, err = filepath.Abs(path); err != nil {
+	return NewReader(ReaderConfig{Path: path, ExcludePath: excludePath}, log)
+}
+
+// NewReader creates a Reader. A file is read from the persisted offset if there is a checkpoint for it
+// and from the end of the file otherwise. Files that appear later are read from the beginning.
+func NewReader(cfg ReaderConfig, log *logger.Logger) (*Reader, error) {
+	path, err := filepath.Abs(cfg.Path)
+	if err != nil {
 		return nil, err
 	}
 	if _, err = filepath.Match(path, "/"); err != nil {
 		return nil, fmt.Errorf("bad path syntax: %q", path)
 	}
-	if _, err = filepath.Match(excludePath, "/"); err != nil {
-		return nil, fmt.Errorf("bad exclude_path syntax: %q", path)
+	if _, err = filepath.Match(cfg.ExcludePath, "/"); err != nil {
+		return nil, fmt.Errorf("bad exclude_path syntax: %q", cfg.ExcludePath)
+	}
+	if cfg.Backfill.Enabled && cfg.CheckpointFile == "" {
+		return nil, ErrBackfillNoCheckpoint
 	}
 	r := &Reader{
-		path:        path,
-		excludePath: excludePath,
-		log:         log,
+		path:           path,
+		excludePath:    cfg.ExcludePath,
+		allFiles:       cfg.AllFiles,
+		log:            log,
+		checkpointFile: cfg.CheckpointFile,
+		backfillCfg:    cfg.Backfill,
+	}
+
+	if r.checkpointFile != "" {
+		if r.checkpoints, err = loadCheckpoints(r.checkpointFile); err != nil {
+			r.log.Warningf("load checkpoints from '%s': %v", r.checkpointFile, err)
+		}
 	}
 
 	if err = r.open(); err != nil {
 		return nil, err
 	}
+	if r.backfillCfg.Enabled {
+		r.planBackfill()
+	}
 	return r, nil
 }
 
-// CurrentFilename get current opened file name
+// BackfillStats returns the rotated files backfill progress.
+func (r *Reader) BackfillStats() BackfillStats {
+	if r.backfill == nil {
+		return BackfillStats{Done: true}
+	}
+	return r.backfill.stats
+}
+
+func (r *Reader) planBackfill() {
+	r.backfill = &backfill{progress: make(map[string]*backfillCheckpoint)}
+	now := time.Now()
+	for _, t := range r.tails {
+		var files []backfillFile
+		v, ok := r.checkpoints[t.name]
+		switch {
+		case !ok:
+			files = rotatedFiles(t.name, r.backfillCfg, nil, now)
+		case !t.sameAs(v):
+			// rotated or truncated since the last run
+			files = rotatedFiles(t.name, r.backfillCfg, &v, now)
+		}
+		if ok && v.Backfill != nil && !v.Backfill.Done {
+			// the interrupted backfill goes before the files rotated since the last run,
+			// it is resumed from the replayed position regardless of the checkpoint time
+			r.log.Infof("backfill: resuming from file '%s' offset %d", v.Backfill.File, v.Backfill.Offset)
+			rest := resumedFiles(rotatedFiles(t.name, r.backfillCfg, nil, now), *v.Backfill, files)
+			for i, f := range rest {
+				if f.inode == v.Inode && f.name != v.Backfill.File {
+					// the live file rotated since the last run
+					rest[i].skip = v.Offset
+				}
+			}
+			files = append(rest, files...)
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		for i, f := range files {
+			files[i].live = t.name
+			r.log.Debugf("backfill: replay file '%s' (skip %d bytes)", f.name, f.skip)
+		}
+		files[len(files)-1].last = true
+		r.backfill.progress[t.name] = &backfillCheckpoint{File: files[0].name, Inode: files[0].inode, Offset: files[0].skip}
+		r.backfill.files = append(r.backfill.files, files...)
+	}
+	if r.backfill.empty() {
+		r.backfill.stats.Done = true
+		return
+	}
+	r.log.Infof("backfill: replaying %d rotated file(s)", len(r.backfill.files))
+}
+
+// CurrentFilename returns the most recently modified tailed file name.
 func (r *Reader) CurrentFilename() string {
-	return r.file.Name()
+	var name string
+	var mtime time.Time
+	for _, t := range r.tails {
+		if t.detached {
+			continue
+		}
+		fi, err := t.file.Stat()
+		if err != nil {
+			continue
+		}
+		if name == "" || fi.ModTime().After(mtime) {
+			name, mtime = t.name, fi.ModTime()
+		}
+	}
+	return name
+}
+
+// Name returns the current file name.
+func (r *Reader) Name() string {
+	return r.CurrentFilename()
+}
+
+// LastLine returns the last line of the current file.
+func (r *Reader) LastLine() ([]byte, error) {
+	return ReadLastLine(r.CurrentFilename(), 0)
+}
+
+// Filenames returns the tailed file names.
+func (r *Reader) Filenames() []string {
+	var names []string
+	for _, t := range r.tails {
+		if !t.detached {
+			names = append(names, t.name)
+		}
+	}
+	return names
 }
 
 func (r *Reader) open() error {
-	path := r.findFile()
-	if path == "" {
+	r.scan()
+	if len(r.tails) == 0 {
 		r.log.Debugf("couldn't find log file, used path: '%s', exclude_path: '%s'", r.path, r.excludePath)
 		return ErrNoMatchedFile
 	}
-	r.log.Debug("open log file: ", path)
-	file, err := os.Open(path)
-	if err != nil {
-		return err
+	r.started = true
+	return nil
+}
+
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if len(r.buf) == 0 {
+		if err = r.fill(); err != nil {
+			return 0, err
+		}
 	}
-	stat, err := file.Stat()
-	if err != nil {
-		return err
+	n = copy(p, r.buf)
+	r.consume(r.buf[:n])
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *Reader) fill() error {
+	if !r.started {
+		// the Reader was closed
+		if err := r.open(); err != nil {
+			return err
+		}
 	}
-	if _, err = file.Seek(stat.Size(), io.SeekStart); err != nil {
-		return err
+
+	if r.backfill != nil && !r.backfill.stats.Done {
+		r.buf, r.pending = r.backfill.readLines(r.log.Warningf)
+		// the replayed files that have no lines left
+		r.consume(nil)
+		r.saveCheckpoints(false)
+		if r.backfill.stats.Done {
+			r.log.Infof("backfill: read %d rotated file(s)", r.backfill.stats.Files)
+		}
+		if len(r.buf) > 0 {
+			return nil
+		}
+		if !r.backfill.stats.Done {
+			// the batch limit is reached
+			return io.EOF
+		}
 	}
-	r.file = file
-	return nil
+
+	if r.readTails(); len(r.buf) > 0 {
+		return nil
+	}
+
+	r.scan()
+	r.saveCheckpoints(false)
+	if len(r.tails) == 0 {
+		return ErrNoMatchedFile
+	}
+
+	if r.readTails(); len(r.buf) > 0 {
+		return nil
+	}
+	return io.EOF
 }
 
-func (r *Reader) Read(p []byte) (n int, err error) {
-	n, err = r.file.Read(p)
-	if err != nil {
-		switch err {
-		case io.EOF:
-			err = r.handleEOFErr()
-		case os.ErrInvalid: // r.file is nil after Close
-			err = r.handleInvalidArgErr()
+// readTails reads the available complete lines from all the tailed files concurrently.
+func (r *Reader) readTails() {
+	r.lines = r.lines[:0]
+	r.pending = r.pending[:0]
+	res := make([][]byte, len(r.tails))
+	switch len(r.tails) {
+	case 0:
+	case 1:
+		res[0] = r.tails[0].readLines()
+	default:
+		var wg sync.WaitGroup
+		for i, t := range r.tails {
+			wg.Add(1)
+			go func(i int, t *tail) { defer wg.Done(); res[i] = t.readLines() }(i, t)
+		}
+		wg.Wait()
+	}
+	for i, lines := range res {
+		if t := r.tails[i]; t.dropped > 0 {
+			r.log.Warningf("'%s': dropped %d line(s) longer than %d bytes", t.name, t.dropped, maxLineSize)
+			t.dropped = 0
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		r.lines = append(r.lines, lines...)
+		r.pending = append(r.pending, pendingLines{tail: r.tails[i], left: len(lines), end: r.tails[i].linesEnd()})
+	}
+	r.buf = r.lines
+}
+
+// consume moves the tails consumed offsets and the backfill position after the data returned by Read.
+// The consumed offset is moved only to the end of a complete line.
+func (r *Reader) consume(data []byte) {
+	for len(r.pending) > 0 {
+		p := &r.pending[0]
+		n := minInt(len(data), p.left)
+		p.left -= n
+		if p.left > 0 {
+			if i := bytes.LastIndexByte(data[:n], '\n'); i >= 0 {
+				r.setConsumed(p, p.end-int64(p.left)-int64(n-i-1), data[:i+1])
+			}
+			return
 		}
+		r.setConsumed(p, p.end, data[:n])
+		r.pending = r.pending[1:]
+		data = data[n:]
+	}
+}
+
+func (r *Reader) setConsumed(p *pendingLines, offset int64, data []byte) {
+	if p.tail != nil {
+		p.tail.consumed = offset
 		return
 	}
-	r.continuousEOF = 0
-	return
+	r.backfill.consumed(p, offset, data)
 }
 
-func (r *Reader) handleEOFErr() (err error) {
-	err = io.EOF
-	r.eofCounter++
-	r.continuousEOF++
-	if r.eofCounter < maxEOF || r.continuousEOF < 2 {
-		return err
+// scan looks for new, rotated and truncated files.
+func (r *Reader) scan() {
+	files := r.findFiles()
+	now := time.Now()
+	matched := make(map[string]bool, len(files))
+
+	for _, name := range files {
+		matched[name] = true
+		fi, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+
+		if t := r.attachedTail(name); t != nil {
+			if t.sameFile(fi) {
+				if fi.Size() < t.offset {
+					r.log.Infof("file '%s' is truncated, reading from the beginning", name)
+					t.rewind()
+				}
+				continue
+			}
+			r.log.Infof("file '%s' is rotated, reading the new file from the beginning", name)
+			t.detach(now)
+		}
+
+		if t := r.tailOf(fi); t != nil {
+			// a renamed file that is still being read
+			if t.detached {
+				t.name, t.detached = name, false
+			}
+			continue
+		}
+
+		t, err := openTail(name, r.startOffset(name, fi))
+		if err != nil {
+			r.log.Warningf("open log file '%s': %v", name, err)
+			continue
+		}
+		r.log.Debugf("open log file '%s', offset %d", name, t.offset)
+		r.tails = append(r.tails, t)
 	}
-	if err2 := r.reopen(); err2 != nil {
-		err = err2
+
+	tails := r.tails[:0]
+	for _, t := range r.tails {
+		if !t.detached && !matched[t.name] {
+			r.log.Debugf("file '%s' is not matched anymore", t.name)
+			t.detach(now)
+		}
+		if t.detached && now.Sub(t.lastActive) > detachedTTL {
+			r.log.Debugf("close log file '%s'", t.name)
+			_ = t.close()
+			continue
+		}
+		tails = append(tails, t)
 	}
-	return err
+	r.tails = tails
 }
 
-func (r *Reader) handleInvalidArgErr() (err error) {
-	err = io.EOF
-	if err2 := r.reopen(); err2 != nil {
-		err = err2
+// startOffset returns the offset to start reading a newly opened file from.
+func (r *Reader) startOffset(name string, fi os.FileInfo) int64 {
+	if r.started {
+		// a file created after the Reader was started
+		return 0
+	}
+	cp, ok := r.checkpoints[name]
+	switch {
+	case !ok && r.backfillCfg.Enabled:
+		// the live file goes after the replayed rotated files
+		return 0
+	case !ok:
+		return fi.Size()
+	case cp.Inode != fileInode(fi):
+		r.log.Infof("file '%s' was rotated since the last run, reading from the beginning", name)
+		return 0
+	case cp.Offset > fi.Size():
+		r.log.Infof("file '%s' was truncated since the last run, reading from the beginning", name)
+		return 0
+	default:
+		return cp.Offset
 	}
-	return err
 }
 
-func (r *Reader) Close() (err error) {
-	if r == nil || r.file == nil {
-		return
+func (r *Reader) attachedTail(name string) *tail {
+	for _, t := range r.tails {
+		if !t.detached && t.name == name {
+			return t
+		}
 	}
-	r.log.Debug("close log file: ", r.file.Name())
-	err = r.file.Close()
-	r.file = nil
-	r.eofCounter = 0
-	return
+	return nil
+}
+
+func (r *Reader) tailOf(fi os.FileInfo) *tail {
+	for _, t := range r.tails {
+		if t.sameFile(fi) {
+			return t
+		}
+	}
+	return nil
 }
 
-func (r *Reader) reopen() error {
-	r.log.Debugf("reopen, look for: %s", r.path)
-	_ = r.Close()
-	return r.open()
+func (r *Reader) saveCheckpoints(force bool) {
+	if r.checkpointFile == "" || (!force && time.Since(r.lastSave) < checkpointEvery) {
+		return
+	}
+	now := time.Now()
+	r.lastSave = now
+
+	cps := make(map[string]checkpoint)
+	for name, cp := range r.checkpoints {
+		// keep the checkpoints of the files that are temporarily missing
+		if _, err := os.Stat(name); err == nil {
+			cps[name] = cp
+		}
+	}
+	for _, t := range r.tails {
+		if t.detached {
+			continue
+		}
+		cp := t.checkpoint(now)
+		if r.backfill != nil {
+			if p, ok := r.backfill.progress[t.name]; ok {
+				v := *p
+				cp.Backfill = &v
+			}
+		}
+		cps[t.name] = cp
+	}
+	r.checkpoints = cps
+
+	if err := saveCheckpoints(r.checkpointFile, cps); err != nil {
+		r.log.Warningf("save checkpoints to '%s': %v", r.checkpointFile, err)
+	}
 }
 
-func (r *Reader) findFile() string {
-	return find(r.path, r.excludePath)
+func (r *Reader) Close() (err error) {
+	if r == nil || !r.started {
+		return
+	}
+	r.saveCheckpoints(true)
+	if r.backfill != nil {
+		r.backfill.close()
+	}
+	for _, t := range r.tails {
+		r.log.Debug("close log file: ", t.name)
+		if e := t.close(); e != nil {
+			err = e
+		}
+	}
+	r.tails = nil
+	r.buf = nil
+	r.pending = nil
+	r.started = false
+	return err
 }
 
-func find(path, exclude string) string {
-	return finder{}.find(path, exclude)
+func (r *Reader) findFiles() []string {
+	files := finder{}.findAll(r.path, r.excludePath)
+	if r.allFiles || len(files) == 0 {
+		return files
+	}
+	return []string{finder{}.findLastFile(files)}
 }
 
-// TODO: tests
 type finder struct{}
 
-func (f finder) find(path, exclude string) string {
+// findAll returns the sorted regular files matched by the path and not matched by the exclude pattern.
+func (f finder) findAll(path, exclude string) []string {
 	files, _ := filepath.Glob(path)
 	if len(files) == 0 {
-		return ""
+		return nil
 	}
 
 	files = f.filter(files, exclude)
-	if len(files) == 0 {
-		return ""
+	regular := files[:0]
+	for _, file := range files {
+		if stat, err := os.Stat(file); err == nil && stat.Mode().IsRegular() {
+			regular = append(regular, file)
+		}
 	}
-
-	return f.findLastFile(files)
+	sort.Strings(regular)
+	return regular
 }
 
 func (f finder) filter(files []string, exclude string) []string {
@@ -179,15 +541,18 @@ func (f finder) filter(files []string, exclude string) []string {
 	return fs
 }
 
-// TODO: the logic is probably wrong
+// findLastFile returns the most recently modified file, the last one in lexical order wins a tie.
 func (f finder) findLastFile(files []string) string {
-	sort.Strings(files)
-	for i := len(files) - 1; i >= 0; i-- {
-		stat, err := os.Stat(files[i])
+	var last string
+	var mtime time.Time
+	for _, file := range files {
+		stat, err := os.Stat(file)
 		if err != nil || !stat.Mode().IsRegular() {
 			continue
 		}
-		return files[i]
+		if last == "" || !stat.ModTime().Before(mtime) {
+			last, mtime = file, stat.ModTime()
+		}
 	}
-	return ""
+	return last
 }
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +27,7 @@ func TestReader_Read(t *testing.T) {
 	var sum int
 
 	for i := 0; i < 10; i++ {
-		appendLogs(t, filename, time.Millisecond*10, numLogs)
+		appendLogs(t, filename, 0, numLogs)
 		n, err := r.readUntilEOF()
 		sum += n
 
@@ -42,14 +43,19 @@ func TestReader_Read_HandleFileRotation(t *testing.T) {
 	r := testReader{bufio.NewReader(reader)}
 	filename := reader.CurrentFilename()
 	numLogs := 5
+	appendLogs(t, filename, 0, numLogs)
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+
 	rotateFile(t, filename)
-	appendLogs(t, filename, time.Millisecond*10, numLogs)
+	appendLogs(t, filename, 0, numLogs)
 
-	n, err := r.readUntilEOFTimes(maxEOF)
+	n, err = r.readUntilEOF()
 	assert.Equal(t, io.EOF, err)
-	assert.Equal(t, 0, n)
+	assert.Equal(t, numLogs, n)
 
-	appendLogs(t, filename, time.Millisecond*10, numLogs)
+	appendLogs(t, filename, 0, numLogs)
 	n, err = r.readUntilEOF()
 	assert.Equal(t, io.EOF, err)
 	assert.Equal(t, numLogs, n)
@@ -63,25 +69,208 @@ func TestReader_Read_HandleFileRotationWithDelay(t *testing.T) {
 	filename := reader.CurrentFilename()
 	_ = os.Remove(filename)
 
-	// trigger reopen first time
-	n, err := r.readUntilEOFTimes(maxEOF)
-	assert.Equal(t, ErrNoMatchedFile, err)
+	// the removed file is still read
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
 	assert.Equal(t, 0, n)
 
 	f, err := os.Create(filename)
 	require.NoError(t, err)
 	_ = f.Close()
 
-	// trigger reopen 2nd time
 	n, err = r.readUntilEOF()
 	assert.Equal(t, io.EOF, err)
 	assert.Equal(t, 0, n)
 
 	numLogs := 5
-	appendLogs(t, filename, time.Millisecond*10, numLogs)
+	appendLogs(t, filename, 0, numLogs)
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+}
+
+func TestReader_Read_HandleWritesToRenamedFile(t *testing.T) {
+	reader, teardown := prepareTestReader(t)
+	defer teardown()
+
+	r := testReader{bufio.NewReader(reader)}
+	filename := reader.CurrentFilename()
+	renamed := filename + ".1"
+	defer func() { _ = os.Remove(renamed) }()
+
+	require.NoError(t, os.Rename(filename, renamed))
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	_ = f.Close()
+
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// the writer hasn't reopened the log yet
+	numLogs := 5
+	appendLogs(t, renamed, 0, numLogs)
+	appendLogs(t, filename, 0, numLogs)
+
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs*2, n)
+}
+
+func TestReader_Read_HandleCopyTruncate(t *testing.T) {
+	reader, teardown := prepareTestReader(t)
+	defer teardown()
+
+	r := testReader{bufio.NewReader(reader)}
+	filename := reader.CurrentFilename()
+	numLogs := 5
+	appendLogs(t, filename, 0, numLogs)
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+
+	require.NoError(t, os.Truncate(filename, 0))
+	appendLogs(t, filename, 0, numLogs-2)
+
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs-2, n)
+}
+
+func TestReader_Read_ReturnsOnlyCompleteLines(t *testing.T) {
+	reader, teardown := prepareTestReader(t)
+	defer teardown()
+
+	filename := reader.CurrentFilename()
+	writeFile(t, filename, "first ")
+	bs, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Empty(t, bs)
+
+	writeFile(t, filename, "line\nsecond ")
+	bs, err = ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "first line\n", string(bs))
+}
+
+func TestReader_Read_AllFiles(t *testing.T) {
+	dir := t.TempDir()
+	file1, file2, file3 := filepath.Join(dir, "1.log"), filepath.Join(dir, "2.log"), filepath.Join(dir, "3.log")
+	writeFile(t, file1, "old line\n")
+	writeFile(t, file2, "old line\n")
+
+	reader, err := NewReader(ReaderConfig{Path: filepath.Join(dir, "*.log"), AllFiles: true}, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	assert.Equal(t, []string{file1, file2}, reader.Filenames())
+
+	r := testReader{bufio.NewReader(reader)}
+	numLogs := 5
+	appendLogs(t, file1, 0, numLogs)
+	appendLogs(t, file2, 0, numLogs)
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs*2, n)
+
+	// a new file is read from the beginning
+	writeFile(t, file3, "line\n")
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{file1, file2, file3}, reader.Filenames())
+}
+
+func TestReader_Read_ResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	cpFile := filepath.Join(dir, "checkpoints", "access.json")
+	writeFile(t, filename, "old line\n")
+	cfg := ReaderConfig{Path: filename, CheckpointFile: cpFile}
+
+	reader, err := NewReader(cfg, nil)
+	require.NoError(t, err)
+	r := testReader{bufio.NewReader(reader)}
+	numLogs := 5
+	appendLogs(t, filename, 0, numLogs)
+	n, err := r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+	require.NoError(t, reader.Close())
+
+	// lines written while the reader was stopped
+	appendLogs(t, filename, 0, numLogs)
+	reader, err = NewReader(cfg, nil)
+	require.NoError(t, err)
+	r = testReader{bufio.NewReader(reader)}
+	n, err = r.readUntilEOF()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, numLogs, n)
+	require.NoError(t, reader.Close())
+
+	// the file rotated while the reader was stopped
+	rotateFile(t, filename)
+	appendLogs(t, filename, 0, numLogs)
+	reader, err = NewReader(cfg, nil)
+	require.NoError(t, err)
+	r = testReader{bufio.NewReader(reader)}
 	n, err = r.readUntilEOF()
 	assert.Equal(t, io.EOF, err)
 	assert.Equal(t, numLogs, n)
+	require.NoError(t, reader.Close())
+}
+
+func TestReader_Close_CheckpointsConsumedLines(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	cfg := ReaderConfig{Path: filename, CheckpointFile: filepath.Join(dir, "checkpoints.json")}
+	writeFile(t, filename, "")
+
+	reader, err := NewReader(cfg, nil)
+	require.NoError(t, err)
+	writeFile(t, filename, "line 0\nline 1\nline 2\n")
+
+	// the first line and a part of the second one are returned, the rest is still buffered
+	p := make([]byte, len("line 0\nli"))
+	n, err := reader.Read(p)
+	require.NoError(t, err)
+	require.Equal(t, len(p), n)
+	require.NoError(t, reader.Close())
+
+	reader, err = NewReader(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	assert.Equal(t, []string{"line 1", "line 2"}, readLines(t, reader))
+}
+
+func TestReader_Read_DropsTooLongLines(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "access.log")
+	cfg := ReaderConfig{Path: filename, CheckpointFile: filepath.Join(dir, "checkpoints.json")}
+	writeFile(t, filename, "")
+
+	reader, err := NewReader(cfg, nil)
+	require.NoError(t, err)
+	writeFile(t, filename, "line 0\n"+strings.Repeat("x", maxLineSize+10))
+
+	var lines []string
+	for i := 0; i < maxLineSize/readChunkSize+2; i++ {
+		lines = append(lines, readLines(t, reader)...)
+	}
+	assert.Equal(t, []string{"line 0"}, lines)
+	require.NoError(t, reader.Close())
+
+	// the too long line is not resumed in the middle after a restart
+	writeFile(t, filename, "xxx\nline 1\n")
+	reader, err = NewReader(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	lines = nil
+	for i := 0; i < maxLineSize/readChunkSize+2; i++ {
+		lines = append(lines, readLines(t, reader)...)
+	}
+	assert.Equal(t, []string{"line 1"}, lines)
 }
 
 func TestReader_Close(t *testing.T) {
@@ -89,7 +278,7 @@ func TestReader_Close(t *testing.T) {
 	defer teardown()
 
 	assert.NoError(t, reader.Close())
-	assert.Nil(t, reader.file)
+	assert.Nil(t, reader.tails)
 }
 
 func TestReader_Close_NilFile(t *testing.T) {
@@ -157,7 +346,7 @@ func TestOpen(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, r.file)
+				assert.NotEmpty(t, r.tails)
 				_ = r.Close()
 			}
 		})
@@ -168,7 +357,19 @@ func TestReader_CurrentFilename(t *testing.T) {
 	reader, teardown := prepareTestReader(t)
 	defer teardown()
 
-	assert.Equal(t, reader.file.Name(), reader.CurrentFilename())
+	assert.Equal(t, reader.tails[0].name, reader.CurrentFilename())
+}
+
+func TestFinder_findLastFile(t *testing.T) {
+	dir := t.TempDir()
+	older, newer := filepath.Join(dir, "b.log"), filepath.Join(dir, "a.log")
+	writeFile(t, older, "line\n")
+	writeFile(t, newer, "line\n")
+	now := time.Now()
+	require.NoError(t, os.Chtimes(older, now, now.Add(-time.Hour)))
+	require.NoError(t, os.Chtimes(newer, now, now))
+
+	assert.Equal(t, newer, finder{}.findLastFile(finder{}.findAll(filepath.Join(dir, "*.log"), "")))
 }
 
 type testReader struct {
@@ -186,18 +387,6 @@ func (r *testReader) readUntilEOF() (n int, err error) {
 	return n, err
 }
 
-func (r *testReader) readUntilEOFTimes(times int) (sum int, err error) {
-	var n int
-	for i := 0; i < times; i++ {
-		n, err = r.readUntilEOF()
-		if err != io.EOF {
-			break
-		}
-		sum += n
-	}
-	return sum, err
-}
-
 func prepareTempFile(t *testing.T, pattern string) string {
 	t.Helper()
 	f, err := ioutil.TempFile("", pattern)
@@ -208,20 +397,25 @@ func prepareTempFile(t *testing.T, pattern string) string {
 func prepareTestReader(t *testing.T) (reader *Reader, teardown func()) {
 	t.Helper()
 	filename := prepareTempFile(t, "*-web_log-test.log")
-	f, err := os.Open(filename)
+	reader, err := Open(filename, "", nil)
 	require.NoError(t, err)
 
 	teardown = func() {
+		_ = reader.Close()
 		_ = os.Remove(filename)
-		_ = reader.file.Close()
-	}
-	reader = &Reader{
-		file: f,
-		path: filename,
 	}
 	return reader, teardown
 }
 
+func writeFile(t *testing.T, filename, data string) {
+	t.Helper()
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+}
+
 func rotateFile(t *testing.T, filename string) {
 	t.Helper()
 	require.NoError(t, os.Remove(filename))
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import "io"
+
+const (
+	SourceFile    = "file"
+	SourceJournal = "journal"
+	SourceSyslog  = "syslog"
+)
+
+// Source is a log lines source. Read returns only complete lines
+// and io.EOF if there are no more lines available at the moment.
+type Source interface {
+	io.ReadCloser
+	// Name describes the source (the current file name, the journal matches, etc.).
+	Name() string
+	// LastLine returns the most recent line, it is used to auto-detect the log format.
+	LastLine() ([]byte, error)
+}
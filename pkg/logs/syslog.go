@@ -0,0 +1,524 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/netdata/go.d.plugin/logger"
+)
+
+const (
+	defaultSyslogQueueSize      = 10000
+	defaultSyslogMaxMessageSize = 64 * 1024
+	defaultSyslogMaxConnections = 100
+	// syslogLastLineWait is how long LastLine waits for the first message.
+	syslogLastLineWait = time.Second * 5
+	// syslogRetryWait is how long the listener waits after a failed read or accept.
+	syslogRetryWait = time.Millisecond * 100
+)
+
+// SyslogConfig is a SyslogReader configuration.
+type SyslogConfig struct {
+	// Network is one of "udp", "tcp", "unix" (stream) or "unixgram".
+	Network string `yaml:"network"`
+	// Address is the "host:port" to listen on or the socket file path for the unix networks.
+	Address string `yaml:"address"`
+	// QueueSize is the max number of the received, but not yet read messages, the new messages are dropped if the queue is full.
+	QueueSize int `yaml:"queue_size"`
+	// MaxMessageSize is the max message size, larger messages are dropped.
+	MaxMessageSize int `yaml:"max_message_size"`
+	// MaxConnections is the max number of the stream networks connections, the new connections are closed if reached.
+	MaxConnections int `yaml:"max_connections"`
+}
+
+// SyslogStats is the SyslogReader message counters.
+type SyslogStats struct {
+	Received int64
+	Dropped  int64
+}
+
+// SyslogReader receives syslog messages (RFC3164, RFC5424) and returns the messages without the headers, one per line.
+// For the stream networks both the octet counting and the new line delimited framing (RFC6587) are supported.
+type SyslogReader struct {
+	// the atomically accessed fields go first to be 64-bit aligned on 32-bit platforms
+	received int64
+	dropped  int64
+
+	network        string
+	address        string
+	maxMessageSize int
+	maxConns       int
+	log            *logger.Logger
+
+	queue chan []byte
+
+	listener   net.Listener
+	packetConn net.PacketConn
+	connsMux   sync.Mutex
+	conns      map[net.Conn]struct{}
+	wg         sync.WaitGroup
+	done       chan struct{}
+	first      chan struct{}
+	firstOnce  sync.Once
+	last       atomic.Value
+
+	lines []byte
+	buf   []byte
+}
+
+// NewSyslogReader creates a SyslogReader and starts listening.
+func NewSyslogReader(cfg SyslogConfig, log *logger.Logger) (*SyslogReader, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("syslog address not set")
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultSyslogQueueSize
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultSyslogMaxMessageSize
+	}
+	if cfg.MaxConnections <= 0 {
+		cfg.MaxConnections = defaultSyslogMaxConnections
+	}
+
+	s := &SyslogReader{
+		network:        cfg.Network,
+		address:        cfg.Address,
+		maxMessageSize: cfg.MaxMessageSize,
+		maxConns:       cfg.MaxConnections,
+		log:            log,
+		queue:          make(chan []byte, cfg.QueueSize),
+		conns:          make(map[net.Conn]struct{}),
+		done:           make(chan struct{}),
+		first:          make(chan struct{}),
+	}
+
+	var err error
+	switch cfg.Network {
+	case "udp", "udp4", "udp6":
+		s.packetConn, err = net.ListenPacket(cfg.Network, cfg.Address)
+	case "unixgram":
+		_ = os.Remove(cfg.Address)
+		s.packetConn, err = net.ListenPacket(cfg.Network, cfg.Address)
+	case "tcp", "tcp4", "tcp6":
+		s.listener, err = net.Listen(cfg.Network, cfg.Address)
+	case "unix":
+		_ = os.Remove(cfg.Address)
+		s.listener, err = net.Listen(cfg.Network, cfg.Address)
+	default:
+		return nil, fmt.Errorf("unsupported syslog network '%s'", cfg.Network)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	if s.packetConn != nil {
+		go s.servePacket()
+	} else {
+		go s.serveStream()
+	}
+	return s, nil
+}
+
+// Name returns the listen address.
+func (s *SyslogReader) Name() string {
+	return fmt.Sprintf("syslog %s://%s", s.network, s.Addr())
+}
+
+// Addr returns the listen address.
+func (s *SyslogReader) Addr() string {
+	if s.packetConn != nil {
+		return s.packetConn.LocalAddr().String()
+	}
+	return s.listener.Addr().String()
+}
+
+// Stats returns the received and dropped message counters.
+func (s *SyslogReader) Stats() SyslogStats {
+	return SyslogStats{
+		Received: atomic.LoadInt64(&s.received),
+		Dropped:  atomic.LoadInt64(&s.dropped),
+	}
+}
+
+// LastLine returns the last received message, it waits for the first message if nothing is received yet.
+func (s *SyslogReader) LastLine() ([]byte, error) {
+	select {
+	case <-s.first:
+	case <-time.After(syslogLastLineWait):
+		return nil, fmt.Errorf("no messages received within %s", syslogLastLineWait)
+	}
+	return s.last.Load().([]byte), nil
+}
+
+func (s *SyslogReader) Read(p []byte) (n int, err error) {
+	if len(s.buf) == 0 {
+		if err = s.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+func (s *SyslogReader) fill() error {
+	s.lines = s.lines[:0]
+loop:
+	for len(s.lines) < readChunkSize {
+		select {
+		case msg := <-s.queue:
+			s.lines = append(s.lines, msg...)
+		default:
+			break loop
+		}
+	}
+	s.buf = s.lines
+	if len(s.buf) == 0 {
+		return io.EOF
+	}
+	return nil
+}
+
+func (s *SyslogReader) Close() error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case <-s.done:
+		return nil
+	default:
+		close(s.done)
+	}
+
+	var err error
+	if s.packetConn != nil {
+		err = s.packetConn.Close()
+	} else {
+		err = s.listener.Close()
+		s.connsMux.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.connsMux.Unlock()
+	}
+	s.wg.Wait()
+	if s.network == "unix" || s.network == "unixgram" {
+		_ = os.Remove(s.address)
+	}
+	s.buf = nil
+	return err
+}
+
+func (s *SyslogReader) servePacket() {
+	defer s.wg.Done()
+
+	// one extra byte to detect too large messages
+	buf := make([]byte, s.maxMessageSize+1)
+	for {
+		n, _, err := s.packetConn.ReadFrom(buf)
+		if err != nil {
+			if s.closed() {
+				return
+			}
+			s.log.Warningf("syslog: read: %v", err)
+			if !s.wait(syslogRetryWait) {
+				return
+			}
+			continue
+		}
+		if n > s.maxMessageSize {
+			atomic.AddInt64(&s.received, 1)
+			atomic.AddInt64(&s.dropped, 1)
+			continue
+		}
+		s.enqueue(buf[:n])
+	}
+}
+
+func (s *SyslogReader) serveStream() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.closed() {
+				return
+			}
+			s.log.Warningf("syslog: accept: %v", err)
+			if !s.wait(syslogRetryWait) {
+				return
+			}
+			continue
+		}
+
+		s.connsMux.Lock()
+		if s.closed() {
+			s.connsMux.Unlock()
+			_ = conn.Close()
+			return
+		}
+		if len(s.conns) >= s.maxConns {
+			s.connsMux.Unlock()
+			s.log.Warningf("syslog: closing connection from '%s': max connections (%d) reached", conn.RemoteAddr(), s.maxConns)
+			_ = conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.connsMux.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *SyslogReader) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.connsMux.Lock()
+		delete(s.conns, conn)
+		s.connsMux.Unlock()
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := readSyslogFrame(r, s.maxMessageSize)
+		if err == errSyslogMessageTooLarge {
+			atomic.AddInt64(&s.received, 1)
+			atomic.AddInt64(&s.dropped, 1)
+			continue
+		}
+		if len(msg) > 0 {
+			s.enqueue(msg)
+		}
+		if err != nil {
+			if err != io.EOF && !s.closed() {
+				s.log.Debugf("syslog: read from '%s': %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+func (s *SyslogReader) enqueue(msg []byte) {
+	atomic.AddInt64(&s.received, 1)
+
+	body := stripSyslogHeader(bytes.TrimRight(msg, "\r\n\x00"))
+	if len(body) == 0 {
+		return
+	}
+	line := make([]byte, 0, len(body)+1)
+	line = append(append(line, bytes.ReplaceAll(body, []byte("\n"), []byte(" "))...), '\n')
+
+	s.last.Store(line[:len(line)-1])
+	s.firstOnce.Do(func() { close(s.first) })
+
+	select {
+	case s.queue <- line:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// wait waits for the duration, it returns false if the reader is closed while waiting.
+func (s *SyslogReader) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-s.done:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func (s *SyslogReader) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+var errSyslogMessageTooLarge = errors.New("syslog message is too large")
+
+// readSyslogFrame reads a message using the octet counting ("LEN SP MSG")
+// or the non-transparent (new line delimited) framing.
+func readSyslogFrame(r *bufio.Reader, maxSize int) ([]byte, error) {
+	if n, ok := peekOctetCount(r); ok {
+		if _, err := r.Discard(len(strconv.Itoa(n)) + 1); err != nil {
+			return nil, err
+		}
+		if n > maxSize {
+			if _, err := io.CopyN(ioutil.Discard, r, int64(n)); err != nil {
+				return nil, err
+			}
+			return nil, errSyslogMessageTooLarge
+		}
+		msg := make([]byte, n)
+		_, err := io.ReadFull(r, msg)
+		return msg, err
+	}
+
+	var msg []byte
+	var size int
+	for {
+		chunk, err := r.ReadSlice('\n')
+		// the max size doesn't include the trailing new line
+		if size += len(chunk); size <= maxSize+1 {
+			msg = append(msg, chunk...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if size > maxSize+1 {
+			if err != nil {
+				return nil, err
+			}
+			return nil, errSyslogMessageTooLarge
+		}
+		return msg, err
+	}
+}
+
+// peekOctetCount returns the message length if the frame starts with "LEN SP <".
+func peekOctetCount(r *bufio.Reader) (int, bool) {
+	const maxLenDigits = 10
+	bs, _ := r.Peek(maxLenDigits + 2)
+	i := 0
+	for i < len(bs) && i < maxLenDigits && bs[i] >= '0' && bs[i] <= '9' {
+		i++
+	}
+	if i == 0 || bs[0] == '0' || i+1 >= len(bs) || bs[i] != ' ' || bs[i+1] != '<' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(bs[:i]))
+	return n, err == nil
+}
+
+// stripSyslogHeader returns the message without the RFC5424 or RFC3164 header.
+// A message without the PRI part is returned as is.
+func stripSyslogHeader(msg []byte) []byte {
+	rest, ok := stripPRI(msg)
+	if !ok {
+		return msg
+	}
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		return stripRFC5424Header(rest[2:])
+	}
+	return stripRFC3164Header(rest)
+}
+
+func stripPRI(msg []byte) ([]byte, bool) {
+	if len(msg) < 3 || msg[0] != '<' {
+		return msg, false
+	}
+	i := bytes.IndexByte(msg[:minInt(len(msg), 5)], '>')
+	if i < 2 {
+		return msg, false
+	}
+	for _, c := range msg[1:i] {
+		if c < '0' || c > '9' {
+			return msg, false
+		}
+	}
+	return msg[i+1:], true
+}
+
+// stripRFC5424Header strips "TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]".
+func stripRFC5424Header(msg []byte) []byte {
+	for i := 0; i < 5; i++ {
+		idx := bytes.IndexByte(msg, ' ')
+		if idx < 0 {
+			return nil
+		}
+		msg = msg[idx+1:]
+	}
+
+	// STRUCTURED-DATA is either the NILVALUE or one or more "[...]" elements
+	if len(msg) > 0 && msg[0] == '-' {
+		msg = msg[1:]
+	} else {
+		for len(msg) > 0 && msg[0] == '[' {
+			end := sdElementEnd(msg)
+			if end < 0 {
+				return nil
+			}
+			msg = msg[end+1:]
+		}
+	}
+
+	msg = bytes.TrimPrefix(msg, []byte(" "))
+	return bytes.TrimPrefix(msg, []byte("\xEF\xBB\xBF"))
+}
+
+// sdElementEnd returns the index of the structured data element closing bracket, the values may contain escaped '\]'.
+func sdElementEnd(msg []byte) int {
+	var inValue, escaped bool
+	for i, c := range msg {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inValue = !inValue
+		case c == ']' && !inValue:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripRFC3164Header strips "TIMESTAMP SP [HOSTNAME SP] TAG[PID]: SP".
+// The TIMESTAMP is either "Mmm dd hh:mm:ss" or RFC3339.
+func stripRFC3164Header(msg []byte) []byte {
+	switch {
+	case len(msg) >= 16 && msg[3] == ' ' && msg[6] == ' ' && msg[9] == ':' && msg[12] == ':' && msg[15] == ' ':
+		msg = msg[16:]
+	case len(msg) > 20 && msg[4] == '-' && msg[7] == '-' && msg[10] == 'T':
+		idx := bytes.IndexByte(msg, ' ')
+		if idx < 0 {
+			return nil
+		}
+		msg = msg[idx+1:]
+	default:
+		return msg
+	}
+
+	// the TAG is either the first (no HOSTNAME) or the second token
+	rest := msg
+	for i := 0; i < 2; i++ {
+		idx := bytes.IndexByte(rest, ' ')
+		if idx <= 0 {
+			break
+		}
+		if rest[idx-1] == ':' {
+			return rest[idx+1:]
+		}
+		rest = rest[idx+1:]
+	}
+	return msg
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
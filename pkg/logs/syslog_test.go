@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripSyslogHeader(t *testing.T) {
+	tests := map[string]struct {
+		msg  string
+		want string
+	}{
+		"RFC5424": {
+			msg:  `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event`,
+			want: "An application event",
+		},
+		"RFC5424 escaped structured data": {
+			msg:  `<165>1 2003-10-11T22:14:15.003Z host app 1 - [a@1 x="[\]\"]"][b@1 y="z"] message`,
+			want: "message",
+		},
+		"RFC5424 nil structured data and BOM": {
+			msg:  "<34>1 2003-10-11T22:14:15.003Z host su - ID47 - \xEF\xBB\xBF'su root' failed",
+			want: "'su root' failed",
+		},
+		"RFC5424 no message": {
+			msg:  "<34>1 2003-10-11T22:14:15.003Z host su - ID47 -",
+			want: "",
+		},
+		"RFC3164": {
+			msg:  `<190>Aug  1 10:00:00 lb01 nginx: 127.0.0.1 - - [01/Aug/2022:10:00:00 +0000] "GET / HTTP/1.1" 200 612`,
+			want: `127.0.0.1 - - [01/Aug/2022:10:00:00 +0000] "GET / HTTP/1.1" 200 612`,
+		},
+		"RFC3164 no hostname": {
+			msg:  `<190>Aug 11 10:00:00 nginx[123]: 127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+			want: `127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+		},
+		"RFC3164 RFC3339 timestamp": {
+			msg:  `<190>2022-08-01T10:00:00.123456+00:00 lb01 haproxy[1]: 10.0.0.1:5000 [01/Aug/2022:10:00:00.000] fe be/srv 0/0/1/2/3 200 100`,
+			want: `10.0.0.1:5000 [01/Aug/2022:10:00:00.000] fe be/srv 0/0/1/2/3 200 100`,
+		},
+		"RFC3164 no tag": {
+			msg:  `<190>Aug  1 10:00:00 127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+			want: `127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+		},
+		"no PRI": {
+			msg:  `127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+			want: `127.0.0.1 - - "GET / HTTP/1.1" 200 612`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, string(stripSyslogHeader([]byte(test.msg))))
+		})
+	}
+}
+
+func TestReadSyslogFrame(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		maxSize  int
+		wantMsgs []string
+		wantErrs int
+	}{
+		"new line delimited": {
+			input:    "<13>Aug  1 10:00:00 host app: first\n<13>Aug  1 10:00:00 host app: second\n",
+			maxSize:  100,
+			wantMsgs: []string{"<13>Aug  1 10:00:00 host app: first\n", "<13>Aug  1 10:00:00 host app: second\n"},
+		},
+		"octet counting": {
+			input:    "10 <13>first\n10 <13>second",
+			maxSize:  100,
+			wantMsgs: []string{"<13>first\n", "<13>second"},
+		},
+		"too large": {
+			input:    "<13>too large message\n<13>ok\n15 <13>too large!!6 <13>ok",
+			maxSize:  10,
+			wantMsgs: []string{"<13>ok\n", "<13>ok"},
+			wantErrs: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := bufio.NewReaderSize(strings.NewReader(test.input), 16)
+			var msgs []string
+			var errs int
+			for {
+				msg, err := readSyslogFrame(r, test.maxSize)
+				if err == errSyslogMessageTooLarge {
+					errs++
+					continue
+				}
+				if len(msg) > 0 {
+					msgs = append(msgs, string(msg))
+				}
+				if err != nil {
+					break
+				}
+			}
+			assert.Equal(t, test.wantMsgs, msgs)
+			assert.Equal(t, test.wantErrs, errs)
+		})
+	}
+}
+
+func TestSyslogReader_Read(t *testing.T) {
+	tests := map[string]struct {
+		network string
+		address func(t *testing.T) string
+	}{
+		"udp":      {network: "udp", address: func(*testing.T) string { return "127.0.0.1:0" }},
+		"tcp":      {network: "tcp", address: func(*testing.T) string { return "127.0.0.1:0" }},
+		"unix":     {network: "unix", address: func(t *testing.T) string { return filepath.Join(t.TempDir(), "s.sock") }},
+		"unixgram": {network: "unixgram", address: func(t *testing.T) string { return filepath.Join(t.TempDir(), "s.sock") }},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSyslogReader(SyslogConfig{Network: test.network, Address: test.address(t)}, nil)
+			require.NoError(t, err)
+			defer func() { _ = s.Close() }()
+
+			conn, err := net.Dial(test.network, s.Addr())
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			var want []string
+			for i := 0; i < 3; i++ {
+				_, err = fmt.Fprintf(conn, "<190>Aug  1 10:00:00 lb01 nginx: line %d\n", i)
+				require.NoError(t, err)
+				want = append(want, fmt.Sprintf("line %d", i))
+			}
+
+			assert.Equal(t, want, readSyslogLines(t, s, len(want)))
+			assert.Equal(t, SyslogStats{Received: 3}, s.Stats())
+
+			line, err := s.LastLine()
+			assert.NoError(t, err)
+			assert.Equal(t, "line 2", string(line))
+		})
+	}
+}
+
+func TestSyslogReader_Read_DropsWhenQueueIsFull(t *testing.T) {
+	s, err := NewSyslogReader(SyslogConfig{Network: "tcp", Address: "127.0.0.1:0", QueueSize: 2}, nil)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	conn, err := net.Dial("tcp", s.Addr())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	for i := 0; i < 5; i++ {
+		_, err = fmt.Fprintf(conn, "<13>Aug  1 10:00:00 host app: line %d\n", i)
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool { return s.Stats().Received == 5 }, time.Second*5, time.Millisecond*10)
+
+	assert.Equal(t, SyslogStats{Received: 5, Dropped: 3}, s.Stats())
+	bs, err := ioutil.ReadAll(s)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 0\nline 1\n", string(bs))
+}
+
+func TestSyslogReader_MaxConnections(t *testing.T) {
+	s, err := NewSyslogReader(SyslogConfig{Network: "tcp", Address: "127.0.0.1:0", MaxConnections: 1}, nil)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	conn, err := net.Dial("tcp", s.Addr())
+	require.NoError(t, err)
+	_, err = fmt.Fprint(conn, "<13>Aug  1 10:00:00 host app: line 0\n")
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 0"}, readSyslogLines(t, s, 1))
+
+	rejected, err := net.Dial("tcp", s.Addr())
+	require.NoError(t, err)
+	defer func() { _ = rejected.Close() }()
+	_ = rejected.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = rejected.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err, "the connection over the limit is closed")
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		s.connsMux.Lock()
+		defer s.connsMux.Unlock()
+		return len(s.conns) == 0
+	}, time.Second*5, time.Millisecond*10)
+
+	conn, err = net.Dial("tcp", s.Addr())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = fmt.Fprint(conn, "<13>Aug  1 10:00:00 host app: line 1\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line 1"}, readSyslogLines(t, s, 1))
+}
+
+func readSyslogLines(t *testing.T, s *SyslogReader, num int) (lines []string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for len(lines) < num && time.Now().Before(deadline) {
+		bs, err := ioutil.ReadAll(s)
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(bs), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return lines
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	readChunkSize = 64 * 1024
+	// maxLineSize is the max length of an incomplete line kept between reads, a longer line is dropped.
+	maxLineSize = 1024 * 1024
+)
+
+// tail reads a single file. It tracks the file inode, the read offset and the offset of the last line
+// returned by the Reader (consumed), only the consumed offset is checkpointed.
+type tail struct {
+	name       string
+	file       *os.File
+	inode      uint64
+	offset     int64
+	consumed   int64
+	partial    []byte
+	chunk      []byte
+	detached   bool
+	lastActive time.Time
+	// discard is whether the rest of a line longer than maxLineSize is being dropped.
+	discard bool
+	// dropped is the number of the dropped lines longer than maxLineSize, not reported by the Reader yet.
+	dropped int64
+}
+
+func openTail(name string, offset int64) (*tail, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &tail{
+		name:       name,
+		file:       file,
+		inode:      fileInode(fi),
+		offset:     offset,
+		consumed:   offset,
+		lastActive: time.Now(),
+	}, nil
+}
+
+// readLines reads up to readChunkSize bytes and returns the complete lines.
+// A line longer than maxLineSize is dropped up to its new line, so only the complete lines are ever returned
+// and the consumed offset is never in the middle of a line.
+func (t *tail) readLines() []byte {
+	if t.chunk == nil {
+		t.chunk = make([]byte, readChunkSize)
+	}
+	n, _ := t.file.Read(t.chunk)
+	if n == 0 {
+		return nil
+	}
+	t.offset += int64(n)
+	t.lastActive = time.Now()
+
+	data := append(t.partial, t.chunk[:n]...)
+	if t.discard {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			return nil
+		}
+		data = data[idx+1:]
+		t.discard = false
+	}
+	idx := bytes.LastIndexByte(data, '\n')
+	if idx < 0 {
+		if len(data) < maxLineSize {
+			t.partial = data
+			return nil
+		}
+		t.partial = nil
+		t.discard = true
+		t.dropped++
+		return nil
+	}
+	t.partial = append([]byte(nil), data[idx+1:]...)
+	return data[:idx+1]
+}
+
+func (t *tail) sameFile(fi os.FileInfo) bool {
+	cur, err := t.file.Stat()
+	return err == nil && os.SameFile(cur, fi)
+}
+
+// rewind starts reading the file from the beginning, used when the file is truncated.
+func (t *tail) rewind() {
+	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	t.offset, t.consumed = 0, 0
+	t.partial = nil
+	t.discard = false
+}
+
+// detach marks the file as no longer matched by its name (renamed or not matched anymore).
+// It is still read until it is inactive for detachedTTL.
+func (t *tail) detach(now time.Time) {
+	t.detached = true
+	t.lastActive = now
+}
+
+// sameAs returns whether the file is neither rotated nor truncated since the checkpoint.
+func (t *tail) sameAs(cp checkpoint) bool {
+	fi, err := t.file.Stat()
+	return err == nil && cp.Inode == t.inode && cp.Offset <= fi.Size()
+}
+
+func (t *tail) checkpoint(now time.Time) checkpoint {
+	return checkpoint{Inode: t.inode, Offset: t.consumed, Time: now}
+}
+
+// linesEnd returns the offset of the end of the lines returned by readLines.
+func (t *tail) linesEnd() int64 {
+	return t.offset - int64(len(t.partial))
+}
+
+func (t *tail) close() error {
+	return t.file.Close()
+}
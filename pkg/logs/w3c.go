@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxW3CHeaderLines is the max number of lines ReadW3CFields reads from the file start.
+const maxW3CHeaderLines = 16
+
+var w3cFieldsDirective = []byte("#Fields:")
+
+type (
+	W3CConfig struct {
+		// Fields is the '#Fields' directive value, it is used until the directive is read from the log.
+		Fields  string            `yaml:"fields"`
+		Mapping map[string]string `yaml:"mapping"`
+	}
+
+	// W3CParser parses the W3C extended log file format (https://www.w3.org/TR/WD-logfile.html).
+	// The fields are defined by the '#Fields' directive, the other directives are skipped.
+	W3CParser struct {
+		r       *bufio.Reader
+		fields  []string
+		mapping map[string]string
+		values  []string
+	}
+)
+
+var (
+	errW3CNoFields       = errors.New("no '#Fields' directive")
+	errW3CUnterminated   = errors.New("unterminated quoted value")
+	errW3CNumberOfFields = errors.New("wrong number of fields")
+)
+
+func NewW3CParser(config W3CConfig, in io.Reader) (*W3CParser, error) {
+	parser := &W3CParser{
+		r:       bufio.NewReader(in),
+		fields:  parseW3CFields(config.Fields),
+		mapping: config.Mapping,
+	}
+	return parser, nil
+}
+
+// ReadLine reads the next log entry, the directives are applied and skipped.
+func (p *W3CParser) ReadLine(line LogLine) error {
+	for {
+		row, err := p.r.ReadSlice('\n')
+		if err != nil && len(row) == 0 {
+			return err
+		}
+		row = bytes.TrimRight(row, "\r\n")
+		if isW3CDirective(row) {
+			p.applyDirective(row)
+			continue
+		}
+		return p.Parse(row, line)
+	}
+}
+
+// Parse parses the log entry, a directive is applied and nothing is assigned.
+func (p *W3CParser) Parse(row []byte, line LogLine) error {
+	row = bytes.TrimRight(row, "\r\n")
+	if isW3CDirective(row) {
+		p.applyDirective(row)
+		return nil
+	}
+	if len(row) == 0 {
+		return nil
+	}
+	if len(p.fields) == 0 {
+		return &ParseError{msg: fmt.Sprintf("w3c parse: %v", errW3CNoFields), err: errW3CNoFields}
+	}
+
+	values, err := splitW3CValues(row, p.values[:0])
+	p.values = values
+	if err != nil {
+		return &ParseError{msg: fmt.Sprintf("w3c parse: %v", err), err: err}
+	}
+	if len(values) != len(p.fields) {
+		return &ParseError{
+			msg: fmt.Sprintf("w3c parse: %v (got %d, want %d)", errW3CNumberOfFields, len(values), len(p.fields)),
+			err: errW3CNumberOfFields,
+		}
+	}
+
+	for i, value := range values {
+		name := p.fields[i]
+		if v, ok := p.mapping[name]; ok {
+			name = v
+		}
+		if err := line.Assign(name, value); err != nil {
+			return &ParseError{msg: fmt.Sprintf("w3c parse: %v", err), err: err}
+		}
+	}
+	return nil
+}
+
+func (p *W3CParser) Info() string {
+	return fmt.Sprintf("w3c: %q", strings.Join(p.fields, " "))
+}
+
+func (p *W3CParser) applyDirective(row []byte) {
+	if bytes.HasPrefix(row, w3cFieldsDirective) {
+		p.fields = parseW3CFields(string(row))
+	}
+}
+
+// ReadW3CFields returns the '#Fields' directive value from the directives at the start of the file.
+// It returns an empty string if there is no such directive.
+func ReadW3CFields(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	var fields string
+	sc := bufio.NewScanner(f)
+	for i := 0; i < maxW3CHeaderLines && sc.Scan(); i++ {
+		row := bytes.TrimRight(sc.Bytes(), "\r")
+		if !isW3CDirective(row) {
+			break
+		}
+		if bytes.HasPrefix(row, w3cFieldsDirective) {
+			fields = strings.TrimSpace(string(row[len(w3cFieldsDirective):]))
+		}
+	}
+	return fields, sc.Err()
+}
+
+func isW3CDirective(row []byte) bool {
+	return len(row) > 0 && row[0] == '#'
+}
+
+func parseW3CFields(s string) []string {
+	s = strings.TrimPrefix(strings.TrimSpace(s), string(w3cFieldsDirective))
+	return strings.Fields(s)
+}
+
+// splitW3CValues splits the log entry by spaces and tabs. A quoted value can contain spaces,
+// a double quote inside is escaped by another double quote.
+func splitW3CValues(row []byte, values []string) ([]string, error) {
+	for {
+		row = bytes.TrimLeft(row, " \t")
+		if len(row) == 0 {
+			return values, nil
+		}
+
+		if row[0] != '"' {
+			end := bytes.IndexAny(row, " \t")
+			if end < 0 {
+				end = len(row)
+			}
+			values = append(values, string(row[:end]))
+			row = row[end:]
+			continue
+		}
+
+		var value []byte
+		i := 1
+		for ; i < len(row); i++ {
+			if row[i] != '"' {
+				value = append(value, row[i])
+				continue
+			}
+			if i+1 < len(row) && row[i+1] == '"' {
+				value = append(value, '"')
+				i++
+				continue
+			}
+			break
+		}
+		if i == len(row) {
+			return values, errW3CUnterminated
+		}
+		values = append(values, string(value))
+		row = row[i+1:]
+	}
+}
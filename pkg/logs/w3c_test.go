@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package logs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testW3CLog = "#Software: Microsoft Internet Information Services 10.0\r\n" +
+	"#Version: 1.0\r\n" +
+	"#Date: 2022-08-01 10:00:00\r\n" +
+	"#Fields: date time cs-method cs-uri-stem sc-status time-taken\r\n" +
+	"2022-08-01 10:00:00 GET /index.html 200 15\r\n" +
+	"#Fields: date time cs-method cs-uri-stem cs(User-Agent) sc-status\r\n" +
+	"2022-08-01 10:00:01 POST /api \"Mozilla/5.0 (\"\"X11\"\")\" 201\r\n"
+
+func TestW3CParser_ReadLine(t *testing.T) {
+	p, err := NewW3CParser(W3CConfig{Mapping: map[string]string{"sc-status": "status"}}, strings.NewReader(testW3CLog))
+	require.NoError(t, err)
+
+	line := newLogLine()
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{
+		"date":        "2022-08-01",
+		"time":        "10:00:00",
+		"cs-method":   "GET",
+		"cs-uri-stem": "/index.html",
+		"status":      "200",
+		"time-taken":  "15",
+	}, line.assigned)
+
+	line = newLogLine()
+	require.NoError(t, p.ReadLine(line))
+	assert.Equal(t, map[string]string{
+		"date":           "2022-08-01",
+		"time":           "10:00:01",
+		"cs-method":      "POST",
+		"cs-uri-stem":    "/api",
+		"cs(User-Agent)": `Mozilla/5.0 ("X11")`,
+		"status":         "201",
+	}, line.assigned)
+
+	assert.Equal(t, io.EOF, p.ReadLine(newLogLine()))
+}
+
+func TestW3CParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		fields       string
+		row          string
+		wantAssigned map[string]string
+		wantErr      bool
+	}{
+		"configured fields": {
+			fields:       "c-ip cs-method sc-status",
+			row:          "10.0.0.1 GET 200",
+			wantAssigned: map[string]string{"c-ip": "10.0.0.1", "cs-method": "GET", "sc-status": "200"},
+		},
+		"configured fields with the directive prefix": {
+			fields:       "#Fields: c-ip cs-method",
+			row:          "10.0.0.1\tGET",
+			wantAssigned: map[string]string{"c-ip": "10.0.0.1", "cs-method": "GET"},
+		},
+		"directive": {
+			row:          "#Fields: c-ip",
+			wantAssigned: map[string]string{},
+		},
+		"error on no fields": {
+			row:     "10.0.0.1 GET 200",
+			wantErr: true,
+		},
+		"error on wrong number of fields": {
+			fields:  "c-ip cs-method sc-status",
+			row:     "10.0.0.1 GET",
+			wantErr: true,
+		},
+		"error on unterminated quoted value": {
+			fields:  "c-ip cs(User-Agent)",
+			row:     `10.0.0.1 "Mozilla/5.0`,
+			wantErr: true,
+		},
+		"error on assigning": {
+			fields:  "c-ip ERR",
+			row:     "10.0.0.1 1",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			line := newLogLine()
+			p, err := NewW3CParser(W3CConfig{Fields: test.fields}, nil)
+			require.NoError(t, err)
+
+			err = p.Parse([]byte(test.row), line)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.True(t, IsParseError(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantAssigned, line.assigned)
+			}
+		})
+	}
+}
+
+func TestW3CParser_Info(t *testing.T) {
+	p, err := NewW3CParser(W3CConfig{Fields: "c-ip sc-status"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `w3c: "c-ip sc-status"`, p.Info())
+}
+
+func TestReadW3CFields(t *testing.T) {
+	tests := map[string]struct {
+		content    string
+		wantFields string
+	}{
+		"last fields directive of the header": {
+			content:    testW3CLog,
+			wantFields: "date time cs-method cs-uri-stem sc-status time-taken",
+		},
+		"no directives": {
+			content:    "2022-08-01 10:00:00 GET /index.html 200 15\n",
+			wantFields: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "u_ex220801.log")
+			require.NoError(t, os.WriteFile(filename, []byte(test.content), 0644))
+
+			fields, err := ReadW3CFields(filename)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantFields, fields)
+		})
+	}
+}
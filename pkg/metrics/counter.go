@@ -39,6 +39,11 @@ func (c Counter) WriteTo(rv map[string]int64, key string, mul, div int) {
 	rv[key] = int64(c.Value() * float64(mul) / float64(div))
 }
 
+// WriteFloatTo writes it's value into given map.
+func (c Counter) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	rv[key] = c.Value() * float64(mul) / float64(div)
+}
+
 // Value gets current counter.
 func (c Counter) Value() float64 {
 	return float64(c.valInt) + c.valFloat
@@ -75,6 +80,13 @@ func (c CounterVec) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+// WriteFloatTo writes it's value into given map.
+func (c CounterVec) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	for name, value := range c {
+		rv[key+"_"+name] = value.Value() * float64(mul) / float64(div)
+	}
+}
+
 // Get gets counter instance by name
 func (c CounterVec) Get(name string) *Counter {
 	item, _ := c.GetP(name)
@@ -25,8 +25,10 @@ type (
 )
 
 var (
-	_ stm.Value = Gauge(0)
-	_ stm.Value = GaugeVec{}
+	_ stm.Value      = Gauge(0)
+	_ stm.Value      = GaugeVec{}
+	_ stm.FloatValue = Gauge(0)
+	_ stm.FloatValue = GaugeVec{}
 )
 
 // WriteTo writes it's value into given map.
@@ -34,6 +36,11 @@ func (g Gauge) WriteTo(rv map[string]int64, key string, mul, div int) {
 	rv[key] = int64(float64(g) * float64(mul) / float64(div))
 }
 
+// WriteFloatTo writes it's value into given map.
+func (g Gauge) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	rv[key] = float64(g) * float64(mul) / float64(div)
+}
+
 // Value gets current counter.
 func (g Gauge) Value() float64 {
 	return float64(g)
@@ -85,6 +92,13 @@ func (g GaugeVec) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+// WriteFloatTo writes it's value into given map.
+func (g GaugeVec) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	for name, value := range g {
+		rv[key+"_"+name] = value.Value() * float64(mul) / float64(div)
+	}
+}
+
 // Get gets counter instance by name
 func (g GaugeVec) Get(name string) *Gauge {
 	item, _ := g.GetP(name)
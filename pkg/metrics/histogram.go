@@ -34,7 +34,8 @@ type (
 )
 
 var (
-	_ stm.Value = histogram{}
+	_ stm.Value      = histogram{}
+	_ stm.FloatValue = histogram{}
 )
 
 // DefBuckets are the default histogram buckets. The default buckets are
@@ -122,6 +123,18 @@ func (h histogram) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+// WriteFloatTo writes it's values into given map, the keys are the same as in WriteTo.
+func (h histogram) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	rv[key+"_sum"] = h.sum * float64(mul) / float64(div)
+	rv[key+"_count"] = float64(h.count)
+	var conn int64
+	for i, bucket := range h.buckets {
+		name := fmt.Sprintf("%s_bucket_%d", key, i+1)
+		conn += bucket
+		rv[name] = float64(conn)
+	}
+}
+
 // Observe observes a value
 func (h *histogram) Observe(v float64) {
 	hotIdx := h.searchBucketIndex(v)
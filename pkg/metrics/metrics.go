@@ -8,5 +8,6 @@ import "github.com/netdata/go.d.plugin/pkg/stm"
 // Histogram and Summary to add observations.
 type Observer interface {
 	stm.Value
+	stm.FloatValue
 	Observe(v float64)
 }
@@ -37,8 +37,10 @@ type (
 )
 
 var (
-	_ stm.Value = summary{}
-	_ stm.Value = SummaryVec{}
+	_ stm.Value      = summary{}
+	_ stm.Value      = SummaryVec{}
+	_ stm.FloatValue = summary{}
+	_ stm.FloatValue = SummaryVec{}
 )
 
 // NewSummary creates a new Summary.
@@ -72,6 +74,23 @@ func (s summary) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+// WriteFloatTo writes it's values into given map, the keys are the same as in WriteTo.
+func (s summary) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	if s.count > 0 {
+		rv[key+"_min"] = s.min * float64(mul) / float64(div)
+		rv[key+"_max"] = s.max * float64(mul) / float64(div)
+		rv[key+"_sum"] = s.sum * float64(mul) / float64(div)
+		rv[key+"_count"] = float64(s.count)
+		rv[key+"_avg"] = s.sum / float64(s.count) * float64(mul) / float64(div)
+	} else {
+		rv[key+"_count"] = 0
+		rv[key+"_sum"] = 0
+		delete(rv, key+"_min")
+		delete(rv, key+"_max")
+		delete(rv, key+"_avg")
+	}
+}
+
 // Reset resets all of its counters.
 // Call it before every scrape loop.
 func (s *summary) Reset() {
@@ -105,6 +124,13 @@ func (c SummaryVec) WriteTo(rv map[string]int64, key string, mul, div int) {
 	}
 }
 
+// WriteFloatTo writes it's value into given map.
+func (c SummaryVec) WriteFloatTo(rv map[string]float64, key string, mul, div int) {
+	for name, value := range c {
+		value.WriteFloatTo(rv, key+"_"+name, mul, div)
+	}
+}
+
 // Get gets counter instance by name.
 func (c SummaryVec) Get(name string) Summary {
 	item, ok := c[name]
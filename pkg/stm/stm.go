@@ -20,58 +20,100 @@ type (
 	Value interface {
 		WriteTo(rv map[string]int64, key string, mul, div int)
 	}
+
+	// FloatValue is a Value that writes its floating-point value without truncating it to int64.
+	FloatValue interface {
+		WriteFloatTo(rv map[string]float64, key string, mul, div int)
+	}
 )
 
 // ToMap converts struct to a map[string]int64 based on 'stm' tags
 func ToMap(s ...interface{}) map[string]int64 {
 	rv := map[string]int64{}
+	c := converter{ints: rv}
 	for _, v := range s {
 		value := reflect.Indirect(reflect.ValueOf(v))
-		toMap(value, rv, "", 1, 1)
+		c.toMap(value, "", 1, 1)
 	}
 	return rv
 }
 
-func toMap(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+// ToMapFloat converts struct to a map[string]float64 based on 'stm' tags, the float values are not truncated.
+func ToMapFloat(s ...interface{}) map[string]float64 {
+	rv := map[string]float64{}
+	c := converter{floats: rv}
+	for _, v := range s {
+		value := reflect.Indirect(reflect.ValueOf(v))
+		c.toMap(value, "", 1, 1)
+	}
+	return rv
+}
+
+// converter writes the values either to the ints or to the floats map.
+type converter struct {
+	ints   map[string]int64
+	floats map[string]float64
+}
+
+func (c converter) toMap(value reflect.Value, key string, mul, div int) {
 	if !value.IsValid() {
 		logger.Panicf("value is not valid key=%s", key)
 	}
-	if value.CanInterface() {
-		val, ok := value.Interface().(Value)
-		if ok {
-			val.WriteTo(rv, key, mul, div)
-			return
-		}
+	if value.CanInterface() && c.writeValue(value.Interface(), key, mul, div) {
+		return
 	}
 	switch value.Kind() {
 	case reflect.Ptr:
-		convertPtr(value, rv, key, mul, div)
+		c.convertPtr(value, key, mul, div)
 	case reflect.Struct:
-		convertStruct(value, rv, key)
+		c.convertStruct(value, key)
 	case reflect.Array, reflect.Slice:
-		convertArraySlice(value, rv, key, mul, div)
+		c.convertArraySlice(value, key, mul, div)
 	case reflect.Map:
-		convertMap(value, rv, key, mul, div)
+		c.convertMap(value, key, mul, div)
 	case reflect.Bool:
-		convertBool(value, rv, key)
+		c.convertBool(value, key)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		convertInteger(value, rv, key, mul, div)
+		c.convertInteger(value, key, mul, div)
 	case reflect.Float32, reflect.Float64:
-		convertFloat(value, rv, key, mul, div)
+		c.convertFloat(value, key, mul, div)
 	case reflect.Interface:
-		convertInterface(value, rv, key, mul, div)
+		c.convertInterface(value, key, mul, div)
 	default:
 		logger.Panic("unsupported data type: ", value.Kind())
 	}
 }
 
-func convertPtr(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+func (c converter) writeValue(v interface{}, key string, mul, div int) bool {
+	if c.floats != nil {
+		if val, ok := v.(FloatValue); ok {
+			val.WriteFloatTo(c.floats, key, mul, div)
+			return true
+		}
+	}
+	val, ok := v.(Value)
+	if !ok {
+		return false
+	}
+	if c.ints != nil {
+		val.WriteTo(c.ints, key, mul, div)
+		return true
+	}
+	rv := make(map[string]int64)
+	val.WriteTo(rv, key, mul, div)
+	for k, n := range rv {
+		c.floats[k] = float64(n)
+	}
+	return true
+}
+
+func (c converter) convertPtr(value reflect.Value, key string, mul, div int) {
 	if !value.IsNil() {
-		toMap(value.Elem(), rv, key, mul, div)
+		c.toMap(value.Elem(), key, mul, div)
 	}
 }
 
-func convertStruct(value reflect.Value, rv map[string]int64, key string) {
+func (c converter) convertStruct(value reflect.Value, key string) {
 	t := value.Type()
 	k := value.FieldByName(structKey)
 	if k.Kind() == reflect.String {
@@ -85,55 +127,73 @@ func convertStruct(value reflect.Value, rv map[string]int64, key string) {
 		}
 		value := value.Field(i)
 		prefix, mul, div := parseTag(tag)
-		toMap(value, rv, joinPrefix(key, prefix), mul, div)
+		c.toMap(value, joinPrefix(key, prefix), mul, div)
 	}
 }
 
-func convertMap(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+func (c converter) convertMap(value reflect.Value, key string, mul, div int) {
 	if value.IsNil() {
 		logger.Panicf("value is nil key=%s", key)
 	}
 	for _, k := range value.MapKeys() {
-		toMap(value.MapIndex(k), rv, joinPrefix(key, k.String()), mul, div)
+		c.toMap(value.MapIndex(k), joinPrefix(key, k.String()), mul, div)
 	}
 }
 
-func convertArraySlice(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+func (c converter) convertArraySlice(value reflect.Value, key string, mul, div int) {
 	for i := 0; i < value.Len(); i++ {
-		toMap(value.Index(i), rv, key, mul, div)
+		c.toMap(value.Index(i), key, mul, div)
 	}
 }
 
-func convertBool(value reflect.Value, rv map[string]int64, key string) {
-	if _, ok := rv[key]; ok {
-		logger.Panic("duplicate key: ", key)
-	}
+func (c converter) convertBool(value reflect.Value, key string) {
+	c.checkDuplicate(key)
+	var v int64
 	if value.Bool() {
-		rv[key] = 1
+		v = 1
+	}
+	if c.ints != nil {
+		c.ints[key] = v
 	} else {
-		rv[key] = 0
+		c.floats[key] = float64(v)
 	}
 }
 
-func convertInteger(value reflect.Value, rv map[string]int64, key string, mul, div int) {
-	if _, ok := rv[key]; ok {
-		logger.Panic("duplicate key: ", key)
-	}
+func (c converter) convertInteger(value reflect.Value, key string, mul, div int) {
+	c.checkDuplicate(key)
 	intVal := value.Int()
-	rv[key] = intVal * int64(mul) / int64(div)
+	if c.ints != nil {
+		c.ints[key] = intVal * int64(mul) / int64(div)
+	} else {
+		c.floats[key] = float64(intVal) * float64(mul) / float64(div)
+	}
 }
 
-func convertFloat(value reflect.Value, rv map[string]int64, key string, mul, div int) {
-	if _, ok := rv[key]; ok {
-		logger.Panic("duplicate key: ", key)
-	}
+func (c converter) convertFloat(value reflect.Value, key string, mul, div int) {
+	c.checkDuplicate(key)
 	floatVal := value.Float()
-	rv[key] = int64(floatVal * float64(mul) / float64(div))
+	if c.ints != nil {
+		c.ints[key] = int64(floatVal * float64(mul) / float64(div))
+	} else {
+		c.floats[key] = floatVal * float64(mul) / float64(div)
+	}
 }
 
-func convertInterface(value reflect.Value, rv map[string]int64, key string, mul, div int) {
+func (c converter) convertInterface(value reflect.Value, key string, mul, div int) {
 	fv := reflect.ValueOf(value.Interface())
-	toMap(fv, rv, key, mul, div)
+	c.toMap(fv, key, mul, div)
+}
+
+func (c converter) checkDuplicate(key string) {
+	var ok bool
+	if c.ints != nil {
+		_, ok = c.ints[key]
+	} else {
+		_, ok = c.floats[key]
+	}
+	if ok {
+		logger.Panic("duplicate key: ", key)
+	}
 }
 
 func joinPrefix(prefix, key string) string {
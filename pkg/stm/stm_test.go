@@ -96,6 +96,34 @@ func TestToMap_float(t *testing.T) {
 	assert.EqualValuesf(t, expected, stm.ToMap(&s), "ptr test")
 }
 
+func TestToMapFloat(t *testing.T) {
+	s := struct {
+		I   int                   `stm:"int,3,2"`
+		F   float64               `stm:"float"`
+		B   bool                  `stm:"bool"`
+		M   map[string]float64    `stm:"map"`
+		G   metrics.Gauge         `stm:"gauge,1,4"`
+		S   metrics.Summary       `stm:"summary"`
+		UC  metrics.UniqueCounter `stm:"unique"`
+		Nil *int                  `stm:"nil"`
+	}{
+		I: 1, F: 0.125, B: true, M: map[string]float64{"a": 1.5}, G: 1,
+		S: metrics.NewSummary(), UC: metrics.NewUniqueCounter(false),
+	}
+	s.S.Observe(0.5)
+	s.S.Observe(1.5)
+	s.UC.Insert("a")
+
+	expected := map[string]float64{
+		"int": 1.5, "float": 0.125, "bool": 1, "map_a": 1.5, "gauge": 0.25,
+		"summary_count": 2, "summary_sum": 2, "summary_min": 0.5, "summary_max": 1.5, "summary_avg": 1,
+		"unique": 1,
+	}
+
+	assert.Equal(t, expected, stm.ToMapFloat(s), "value test")
+	assert.Equal(t, expected, stm.ToMapFloat(&s), "ptr test")
+}
+
 func TestToMap_struct(t *testing.T) {
 	type pair struct {
 		Left  int `stm:"left"`